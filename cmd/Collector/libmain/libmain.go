@@ -10,14 +10,18 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/aristanetworks/cloudvision-go/device"
 	_ "github.com/aristanetworks/cloudvision-go/device/devices" // import all registered devices
 	"github.com/aristanetworks/cloudvision-go/device/gen"
+	"github.com/aristanetworks/cloudvision-go/device/plugin"
 	"github.com/aristanetworks/cloudvision-go/log"
 	"github.com/aristanetworks/cloudvision-go/version"
 	"github.com/aristanetworks/fsnotify"
@@ -46,6 +50,8 @@ var (
 		"Device type (available devices: "+deviceList()+")")
 	deviceOptions    = aflag.Map{}
 	deviceConfigFile = flag.String("configFile", "", "Path to the config file for devices")
+	plugins          = flag.String("plugins", "", "Comma-separated list of device plugin "+
+		"executables, or directories of them, to load at startup")
 
 	// MockCollector config
 	mock        = flag.Bool("mock", false, "Run Collector in mock mode")
@@ -80,6 +86,31 @@ func Main() {
 
 	flag.Parse()
 
+	// Kill plugins and wait for them to be cleaned up after however
+	// we exit: by returning, on a fatal error, or on a signal.
+	ctx, cancel := context.WithCancel(context.Background())
+	shutdown := func() {
+		cancel()
+		plugin.Wait()
+	}
+	defer shutdown()
+	logrus.RegisterExitHandler(shutdown)
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-signals
+		logrus.Infof("Exiting on %v", sig)
+		shutdown()
+		os.Exit(1)
+	}()
+
+	// Load plugins before anything that looks at registered devices.
+	if *plugins != "" {
+		if err := loadPlugins(ctx, *plugins); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
 	// Print version.
 	if *v {
 		vs := []string{version.CollectorVersion, runtime.Version()}
@@ -112,14 +143,14 @@ func Main() {
 	initLogging()
 
 	if *mock {
-		runMock(context.Background())
+		runMock(ctx)
 		return
 	}
 	if *dump {
-		runDump(context.Background())
+		runDump(ctx)
 		return
 	}
-	runMain(context.Background())
+	runMain(ctx)
 }
 
 func initLogging() {
@@ -204,6 +235,18 @@ func createDeviceConfigs() ([]*device.Config, error) {
 	return configs, nil
 }
 
+// loadPlugins loads each of the comma-separated plugin paths, then
+// updates the device flag's usage string with any new devices.
+func loadPlugins(ctx context.Context, paths string) error {
+	for _, path := range strings.Split(paths, ",") {
+		if err := plugin.Load(ctx, path); err != nil {
+			return err
+		}
+	}
+	flag.Lookup("device").Usage = "Device type (available devices: " + deviceList() + ")"
+	return nil
+}
+
 // Return a formatted list of available devices.
 func deviceList() string {
 	dl := device.Registered()
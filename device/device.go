@@ -102,6 +102,15 @@ func OptionHelp(deviceName string) (map[string]string, error) {
 	return helpDesc(registrationInfo.options), nil
 }
 
+// Options returns the options supported by the specified device.
+func Options(deviceName string) (map[string]Option, error) {
+	registrationInfo, ok := deviceMap[deviceName]
+	if !ok {
+		return nil, fmt.Errorf("Device '%v' not found", deviceName)
+	}
+	return registrationInfo.options, nil
+}
+
 // Info contains the running state of an instantiated device.
 type Info struct {
 	ID     string
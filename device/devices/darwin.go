@@ -6,8 +6,6 @@ package devices
 
 import (
 	"fmt"
-	"os/exec"
-	"strings"
 
 	"github.com/aristanetworks/cloudvision-go/device"
 	"github.com/aristanetworks/cloudvision-go/provider"
@@ -35,29 +33,6 @@ func (d *darwin) Alive() (bool, error) {
 	return true, nil
 }
 
-// Use the device's serial number as its ID.
-func (d *darwin) deviceSerial() (string, error) {
-	profiler := exec.Command("system_profiler", "SPHardwareDataType")
-	awk := exec.Command("awk", "/Serial/ {print $4}")
-	pipe, err := profiler.StdoutPipe()
-	if err != nil {
-		return "", err
-	}
-	defer pipe.Close()
-
-	awk.Stdin = pipe
-
-	err = profiler.Start()
-	if err != nil {
-		return "", err
-	}
-	out, err := awk.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSuffix(string(out), "\n"), nil
-}
-
 func (d *darwin) DeviceID() (string, error) {
 	return d.deviceID, nil
 }
@@ -73,13 +48,14 @@ func NewDarwinDevice(options map[string]string) (device.Device, error) {
 		return nil, err
 	}
 
-	device := darwin{}
-	did, err := device.deviceSerial()
+	// Use the device's serial number as its ID.
+	did, err := pdarwin.DeviceID(pdarwin.RunCommand)
 	if err != nil {
 		return nil, fmt.Errorf("Failure getting device ID: %v", err)
 	}
-	device.deviceID = did
-	device.provider = pdarwin.NewDarwinProvider(pollInterval)
 
-	return &device, nil
+	return &darwin{
+		deviceID: did,
+		provider: pdarwin.NewDarwinProvider(pollInterval, pdarwin.RunCommand),
+	}, nil
 }
@@ -0,0 +1,151 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package devices
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aristanetworks/cloudvision-go/device"
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pexec "github.com/aristanetworks/cloudvision-go/provider/exec"
+)
+
+// Register this device with its options.
+func init() {
+	options := map[string]device.Option{
+		"command": {
+			Description: "Command producing device data on stdout",
+			Required:    true,
+		},
+		"format": {
+			Description: "Format of the command's output: one JSON object per " +
+				"line with path, value, and optional op (jsonl) or one " +
+				"JSON-encoded gNMI notification per line (gnmi)",
+			Default: pexec.FormatJSONLines,
+			Pattern: pexec.FormatJSONLines + "|" + pexec.FormatGNMI,
+		},
+		"mode": {
+			Description: "Run the command every poll interval (poll) or " +
+				"once, streaming its output (stream)",
+			Default: "poll",
+			Pattern: "poll|stream",
+		},
+		"pollInterval": {
+			Description: "Polling interval, with unit suffix (s/m/h). In " +
+				"stream mode, the delay before restarting an exited command.",
+			Default: "20s",
+		},
+		"timeout": {
+			Description: "Time limit on each run of the command in poll mode " +
+				"and of deviceIDCommand and aliveCommand, with unit suffix (s/m/h)",
+			Default: "10s",
+		},
+		"deviceIDCommand": {
+			Description: "Command whose output is the device ID",
+			Required:    true,
+		},
+		"aliveCommand": {
+			Description: "Command whose exit status indicates whether the " +
+				"device is alive. If unset, the device is always alive.",
+		},
+		"openConfig": {
+			Description: "Whether the command's output is OpenConfig data",
+			Default:     "true",
+			Pattern:     "true|false",
+		},
+	}
+	device.Register("exec", NewExecDevice, options)
+}
+
+type execDevice struct {
+	deviceID     string
+	aliveCommand string
+	timeout      time.Duration
+	provider     provider.GNMIProvider
+}
+
+func (d *execDevice) Alive() (bool, error) {
+	if d.aliveCommand == "" {
+		return true, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	err := pexec.Command(ctx, d.aliveCommand).Run()
+	if _, ok := err.(*exec.ExitError); ok {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (d *execDevice) DeviceID() (string, error) {
+	return d.deviceID, nil
+}
+
+func (d *execDevice) Providers() ([]provider.Provider, error) {
+	return []provider.Provider{d.provider}, nil
+}
+
+// NewExecDevice instantiates a device whose data comes from an
+// external command.
+func NewExecDevice(options map[string]string) (device.Device, error) {
+	command, err := device.GetStringOption("command", options)
+	if err != nil {
+		return nil, err
+	}
+	format, err := device.GetStringOption("format", options)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := device.GetStringOption("mode", options)
+	if err != nil {
+		return nil, err
+	}
+	pollInterval, err := device.GetDurationOption("pollInterval", options)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := device.GetDurationOption("timeout", options)
+	if err != nil {
+		return nil, err
+	}
+	deviceIDCommand, err := device.GetStringOption("deviceIDCommand", options)
+	if err != nil {
+		return nil, err
+	}
+	aliveCommand, err := device.GetStringOption("aliveCommand", options)
+	if err != nil {
+		return nil, err
+	}
+	openConfig, err := device.GetBoolOption("openConfig", options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := pexec.Command(ctx, deviceIDCommand).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Failure getting device ID: %v", err)
+	}
+	did := strings.TrimSpace(string(out))
+	if did == "" {
+		return nil, fmt.Errorf("Command '%s' returned an empty device ID",
+			deviceIDCommand)
+	}
+
+	return &execDevice{
+		deviceID:     did,
+		aliveCommand: aliveCommand,
+		timeout:      timeout,
+		provider: pexec.NewExecProvider(command, format, pollInterval, timeout,
+			mode == "stream", openConfig),
+	}, nil
+}
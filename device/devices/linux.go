@@ -0,0 +1,71 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package devices
+
+import (
+	"fmt"
+
+	"github.com/aristanetworks/cloudvision-go/device"
+	"github.com/aristanetworks/cloudvision-go/provider"
+	plinux "github.com/aristanetworks/cloudvision-go/provider/linux"
+)
+
+// Register this device with its options.
+func init() {
+	options := map[string]device.Option{
+		"pollInterval": {
+			Description: "Polling interval, with unit suffix (s/m/h)",
+			Default:     "20s",
+		},
+		"root": {
+			Description: "Directory under which the host's /proc, /sys, " +
+				"and /etc are found",
+			Default: "/",
+		},
+	}
+	device.Register("linux", NewLinuxDevice, options)
+}
+
+type linux struct {
+	deviceID string
+	provider provider.GNMIProvider
+}
+
+func (d *linux) Alive() (bool, error) {
+	// Runs on the device itself, so if the method is called, it's alive.
+	return true, nil
+}
+
+func (d *linux) DeviceID() (string, error) {
+	return d.deviceID, nil
+}
+
+func (d *linux) Providers() ([]provider.Provider, error) {
+	return []provider.Provider{d.provider}, nil
+}
+
+// NewLinuxDevice instantiates a Linux host device.
+func NewLinuxDevice(options map[string]string) (device.Device, error) {
+	pollInterval, err := device.GetDurationOption("pollInterval", options)
+	if err != nil {
+		return nil, err
+	}
+	root, err := device.GetStringOption("root", options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the host's serial number, or failing that its machine ID,
+	// as its ID.
+	did, err := plinux.DeviceID(root)
+	if err != nil {
+		return nil, fmt.Errorf("Failure getting device ID: %v", err)
+	}
+
+	return &linux{
+		deviceID: did,
+		provider: plinux.NewLinuxProvider(pollInterval, root),
+	}, nil
+}
@@ -5,6 +5,7 @@
 package devices
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
@@ -14,13 +15,15 @@ import (
 	"github.com/aristanetworks/cloudvision-go/device"
 	"github.com/aristanetworks/cloudvision-go/provider"
 	psnmp "github.com/aristanetworks/cloudvision-go/provider/snmp"
-	"github.com/soniah/gosnmp"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/snmpoc"
+	"github.com/gosnmp/gosnmp"
 )
 
 var options = map[string]device.Option{
 	"a": device.Option{
-		Description: "SNMPv3 authentication protocol",
-		Pattern:     `sha|SHA|md5|MD5`,
+		Description: "SNMPv3 authentication protocol " +
+			"(md5|sha|sha224|sha256|sha384|sha512)",
+		Pattern: `(?i)md5|sha|sha224|sha256|sha384|sha512`,
 	},
 	"A": device.Option{
 		Description: "SNMPv3 authentication key",
@@ -36,18 +39,78 @@ var options = map[string]device.Option{
 	"c": device.Option{
 		Description: "SNMP community string",
 	},
+	"contextEngineID": device.Option{
+		Description: "SNMPv3 context engine ID, in hex (default the authoritative engine ID)",
+		Pattern:     `(0x)?([0-9a-fA-F]{2})+`,
+	},
+	"contextName": device.Option{
+		Description: "SNMPv3 context name",
+	},
+	"counterRates": device.Option{
+		Description: "Send per-second rates of interface counters, in the counter-rates origin",
+		Default:     "false",
+	},
+	"extendCounters": device.Option{
+		Description: "Extend 32-bit interface counters to 64 bits across counter wraps",
+		Default:     "false",
+	},
+	"engineID": device.Option{
+		Description: "SNMPv3 authoritative engine ID of the device, in hex " +
+			"(discovered if empty)",
+		Pattern: `(0x)?([0-9a-fA-F]{2})+`,
+	},
+	"exponentialTimeout": device.Option{
+		Description: "Double the SNMP timeout with each retry",
+		Default:     "true",
+	},
 	"l": device.Option{
 		Description: "SNMPv3 security level (noAuthNoPriv|authNoPriv|authPriv)",
 		Default:     "authPriv",
 		Pattern:     `noAuthNoPriv|authNoPriv|authPriv`,
 	},
+	"mappings": device.Option{
+		Description: "YAML file of additional SNMP-to-OpenConfig mappings " +
+			"(models defined in the file are polled at pollInterval)",
+	},
+	"maxRequestRate": device.Option{
+		Description: "Maximum SNMP requests per second to send the device (0 for no limit)",
+		Default:     "0",
+	},
+	"maxRepetitions": device.Option{
+		Description: "Max-repetitions value of SNMP GETBULK requests",
+		Default:     "12",
+		Pattern:     `[1-9][0-9]*`,
+	},
+	"mibCache": device.Option{
+		Description: "Directory in which to cache parsed MIBs so that " +
+			"they needn't be parsed at every start (no cache if empty)",
+	},
 	"mibs": device.Option{
-		Description: "Comma-separated list of mib files/directories",
-		Required:    true,
+		Description: "Comma-separated list of mib files/directories, " +
+			"whose modules replace any bundled modules of the same name",
 	},
 	"pollInterval": device.Option{
-		Description: "Polling interval, with unit suffix (s/m/h)",
-		Default:     "20s",
+		Description: "Polling interval, with unit suffix (s/m/h), of models " +
+			"without a pollInterval.<model> option",
+		Default: "20s",
+	},
+	"retries": device.Option{
+		Description: "Number of times to retry an SNMP request",
+		Default:     "3",
+		Pattern:     `[0-9]+`,
+	},
+	"sessions": device.Option{
+		Description: "Number of SNMP sessions to open for concurrent requests",
+		Default:     "1",
+		Pattern:     `[1-9][0-9]*`,
+	},
+	"timeout": device.Option{
+		Description: "Time to wait for an SNMP response, with unit suffix (s/m/h)",
+		Default:     "2s",
+	},
+	"trapAddress": device.Option{
+		Description: "Local address on which to receive the device's SNMP " +
+			"traps and informs, such as :162 (disabled if empty)",
 	},
 	"u": device.Option{
 		Description: "SNMPv3 security name",
@@ -57,9 +120,16 @@ var options = map[string]device.Option{
 		Pattern:     `2c|3`,
 		Default:     "2c",
 	},
+	"walk": device.Option{
+		Description: "How to walk tables: with GETBULK, with GETNEXT, or " +
+			"with GETBULK falling back to GETNEXT if the device fails it (bulk|getnext|auto)",
+		Default: "bulk",
+		Pattern: `bulk|getnext|auto`,
+	},
 	"x": device.Option{
-		Description: "SNMPv3 privacy protocol",
-		Pattern:     `des|DES|aes|AES`,
+		Description: "SNMPv3 privacy protocol (des|aes|aes192|aes256|aes192c|aes256c), " +
+			"where aes192c and aes256c use the Cisco (Reeder) key extension",
+		Pattern: `(?i)des|aes|aes192|aes256|aes192c|aes256c`,
 	},
 	"X": device.Option{
 		Description: "SNMPv3 privacy key",
@@ -67,26 +137,54 @@ var options = map[string]device.Option{
 }
 
 func init() {
+	for _, model := range snmpoc.Models() {
+		options[pollIntervalOption(model)] = device.Option{
+			Description: fmt.Sprintf("Polling interval of the %s model, with unit "+
+				"suffix (s/m/h) (pollInterval if empty)", model),
+		}
+	}
 	device.Register("snmp", newSnmp, options)
 }
 
+// pollIntervalOption returns the name of the option setting the
+// poll interval of the named model.
+func pollIntervalOption(model string) string {
+	return "pollInterval." + model
+}
+
 type snmp struct {
-	address      string
-	authKey      string
-	authProto    string
-	community    string
-	level        string
-	mibs         []string
-	pollInterval time.Duration
-	port         uint16
-	privacyKey   string
-	privacyProto string
-	securityName string
-	systemID     string
-	version      string
-	v3Params     *psnmp.V3Params
-	v            gosnmp.SnmpVersion
-	snmpProvider provider.GNMIProvider
+	address        string
+	authKey        string
+	authProto      string
+	community      string
+	contextName    string
+	contextEngine  string
+	engineID       string
+	level          string
+	mibCacheDir    string
+	mibs           []string
+	pollInterval   time.Duration
+	pollIntervals  map[string]time.Duration
+	port           uint16
+	privacyKey     string
+	privacyProto   string
+	securityName   string
+	systemID       string
+	trapAddress    string
+	mappings       string
+	extendCounters bool
+	counterRates   bool
+	sessions       int
+	maxRate        float64
+	retries        int
+	timeout        time.Duration
+	expTimeout     bool
+	maxReps        uint32
+	walkStrategy   psnmp.WalkStrategy
+	version        string
+	v3Params       *psnmp.V3Params
+	v              gosnmp.SnmpVersion
+	snmpProvider   provider.GNMIProvider
 }
 
 // XXX NOTE: For now, we return an error rather than just returning false. We
@@ -112,7 +210,32 @@ func (s *snmp) DeviceID() (string, error) {
 }
 
 func (s *snmp) Providers() ([]provider.Provider, error) {
-	return []provider.Provider{s.snmpProvider}, nil
+	providers := []provider.Provider{s.snmpProvider}
+	if s.counterRates {
+		providers = append(providers, s.snmpProvider.(*psnmp.Snmp).RateProvider())
+	}
+	return providers, nil
+}
+
+// pollIntervals returns the poll intervals of the models whose
+// pollInterval.<model> options are set.
+func pollIntervals(options map[string]string) (map[string]time.Duration, error) {
+	intervals := make(map[string]time.Duration)
+	for _, model := range snmpoc.Models() {
+		name := pollIntervalOption(model)
+		if o, ok := options[name]; !ok || o == "" {
+			continue
+		}
+		d, err := device.GetDurationOption(name, options)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("Option '%s' must be positive", name)
+		}
+		intervals[model] = d
+	}
+	return intervals, nil
 }
 
 func (s *snmp) validateOptions() error {
@@ -151,6 +274,20 @@ func (s *snmp) validateOptions() error {
 	return nil
 }
 
+// hexOption returns the option specified by optionName, a hex string
+// with an optional 0x prefix, as the raw bytes it represents.
+func hexOption(optionName string, options map[string]string) (string, error) {
+	o, err := device.GetStringOption(optionName, options)
+	if err != nil {
+		return "", err
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(o, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("Invalid hex value for option '%s': %v", optionName, err)
+	}
+	return string(b), nil
+}
+
 func (s *snmp) formatOptions() (gosnmp.SnmpVersion, *psnmp.V3Params) {
 	if s.version == "2c" {
 		return gosnmp.Version2c, nil
@@ -171,19 +308,17 @@ func (s *snmp) formatOptions() (gosnmp.SnmpVersion, *psnmp.V3Params) {
 		v3Params.Level = gosnmp.AuthPriv
 	}
 
-	if strings.ToLower(s.authProto) == "sha" {
-		v3Params.UsmParams.AuthenticationProtocol = gosnmp.SHA
-	} else if strings.ToLower(s.authProto) == "md5" {
-		v3Params.UsmParams.AuthenticationProtocol = gosnmp.MD5
+	if p, ok := psnmp.AuthProtocols[strings.ToLower(s.authProto)]; ok {
+		v3Params.UsmParams.AuthenticationProtocol = p
 	}
-
-	if strings.ToLower(s.privacyProto) == "aes" {
-		v3Params.UsmParams.PrivacyProtocol = gosnmp.AES
-	} else if strings.ToLower(s.privacyProto) == "des" {
-		v3Params.UsmParams.PrivacyProtocol = gosnmp.DES
+	if p, ok := psnmp.PrivacyProtocols[strings.ToLower(s.privacyProto)]; ok {
+		v3Params.UsmParams.PrivacyProtocol = p
 	}
 	v3Params.UsmParams.AuthenticationPassphrase = s.authKey
 	v3Params.UsmParams.PrivacyPassphrase = s.privacyKey
+	v3Params.UsmParams.AuthoritativeEngineID = s.engineID
+	v3Params.ContextName = s.contextName
+	v3Params.ContextEngineID = s.contextEngine
 
 	return gosnmp.Version3, v3Params
 }
@@ -225,16 +360,41 @@ func newSnmp(options map[string]string) (device.Device, error) {
 		return nil, s.deviceConfigErr(err)
 	}
 
+	s.contextName, err = device.GetStringOption("contextName", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	s.contextEngine, err = hexOption("contextEngineID", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	s.engineID, err = hexOption("engineID", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
 	s.mibs, err = device.GetStringListOption("mibs", options)
 	if err != nil {
 		return nil, s.deviceConfigErr(err)
 	}
 
+	s.mibCacheDir, err = device.GetStringOption("mibCache", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
 	s.pollInterval, err = device.GetDurationOption("pollInterval", options)
 	if err != nil {
 		return nil, s.deviceConfigErr(err)
 	}
 
+	s.pollIntervals, err = pollIntervals(options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
 	port, err := device.GetPortOption("port", options)
 	if err != nil {
 		return nil, s.deviceConfigErr(err)
@@ -260,6 +420,86 @@ func newSnmp(options map[string]string) (device.Device, error) {
 		return nil, s.deviceConfigErr(err)
 	}
 
+	s.trapAddress, err = device.GetStringOption("trapAddress", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	s.mappings, err = device.GetStringOption("mappings", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	s.extendCounters, err = device.GetBoolOption("extendCounters", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	s.counterRates, err = device.GetBoolOption("counterRates", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	sessions, err := device.GetStringOption("sessions", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+	s.sessions, err = strconv.Atoi(sessions)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	maxRate, err := device.GetStringOption("maxRequestRate", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+	s.maxRate, err = strconv.ParseFloat(maxRate, 64)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	retries, err := device.GetStringOption("retries", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+	s.retries, err = strconv.Atoi(retries)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	s.timeout, err = device.GetDurationOption("timeout", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	s.expTimeout, err = device.GetBoolOption("exponentialTimeout", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+
+	maxReps, err := device.GetStringOption("maxRepetitions", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+	reps, err := strconv.ParseUint(maxReps, 10, 32)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+	s.maxReps = uint32(reps)
+
+	walk, err := device.GetStringOption("walk", options)
+	if err != nil {
+		return nil, s.deviceConfigErr(err)
+	}
+	switch walk {
+	case "getnext":
+		s.walkStrategy = psnmp.WalkGetNext
+	case "auto":
+		s.walkStrategy = psnmp.WalkAuto
+	default:
+		s.walkStrategy = psnmp.WalkBulk
+	}
+
 	s.version, err = device.GetStringOption("v", options)
 	if err != nil {
 		return nil, s.deviceConfigErr(err)
@@ -274,5 +514,29 @@ func newSnmp(options map[string]string) (device.Device, error) {
 	s.snmpProvider = psnmp.NewSNMPProvider(s.address, s.port, s.community,
 		s.pollInterval, s.v, s.v3Params, s.mibs, false)
 
+	if s.trapAddress != "" {
+		r, err := psnmp.SharedTrapReceiver(s.trapAddress)
+		if err != nil {
+			return nil, s.deviceConfigErr(err)
+		}
+		s.snmpProvider.(*psnmp.Snmp).SetTrapReceiver(r)
+	}
+
+	if s.mappings != "" {
+		cfg, err := snmpoc.ReadMappingConfig(s.mappings)
+		if err != nil {
+			return nil, s.deviceConfigErr(err)
+		}
+		s.snmpProvider.(*psnmp.Snmp).SetMappingConfig(cfg)
+	}
+
+	s.snmpProvider.(*psnmp.Snmp).SetMIBCacheDir(s.mibCacheDir)
+	s.snmpProvider.(*psnmp.Snmp).SetCounterOptions(s.extendCounters, s.counterRates)
+	s.snmpProvider.(*psnmp.Snmp).SetSessionOptions(s.sessions, s.maxRate)
+	s.snmpProvider.(*psnmp.Snmp).SetRequestOptions(s.retries, s.timeout, s.expTimeout,
+		s.maxReps)
+	s.snmpProvider.(*psnmp.Snmp).SetWalkStrategy(s.walkStrategy)
+	s.snmpProvider.(*psnmp.Snmp).SetPollIntervals(s.pollIntervals)
+
 	return s, nil
 }
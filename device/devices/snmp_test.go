@@ -9,10 +9,11 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aristanetworks/cloudvision-go/device"
 	psnmp "github.com/aristanetworks/cloudvision-go/provider/snmp"
-	"github.com/soniah/gosnmp"
+	"github.com/gosnmp/gosnmp"
 )
 
 type optionsTestCase struct {
@@ -67,6 +68,14 @@ func runOptionsTest(t *testing.T, tc optionsTestCase) {
 		t.Fatalf("Expected v3 params %v, got %v", tc.expectedV3Params.UsmParams,
 			ss.v3Params.UsmParams)
 	}
+	if ss.v3Params.ContextName != tc.expectedV3Params.ContextName {
+		t.Fatalf("Expected context name %q, got %q",
+			tc.expectedV3Params.ContextName, ss.v3Params.ContextName)
+	}
+	if ss.v3Params.ContextEngineID != tc.expectedV3Params.ContextEngineID {
+		t.Fatalf("Expected context engine ID %q, got %q",
+			tc.expectedV3Params.ContextEngineID, ss.v3Params.ContextEngineID)
+	}
 }
 
 // Use default value unless a key is of form "k=v", in which case use
@@ -82,6 +91,10 @@ func selectOpt(keys ...string) map[string]string {
 		"X":       "xpass",
 		"u":       "user",
 		"mibs":    "/a/b/c",
+
+		"contextName":     "vrf-mgmt",
+		"contextEngineID": "80001f8804",
+		"engineID":        "0x80001f8803525400",
 	}
 	out := make(map[string]string)
 	for _, k := range keys {
@@ -116,6 +129,8 @@ func usmParams(keys ...string) *gosnmp.UsmSecurityParameters {
 			usm.PrivacyPassphrase = "xpass"
 		case "u":
 			usm.UserName = "user"
+		case "engineID":
+			usm.AuthoritativeEngineID = "\x80\x00\x1f\x88\x03\x52\x54\x00"
 		}
 	}
 	return usm
@@ -201,9 +216,40 @@ func TestOptions(t *testing.T) {
 				"key must be specified"),
 		},
 		{
-			name:          "no mibs",
-			options:       selectOpt("v", "address", "l", "a", "A", "x", "X", "u"),
-			expectedError: errors.New("Required option 'mibs' not provided"),
+			name: "v3 context and engine ID",
+			options: selectOpt("v", "address", "l", "a", "A", "x", "X", "u", "mibs",
+				"contextName", "contextEngineID", "engineID"),
+			expectedVersion: gosnmp.Version3,
+			expectedV3Params: &psnmp.V3Params{
+				SecurityModel:   gosnmp.UserSecurityModel,
+				Level:           gosnmp.AuthPriv,
+				UsmParams:       usmParams("a", "A", "x", "X", "u", "engineID"),
+				ContextName:     "vrf-mgmt",
+				ContextEngineID: "\x80\x00\x1f\x88\x04",
+			},
+		},
+		{
+			name: "v3 bad engine ID",
+			options: selectOpt("v", "address", "l", "a", "A", "x", "X", "u", "mibs",
+				"engineID=0x8000zz"),
+			expectedError: errors.New("Value for option 'engineID' ('0x8000zz') does " +
+				"not match regular expression '(0x)?([0-9a-fA-F]{2})+'"),
+		},
+		{
+			name:    "v3 unknown auth proto",
+			options: selectOpt("v", "address", "l", "a=sha1", "A", "x", "X", "u", "mibs"),
+			expectedError: errors.New("Value for option 'a' ('sha1') does not match " +
+				"regular expression '(?i)md5|sha|sha224|sha256|sha384|sha512'"),
+		},
+		{
+			name:            "no mibs",
+			options:         selectOpt("v", "address", "l", "a", "A", "x", "X", "u"),
+			expectedVersion: gosnmp.Version3,
+			expectedV3Params: &psnmp.V3Params{
+				SecurityModel: gosnmp.UserSecurityModel,
+				Level:         gosnmp.AuthPriv,
+				UsmParams:     usmParams("a", "A", "x", "X", "u"),
+			},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -211,3 +257,104 @@ func TestOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestV3Protocols(t *testing.T) {
+	authProtos := map[string]gosnmp.SnmpV3AuthProtocol{
+		"md5":    gosnmp.MD5,
+		"SHA":    gosnmp.SHA,
+		"sha224": gosnmp.SHA224,
+		"SHA256": gosnmp.SHA256,
+		"sha384": gosnmp.SHA384,
+		"sha512": gosnmp.SHA512,
+	}
+	privProtos := map[string]gosnmp.SnmpV3PrivProtocol{
+		"DES":     gosnmp.DES,
+		"aes":     gosnmp.AES,
+		"aes192":  gosnmp.AES192,
+		"AES256":  gosnmp.AES256,
+		"aes192c": gosnmp.AES192C,
+		"AES256C": gosnmp.AES256C,
+	}
+	for a, authProto := range authProtos {
+		for x, privProto := range privProtos {
+			usm := usmParams("A", "X", "u")
+			usm.AuthenticationProtocol = authProto
+			usm.PrivacyProtocol = privProto
+			tc := optionsTestCase{
+				name: a + "/" + x,
+				options: selectOpt("v", "address", "l", "a="+a, "A", "x="+x, "X",
+					"u", "mibs"),
+				expectedVersion: gosnmp.Version3,
+				expectedV3Params: &psnmp.V3Params{
+					SecurityModel: gosnmp.UserSecurityModel,
+					Level:         gosnmp.AuthPriv,
+					UsmParams:     usm,
+				},
+			}
+			t.Run(tc.name, func(t *testing.T) {
+				runOptionsTest(t, tc)
+			})
+		}
+	}
+}
+
+func TestPollIntervalOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		options   map[string]string
+		intervals map[string]time.Duration
+		err       string
+	}{
+		{
+			name:      "none",
+			options:   map[string]string{},
+			intervals: map[string]time.Duration{},
+		},
+		{
+			name: "models",
+			options: map[string]string{
+				"pollInterval.interfaces": "10s",
+				"pollInterval.platform":   "10m",
+			},
+			intervals: map[string]time.Duration{
+				"interfaces": 10 * time.Second,
+				"platform":   10 * time.Minute,
+			},
+		},
+		{
+			name:    "unknown model",
+			options: map[string]string{"pollInterval.bogus": "10s"},
+			err:     "Bad option 'pollInterval.bogus'",
+		},
+		{
+			name:    "zero",
+			options: map[string]string{"pollInterval.lldp": "0s"},
+			err: "Configuration error for device 1.1.1.1: " +
+				"Option 'pollInterval.lldp' must be positive",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := map[string]string{"c": "public", "address": "1.1.1.1"}
+			for k, v := range tc.options {
+				opts[k] = v
+			}
+			so, err := device.SanitizedOptions(options, opts)
+			var d device.Device
+			if err == nil {
+				d, err = newSnmp(so)
+			}
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("Expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.(*snmp).pollIntervals; !reflect.DeepEqual(got, tc.intervals) {
+				t.Fatalf("Expected poll intervals %v, got %v", tc.intervals, got)
+			}
+		})
+	}
+}
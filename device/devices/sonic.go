@@ -19,6 +19,11 @@ func init() {
 			Description: "Polling interval, with unit suffix (s/m/h)",
 			Default:     "20s",
 		},
+		"redisAddress": {
+			Description: "Address of the SONiC Redis server, as host:port " +
+				"or the path of a unix socket",
+			Default: "127.0.0.1:6379",
+		},
 	}
 	device.Register("sonic", NewSonicDevice, options)
 }
@@ -33,11 +38,6 @@ func (d *sonic) Alive() (bool, error) {
 	return true, nil
 }
 
-// Use the device's serial number as its ID.
-func (d *sonic) deviceSerial() (string, error) {
-	return "JPE16194299", nil
-}
-
 func (d *sonic) DeviceID() (string, error) {
 	return d.deviceID, nil
 }
@@ -53,13 +53,20 @@ func NewSonicDevice(options map[string]string) (device.Device, error) {
 		return nil, err
 	}
 
-	device := sonic{}
-	did, err := device.deviceSerial()
+	redisAddress, err := device.GetStringOption("redisAddress", options)
+	if err != nil {
+		return nil, err
+	}
+	client := psonic.NewRedisClient(redisAddress)
+
+	// Use the device's serial number as its ID.
+	did, err := psonic.DeviceID(client)
 	if err != nil {
 		return nil, fmt.Errorf("Failure getting device ID: %v", err)
 	}
-	device.deviceID = did
-	device.provider = psonic.NewSonicProvider(pollInterval)
 
-	return &device, nil
+	return &sonic{
+		deviceID: did,
+		provider: psonic.NewSonicProvider(client, pollInterval),
+	}, nil
 }
@@ -8,3 +8,4 @@ package gen
 // brew install protobuf
 
 //go:generate protoc --proto_path=${GOPATH}/src --go_out=plugins=grpc,:${GOPATH}/src github.com/aristanetworks/cloudvision-go/device/inventory.proto
+//go:generate protoc --proto_path=${GOPATH}/src --go_out=plugins=grpc,:${GOPATH}/src github.com/aristanetworks/cloudvision-go/device/plugin.proto
@@ -0,0 +1,979 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: github.com/aristanetworks/cloudvision-go/device/plugin.proto
+
+package gen
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	gnmi "github.com/openconfig/gnmi/proto/gnmi"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// PluginOption mirrors device.Option.
+type PluginOption struct {
+	Description          string   `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Default              string   `protobuf:"bytes,2,opt,name=default,proto3" json:"default,omitempty"`
+	Pattern              string   `protobuf:"bytes,3,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Required             bool     `protobuf:"varint,4,opt,name=required,proto3" json:"required,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PluginOption) Reset()         { *m = PluginOption{} }
+func (m *PluginOption) String() string { return proto.CompactTextString(m) }
+func (*PluginOption) ProtoMessage()    {}
+func (*PluginOption) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{0}
+}
+
+func (m *PluginOption) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PluginOption.Unmarshal(m, b)
+}
+func (m *PluginOption) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PluginOption.Marshal(b, m, deterministic)
+}
+func (m *PluginOption) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PluginOption.Merge(m, src)
+}
+func (m *PluginOption) XXX_Size() int {
+	return xxx_messageInfo_PluginOption.Size(m)
+}
+func (m *PluginOption) XXX_DiscardUnknown() {
+	xxx_messageInfo_PluginOption.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PluginOption proto.InternalMessageInfo
+
+func (m *PluginOption) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *PluginOption) GetDefault() string {
+	if m != nil {
+		return m.Default
+	}
+	return ""
+}
+
+func (m *PluginOption) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
+func (m *PluginOption) GetRequired() bool {
+	if m != nil {
+		return m.Required
+	}
+	return false
+}
+
+// PluginDevice describes a device type served by a plugin.
+type PluginDevice struct {
+	Name                 string                   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Options              map[string]*PluginOption `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *PluginDevice) Reset()         { *m = PluginDevice{} }
+func (m *PluginDevice) String() string { return proto.CompactTextString(m) }
+func (*PluginDevice) ProtoMessage()    {}
+func (*PluginDevice) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{1}
+}
+
+func (m *PluginDevice) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PluginDevice.Unmarshal(m, b)
+}
+func (m *PluginDevice) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PluginDevice.Marshal(b, m, deterministic)
+}
+func (m *PluginDevice) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PluginDevice.Merge(m, src)
+}
+func (m *PluginDevice) XXX_Size() int {
+	return xxx_messageInfo_PluginDevice.Size(m)
+}
+func (m *PluginDevice) XXX_DiscardUnknown() {
+	xxx_messageInfo_PluginDevice.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PluginDevice proto.InternalMessageInfo
+
+func (m *PluginDevice) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PluginDevice) GetOptions() map[string]*PluginOption {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+type DescribeRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DescribeRequest) Reset()         { *m = DescribeRequest{} }
+func (m *DescribeRequest) String() string { return proto.CompactTextString(m) }
+func (*DescribeRequest) ProtoMessage()    {}
+func (*DescribeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{2}
+}
+
+func (m *DescribeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DescribeRequest.Unmarshal(m, b)
+}
+func (m *DescribeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DescribeRequest.Marshal(b, m, deterministic)
+}
+func (m *DescribeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DescribeRequest.Merge(m, src)
+}
+func (m *DescribeRequest) XXX_Size() int {
+	return xxx_messageInfo_DescribeRequest.Size(m)
+}
+func (m *DescribeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DescribeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DescribeRequest proto.InternalMessageInfo
+
+type DescribeResponse struct {
+	Devices              []*PluginDevice `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *DescribeResponse) Reset()         { *m = DescribeResponse{} }
+func (m *DescribeResponse) String() string { return proto.CompactTextString(m) }
+func (*DescribeResponse) ProtoMessage()    {}
+func (*DescribeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{3}
+}
+
+func (m *DescribeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DescribeResponse.Unmarshal(m, b)
+}
+func (m *DescribeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DescribeResponse.Marshal(b, m, deterministic)
+}
+func (m *DescribeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DescribeResponse.Merge(m, src)
+}
+func (m *DescribeResponse) XXX_Size() int {
+	return xxx_messageInfo_DescribeResponse.Size(m)
+}
+func (m *DescribeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DescribeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DescribeResponse proto.InternalMessageInfo
+
+func (m *DescribeResponse) GetDevices() []*PluginDevice {
+	if m != nil {
+		return m.Devices
+	}
+	return nil
+}
+
+type CreateRequest struct {
+	DeviceConfig         *DeviceConfig `protobuf:"bytes,1,opt,name=deviceConfig,proto3" json:"deviceConfig,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *CreateRequest) Reset()         { *m = CreateRequest{} }
+func (m *CreateRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateRequest) ProtoMessage()    {}
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{4}
+}
+
+func (m *CreateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateRequest.Unmarshal(m, b)
+}
+func (m *CreateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateRequest.Merge(m, src)
+}
+func (m *CreateRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateRequest.Size(m)
+}
+func (m *CreateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateRequest proto.InternalMessageInfo
+
+func (m *CreateRequest) GetDeviceConfig() *DeviceConfig {
+	if m != nil {
+		return m.DeviceConfig
+	}
+	return nil
+}
+
+// CreateResponse carries a plugin-assigned handle by which the
+// created device is referred to in subsequent requests.
+type CreateResponse struct {
+	Handle               string   `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateResponse) Reset()         { *m = CreateResponse{} }
+func (m *CreateResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateResponse) ProtoMessage()    {}
+func (*CreateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{5}
+}
+
+func (m *CreateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateResponse.Unmarshal(m, b)
+}
+func (m *CreateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateResponse.Marshal(b, m, deterministic)
+}
+func (m *CreateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateResponse.Merge(m, src)
+}
+func (m *CreateResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateResponse.Size(m)
+}
+func (m *CreateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateResponse proto.InternalMessageInfo
+
+func (m *CreateResponse) GetHandle() string {
+	if m != nil {
+		return m.Handle
+	}
+	return ""
+}
+
+type AliveRequest struct {
+	Handle               string   `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AliveRequest) Reset()         { *m = AliveRequest{} }
+func (m *AliveRequest) String() string { return proto.CompactTextString(m) }
+func (*AliveRequest) ProtoMessage()    {}
+func (*AliveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{6}
+}
+
+func (m *AliveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AliveRequest.Unmarshal(m, b)
+}
+func (m *AliveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AliveRequest.Marshal(b, m, deterministic)
+}
+func (m *AliveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AliveRequest.Merge(m, src)
+}
+func (m *AliveRequest) XXX_Size() int {
+	return xxx_messageInfo_AliveRequest.Size(m)
+}
+func (m *AliveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AliveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AliveRequest proto.InternalMessageInfo
+
+func (m *AliveRequest) GetHandle() string {
+	if m != nil {
+		return m.Handle
+	}
+	return ""
+}
+
+type AliveResponse struct {
+	Alive                bool     `protobuf:"varint,1,opt,name=alive,proto3" json:"alive,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AliveResponse) Reset()         { *m = AliveResponse{} }
+func (m *AliveResponse) String() string { return proto.CompactTextString(m) }
+func (*AliveResponse) ProtoMessage()    {}
+func (*AliveResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{7}
+}
+
+func (m *AliveResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AliveResponse.Unmarshal(m, b)
+}
+func (m *AliveResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AliveResponse.Marshal(b, m, deterministic)
+}
+func (m *AliveResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AliveResponse.Merge(m, src)
+}
+func (m *AliveResponse) XXX_Size() int {
+	return xxx_messageInfo_AliveResponse.Size(m)
+}
+func (m *AliveResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AliveResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AliveResponse proto.InternalMessageInfo
+
+func (m *AliveResponse) GetAlive() bool {
+	if m != nil {
+		return m.Alive
+	}
+	return false
+}
+
+type DeviceIDRequest struct {
+	Handle               string   `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeviceIDRequest) Reset()         { *m = DeviceIDRequest{} }
+func (m *DeviceIDRequest) String() string { return proto.CompactTextString(m) }
+func (*DeviceIDRequest) ProtoMessage()    {}
+func (*DeviceIDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{8}
+}
+
+func (m *DeviceIDRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeviceIDRequest.Unmarshal(m, b)
+}
+func (m *DeviceIDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeviceIDRequest.Marshal(b, m, deterministic)
+}
+func (m *DeviceIDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeviceIDRequest.Merge(m, src)
+}
+func (m *DeviceIDRequest) XXX_Size() int {
+	return xxx_messageInfo_DeviceIDRequest.Size(m)
+}
+func (m *DeviceIDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeviceIDRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeviceIDRequest proto.InternalMessageInfo
+
+func (m *DeviceIDRequest) GetHandle() string {
+	if m != nil {
+		return m.Handle
+	}
+	return ""
+}
+
+type DeviceIDResponse struct {
+	DeviceID             string   `protobuf:"bytes,1,opt,name=deviceID,proto3" json:"deviceID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeviceIDResponse) Reset()         { *m = DeviceIDResponse{} }
+func (m *DeviceIDResponse) String() string { return proto.CompactTextString(m) }
+func (*DeviceIDResponse) ProtoMessage()    {}
+func (*DeviceIDResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{9}
+}
+
+func (m *DeviceIDResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeviceIDResponse.Unmarshal(m, b)
+}
+func (m *DeviceIDResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeviceIDResponse.Marshal(b, m, deterministic)
+}
+func (m *DeviceIDResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeviceIDResponse.Merge(m, src)
+}
+func (m *DeviceIDResponse) XXX_Size() int {
+	return xxx_messageInfo_DeviceIDResponse.Size(m)
+}
+func (m *DeviceIDResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeviceIDResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeviceIDResponse proto.InternalMessageInfo
+
+func (m *DeviceIDResponse) GetDeviceID() string {
+	if m != nil {
+		return m.DeviceID
+	}
+	return ""
+}
+
+type ProvidersRequest struct {
+	Handle               string   `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProvidersRequest) Reset()         { *m = ProvidersRequest{} }
+func (m *ProvidersRequest) String() string { return proto.CompactTextString(m) }
+func (*ProvidersRequest) ProtoMessage()    {}
+func (*ProvidersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{10}
+}
+
+func (m *ProvidersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProvidersRequest.Unmarshal(m, b)
+}
+func (m *ProvidersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProvidersRequest.Marshal(b, m, deterministic)
+}
+func (m *ProvidersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProvidersRequest.Merge(m, src)
+}
+func (m *ProvidersRequest) XXX_Size() int {
+	return xxx_messageInfo_ProvidersRequest.Size(m)
+}
+func (m *ProvidersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProvidersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProvidersRequest proto.InternalMessageInfo
+
+func (m *ProvidersRequest) GetHandle() string {
+	if m != nil {
+		return m.Handle
+	}
+	return ""
+}
+
+// PluginProvider describes one of a device's providers. Only gNMI
+// providers can be served by plugins.
+type PluginProvider struct {
+	Index uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// openConfig is true if the provider emits OpenConfig-modeled
+	// data that should be type-checked.
+	OpenConfig           bool     `protobuf:"varint,2,opt,name=openConfig,proto3" json:"openConfig,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PluginProvider) Reset()         { *m = PluginProvider{} }
+func (m *PluginProvider) String() string { return proto.CompactTextString(m) }
+func (*PluginProvider) ProtoMessage()    {}
+func (*PluginProvider) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{11}
+}
+
+func (m *PluginProvider) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PluginProvider.Unmarshal(m, b)
+}
+func (m *PluginProvider) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PluginProvider.Marshal(b, m, deterministic)
+}
+func (m *PluginProvider) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PluginProvider.Merge(m, src)
+}
+func (m *PluginProvider) XXX_Size() int {
+	return xxx_messageInfo_PluginProvider.Size(m)
+}
+func (m *PluginProvider) XXX_DiscardUnknown() {
+	xxx_messageInfo_PluginProvider.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PluginProvider proto.InternalMessageInfo
+
+func (m *PluginProvider) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *PluginProvider) GetOpenConfig() bool {
+	if m != nil {
+		return m.OpenConfig
+	}
+	return false
+}
+
+type ProvidersResponse struct {
+	Providers            []*PluginProvider `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ProvidersResponse) Reset()         { *m = ProvidersResponse{} }
+func (m *ProvidersResponse) String() string { return proto.CompactTextString(m) }
+func (*ProvidersResponse) ProtoMessage()    {}
+func (*ProvidersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{12}
+}
+
+func (m *ProvidersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProvidersResponse.Unmarshal(m, b)
+}
+func (m *ProvidersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProvidersResponse.Marshal(b, m, deterministic)
+}
+func (m *ProvidersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProvidersResponse.Merge(m, src)
+}
+func (m *ProvidersResponse) XXX_Size() int {
+	return xxx_messageInfo_ProvidersResponse.Size(m)
+}
+func (m *ProvidersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProvidersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProvidersResponse proto.InternalMessageInfo
+
+func (m *ProvidersResponse) GetProviders() []*PluginProvider {
+	if m != nil {
+		return m.Providers
+	}
+	return nil
+}
+
+type RunProviderRequest struct {
+	Handle               string   `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Index                uint32   `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunProviderRequest) Reset()         { *m = RunProviderRequest{} }
+func (m *RunProviderRequest) String() string { return proto.CompactTextString(m) }
+func (*RunProviderRequest) ProtoMessage()    {}
+func (*RunProviderRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e89f0186c7af854, []int{13}
+}
+
+func (m *RunProviderRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunProviderRequest.Unmarshal(m, b)
+}
+func (m *RunProviderRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunProviderRequest.Marshal(b, m, deterministic)
+}
+func (m *RunProviderRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunProviderRequest.Merge(m, src)
+}
+func (m *RunProviderRequest) XXX_Size() int {
+	return xxx_messageInfo_RunProviderRequest.Size(m)
+}
+func (m *RunProviderRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunProviderRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunProviderRequest proto.InternalMessageInfo
+
+func (m *RunProviderRequest) GetHandle() string {
+	if m != nil {
+		return m.Handle
+	}
+	return ""
+}
+
+func (m *RunProviderRequest) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*PluginOption)(nil), "arista.cloudvision.PluginOption")
+	proto.RegisterType((*PluginDevice)(nil), "arista.cloudvision.PluginDevice")
+	proto.RegisterMapType((map[string]*PluginOption)(nil), "arista.cloudvision.PluginDevice.OptionsEntry")
+	proto.RegisterType((*DescribeRequest)(nil), "arista.cloudvision.DescribeRequest")
+	proto.RegisterType((*DescribeResponse)(nil), "arista.cloudvision.DescribeResponse")
+	proto.RegisterType((*CreateRequest)(nil), "arista.cloudvision.CreateRequest")
+	proto.RegisterType((*CreateResponse)(nil), "arista.cloudvision.CreateResponse")
+	proto.RegisterType((*AliveRequest)(nil), "arista.cloudvision.AliveRequest")
+	proto.RegisterType((*AliveResponse)(nil), "arista.cloudvision.AliveResponse")
+	proto.RegisterType((*DeviceIDRequest)(nil), "arista.cloudvision.DeviceIDRequest")
+	proto.RegisterType((*DeviceIDResponse)(nil), "arista.cloudvision.DeviceIDResponse")
+	proto.RegisterType((*ProvidersRequest)(nil), "arista.cloudvision.ProvidersRequest")
+	proto.RegisterType((*PluginProvider)(nil), "arista.cloudvision.PluginProvider")
+	proto.RegisterType((*ProvidersResponse)(nil), "arista.cloudvision.ProvidersResponse")
+	proto.RegisterType((*RunProviderRequest)(nil), "arista.cloudvision.RunProviderRequest")
+}
+
+func init() {
+	proto.RegisterFile("github.com/aristanetworks/cloudvision-go/device/plugin.proto", fileDescriptor_3e89f0186c7af854)
+}
+
+var fileDescriptor_3e89f0186c7af854 = []byte{
+	// 646 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0x5d, 0x4b, 0xdc, 0x40,
+	0x14, 0x25, 0xbb, 0x7e, 0xac, 0x77, 0x57, 0xbb, 0x0e, 0xa5, 0x84, 0x3c, 0x94, 0x38, 0x55, 0xd9,
+	0x16, 0x4c, 0x44, 0x69, 0x29, 0x52, 0x68, 0xab, 0xdb, 0x16, 0xa1, 0x54, 0x49, 0x11, 0x4a, 0xe9,
+	0x4b, 0xdc, 0x8c, 0x71, 0x30, 0xce, 0xc4, 0xc9, 0x24, 0xad, 0x6f, 0xfe, 0xbd, 0xfe, 0xab, 0x92,
+	0x99, 0xc9, 0x6e, 0xb6, 0xee, 0x46, 0x7c, 0x91, 0xb9, 0xf7, 0x9e, 0x7b, 0xee, 0xd7, 0x71, 0x03,
+	0xef, 0x62, 0x2a, 0x2f, 0xf3, 0x73, 0x6f, 0xc4, 0xaf, 0xfd, 0x50, 0xd0, 0x4c, 0x86, 0x8c, 0xc8,
+	0xdf, 0x5c, 0x5c, 0x65, 0xfe, 0x28, 0xe1, 0x79, 0x54, 0xd0, 0x8c, 0x72, 0xb6, 0x13, 0x73, 0x3f,
+	0x22, 0x05, 0x1d, 0x11, 0x3f, 0x4d, 0xf2, 0x98, 0x32, 0x2f, 0x15, 0x5c, 0x72, 0x84, 0x74, 0x8a,
+	0x57, 0x83, 0x3a, 0xef, 0x1f, 0xcb, 0x48, 0x59, 0x41, 0x98, 0xe4, 0xe2, 0x56, 0x93, 0x3a, 0xbb,
+	0x35, 0x02, 0x9e, 0x12, 0x36, 0xe2, 0xec, 0x82, 0xc6, 0x7e, 0xcc, 0xae, 0xa9, 0xaf, 0x10, 0xfa,
+	0x59, 0xfe, 0xd1, 0x19, 0xf8, 0xce, 0x82, 0xde, 0xa9, 0xea, 0xeb, 0x24, 0x95, 0x94, 0x33, 0xe4,
+	0x42, 0x37, 0x22, 0xd9, 0x48, 0x50, 0x65, 0xda, 0x96, 0x6b, 0x0d, 0x56, 0x82, 0xba, 0x0b, 0xd9,
+	0xb0, 0x1c, 0x91, 0x8b, 0x30, 0x4f, 0xa4, 0xdd, 0x52, 0xd1, 0xca, 0x2c, 0x23, 0x69, 0x28, 0x25,
+	0x11, 0xcc, 0x6e, 0xeb, 0x88, 0x31, 0x91, 0x03, 0x1d, 0x41, 0x6e, 0x72, 0x2a, 0x48, 0x64, 0x2f,
+	0xb8, 0xd6, 0xa0, 0x13, 0x8c, 0x6d, 0xfc, 0x77, 0xdc, 0xc2, 0x50, 0x4d, 0x85, 0x10, 0x2c, 0xb0,
+	0xf0, 0x9a, 0x98, 0xda, 0xea, 0x8d, 0xbe, 0xc0, 0x32, 0x57, 0xe5, 0x33, 0xbb, 0xe5, 0xb6, 0x07,
+	0xdd, 0xbd, 0x1d, 0xef, 0xfe, 0x02, 0xbd, 0x3a, 0x8d, 0xa7, 0x07, 0xca, 0x3e, 0x31, 0x29, 0x6e,
+	0x83, 0x2a, 0xdb, 0xf9, 0x05, 0xbd, 0x7a, 0x00, 0xf5, 0xa1, 0x7d, 0x45, 0x6e, 0x4d, 0xad, 0xf2,
+	0x89, 0xde, 0xc0, 0x62, 0x11, 0x26, 0x39, 0x51, 0xd3, 0x75, 0xf7, 0xdc, 0xf9, 0x85, 0x34, 0x51,
+	0xa0, 0xe1, 0x07, 0xad, 0xb7, 0x16, 0x5e, 0x87, 0x27, 0x43, 0xb5, 0xaa, 0x73, 0x12, 0x90, 0x9b,
+	0x9c, 0x64, 0x12, 0x7f, 0x83, 0xfe, 0xc4, 0x95, 0xa5, 0x9c, 0x65, 0x04, 0x1d, 0x94, 0x2b, 0x2c,
+	0x9b, 0xcc, 0x6c, 0xcb, 0x6d, 0x37, 0x17, 0xd1, 0xd3, 0x04, 0x55, 0x02, 0x3e, 0x83, 0xd5, 0x23,
+	0x41, 0x42, 0x59, 0x15, 0x40, 0x43, 0xe8, 0xe9, 0xd8, 0x91, 0xba, 0xb6, 0x1a, 0x65, 0x0e, 0xe3,
+	0xb0, 0x86, 0x0b, 0xa6, 0xb2, 0xf0, 0x00, 0xd6, 0x2a, 0x5a, 0xd3, 0xe4, 0x33, 0x58, 0xba, 0x0c,
+	0x59, 0x94, 0x54, 0x87, 0x30, 0x16, 0xde, 0x86, 0xde, 0xc7, 0x84, 0x16, 0xe3, 0xfa, 0xf3, 0x70,
+	0x5b, 0xb0, 0x6a, 0x70, 0x86, 0xf0, 0x29, 0x2c, 0x86, 0xa5, 0x43, 0xe1, 0x3a, 0x81, 0x36, 0xf0,
+	0xcb, 0x72, 0x65, 0x65, 0x23, 0xc7, 0xc3, 0x87, 0x18, 0x3d, 0xe8, 0x4f, 0xa0, 0x86, 0xd4, 0x81,
+	0x4e, 0x64, 0x7c, 0x06, 0x3d, 0xb6, 0xf1, 0x2b, 0xe8, 0x9f, 0x0a, 0x5e, 0xd0, 0x88, 0x88, 0xec,
+	0x21, 0xee, 0xcf, 0xb0, 0xa6, 0xf7, 0x5d, 0x65, 0x94, 0xed, 0x52, 0x16, 0x91, 0x3f, 0x0a, 0xb8,
+	0x1a, 0x68, 0x03, 0x3d, 0x07, 0x28, 0xff, 0xb3, 0xcc, 0xae, 0x5b, 0x6a, 0x92, 0x9a, 0x07, 0x9f,
+	0xc1, 0x7a, 0xad, 0xa6, 0x69, 0xf2, 0x03, 0xac, 0xa4, 0x95, 0xd3, 0x5c, 0x1c, 0xcf, 0xbf, 0x78,
+	0x95, 0x1f, 0x4c, 0x92, 0xf0, 0x21, 0xa0, 0x20, 0x9f, 0x44, 0x9a, 0x87, 0x99, 0xb4, 0xde, 0xaa,
+	0xb5, 0xbe, 0x77, 0xb7, 0x00, 0x3d, 0xbd, 0x3f, 0x5d, 0x07, 0x9d, 0x41, 0xa7, 0x92, 0x26, 0x7a,
+	0x31, 0x5b, 0x2f, 0x53, 0x5a, 0x76, 0x36, 0x9b, 0x41, 0x66, 0xda, 0x13, 0x58, 0xd2, 0x52, 0x42,
+	0x1b, 0xb3, 0xf0, 0x53, 0xea, 0x75, 0x70, 0x13, 0xc4, 0x10, 0x7e, 0x85, 0x45, 0xa5, 0x24, 0x34,
+	0x53, 0xd4, 0x75, 0x31, 0x3a, 0x1b, 0x0d, 0x08, 0xc3, 0xa6, 0xa6, 0xd6, 0x0a, 0x99, 0x37, 0xf5,
+	0x94, 0x1c, 0x9d, 0xcd, 0x66, 0x90, 0xa1, 0xfd, 0x01, 0x2b, 0xe3, 0xc3, 0xa3, 0x99, 0x29, 0xff,
+	0x6b, 0xd1, 0xd9, 0x7a, 0x00, 0x65, 0x98, 0x8f, 0xa1, 0x5b, 0xbb, 0x3d, 0xda, 0x9e, 0x95, 0x75,
+	0x5f, 0x1c, 0x4e, 0xdf, 0x53, 0xbf, 0xf3, 0xdf, 0x89, 0x34, 0x9e, 0x5d, 0xeb, 0xf0, 0xf5, 0xcf,
+	0xfd, 0xc7, 0x7e, 0x63, 0x62, 0xc2, 0xce, 0x97, 0xd4, 0xc7, 0x62, 0xff, 0xdf, 0x00, 0x0e, 0x00,
+	0x1f, 0x1a, 0xf3, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DevicePluginClient is the client API for DevicePlugin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DevicePluginClient interface {
+	Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*DescribeResponse, error)
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	Alive(ctx context.Context, in *AliveRequest, opts ...grpc.CallOption) (*AliveResponse, error)
+	DeviceID(ctx context.Context, in *DeviceIDRequest, opts ...grpc.CallOption) (*DeviceIDResponse, error)
+	Providers(ctx context.Context, in *ProvidersRequest, opts ...grpc.CallOption) (*ProvidersResponse, error)
+	// RunProvider runs the specified provider, streaming back each
+	// SetRequest it issues.
+	RunProvider(ctx context.Context, in *RunProviderRequest, opts ...grpc.CallOption) (DevicePlugin_RunProviderClient, error)
+}
+
+type devicePluginClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDevicePluginClient(cc *grpc.ClientConn) DevicePluginClient {
+	return &devicePluginClient{cc}
+}
+
+func (c *devicePluginClient) Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*DescribeResponse, error) {
+	out := new(DescribeResponse)
+	err := c.cc.Invoke(ctx, "/arista.cloudvision.DevicePlugin/Describe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicePluginClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, "/arista.cloudvision.DevicePlugin/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicePluginClient) Alive(ctx context.Context, in *AliveRequest, opts ...grpc.CallOption) (*AliveResponse, error) {
+	out := new(AliveResponse)
+	err := c.cc.Invoke(ctx, "/arista.cloudvision.DevicePlugin/Alive", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicePluginClient) DeviceID(ctx context.Context, in *DeviceIDRequest, opts ...grpc.CallOption) (*DeviceIDResponse, error) {
+	out := new(DeviceIDResponse)
+	err := c.cc.Invoke(ctx, "/arista.cloudvision.DevicePlugin/DeviceID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicePluginClient) Providers(ctx context.Context, in *ProvidersRequest, opts ...grpc.CallOption) (*ProvidersResponse, error) {
+	out := new(ProvidersResponse)
+	err := c.cc.Invoke(ctx, "/arista.cloudvision.DevicePlugin/Providers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *devicePluginClient) RunProvider(ctx context.Context, in *RunProviderRequest, opts ...grpc.CallOption) (DevicePlugin_RunProviderClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DevicePlugin_serviceDesc.Streams[0], "/arista.cloudvision.DevicePlugin/RunProvider", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &devicePluginRunProviderClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DevicePlugin_RunProviderClient interface {
+	Recv() (*gnmi.SetRequest, error)
+	grpc.ClientStream
+}
+
+type devicePluginRunProviderClient struct {
+	grpc.ClientStream
+}
+
+func (x *devicePluginRunProviderClient) Recv() (*gnmi.SetRequest, error) {
+	m := new(gnmi.SetRequest)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DevicePluginServer is the server API for DevicePlugin service.
+type DevicePluginServer interface {
+	Describe(context.Context, *DescribeRequest) (*DescribeResponse, error)
+	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Alive(context.Context, *AliveRequest) (*AliveResponse, error)
+	DeviceID(context.Context, *DeviceIDRequest) (*DeviceIDResponse, error)
+	Providers(context.Context, *ProvidersRequest) (*ProvidersResponse, error)
+	// RunProvider runs the specified provider, streaming back each
+	// SetRequest it issues.
+	RunProvider(*RunProviderRequest, DevicePlugin_RunProviderServer) error
+}
+
+// UnimplementedDevicePluginServer can be embedded to have forward compatible implementations.
+type UnimplementedDevicePluginServer struct {
+}
+
+func (*UnimplementedDevicePluginServer) Describe(ctx context.Context, req *DescribeRequest) (*DescribeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Describe not implemented")
+}
+func (*UnimplementedDevicePluginServer) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (*UnimplementedDevicePluginServer) Alive(ctx context.Context, req *AliveRequest) (*AliveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Alive not implemented")
+}
+func (*UnimplementedDevicePluginServer) DeviceID(ctx context.Context, req *DeviceIDRequest) (*DeviceIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeviceID not implemented")
+}
+func (*UnimplementedDevicePluginServer) Providers(ctx context.Context, req *ProvidersRequest) (*ProvidersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Providers not implemented")
+}
+func (*UnimplementedDevicePluginServer) RunProvider(req *RunProviderRequest, srv DevicePlugin_RunProviderServer) error {
+	return status.Errorf(codes.Unimplemented, "method RunProvider not implemented")
+}
+
+func RegisterDevicePluginServer(s *grpc.Server, srv DevicePluginServer) {
+	s.RegisterService(&_DevicePlugin_serviceDesc, srv)
+}
+
+func _DevicePlugin_Describe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicePluginServer).Describe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/arista.cloudvision.DevicePlugin/Describe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicePluginServer).Describe(ctx, req.(*DescribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicePlugin_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicePluginServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/arista.cloudvision.DevicePlugin/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicePluginServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicePlugin_Alive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AliveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicePluginServer).Alive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/arista.cloudvision.DevicePlugin/Alive",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicePluginServer).Alive(ctx, req.(*AliveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicePlugin_DeviceID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicePluginServer).DeviceID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/arista.cloudvision.DevicePlugin/DeviceID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicePluginServer).DeviceID(ctx, req.(*DeviceIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicePlugin_Providers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProvidersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DevicePluginServer).Providers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/arista.cloudvision.DevicePlugin/Providers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DevicePluginServer).Providers(ctx, req.(*ProvidersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DevicePlugin_RunProvider_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RunProviderRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DevicePluginServer).RunProvider(m, &devicePluginRunProviderServer{stream})
+}
+
+type DevicePlugin_RunProviderServer interface {
+	Send(*gnmi.SetRequest) error
+	grpc.ServerStream
+}
+
+type devicePluginRunProviderServer struct {
+	grpc.ServerStream
+}
+
+func (x *devicePluginRunProviderServer) Send(m *gnmi.SetRequest) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _DevicePlugin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "arista.cloudvision.DevicePlugin",
+	HandlerType: (*DevicePluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Describe",
+			Handler:    _DevicePlugin_Describe_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _DevicePlugin_Create_Handler,
+		},
+		{
+			MethodName: "Alive",
+			Handler:    _DevicePlugin_Alive_Handler,
+		},
+		{
+			MethodName: "DeviceID",
+			Handler:    _DevicePlugin_DeviceID_Handler,
+		},
+		{
+			MethodName: "Providers",
+			Handler:    _DevicePlugin_Providers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RunProvider",
+			Handler:       _DevicePlugin_RunProvider_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "github.com/aristanetworks/cloudvision-go/device/plugin.proto",
+}
@@ -174,12 +174,15 @@ func GetDurationOption(optionName string,
 }
 
 // GetStringListOption returns the option specified by optionName as
-// a string slice.
+// a string slice. An empty option is an empty slice.
 func GetStringListOption(optionName string,
 	options map[string]string) ([]string, error) {
 	o, ok := options[optionName]
 	if !ok {
 		return nil, fmt.Errorf("No option '%s'", optionName)
 	}
+	if o == "" {
+		return []string{}, nil
+	}
 	return strings.Split(o, ","), nil
 }
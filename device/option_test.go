@@ -382,6 +382,11 @@ func TestGetOptionHelpers(t *testing.T) {
 			optionString: "/a/b/c,/d/e/f,/g/h/i",
 			expectedList: []string{"/a/b/c", "/d/e/f", "/g/h/i"},
 		},
+		{
+			name:         "empty",
+			optionString: "",
+			expectedList: []string{},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			om := map[string]string{"x": tc.optionString}
@@ -0,0 +1,268 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aristanetworks/cloudvision-go/device"
+	"github.com/aristanetworks/cloudvision-go/device/gen"
+	"github.com/aristanetworks/cloudvision-go/provider"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// dialTimeout is how long we wait for a plugin to start serving.
+const dialTimeout = 10 * time.Second
+
+// running tracks the plugins started by Load until they've exited
+// and their sockets are removed.
+var running sync.WaitGroup
+
+// Load starts the plugin executable at the specified path and
+// registers the devices it serves. If path is a directory, every
+// executable file in it is loaded. Plugins are killed when ctx is
+// cancelled. A plugin serving a device of the same name as one
+// that's already registered is rejected.
+func Load(ctx context.Context, path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return loadPlugin(ctx, path)
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() || f.Mode()&0111 == 0 {
+			continue
+		}
+		if err := loadPlugin(ctx, filepath.Join(path, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wait waits for every plugin started by Load to exit and be cleaned
+// up after, as they are once the contexts they were loaded with are
+// cancelled.
+func Wait() {
+	running.Wait()
+}
+
+func dialUnix(ctx context.Context, addr string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", addr)
+}
+
+func loadPlugin(ctx context.Context, path string) error {
+	dir, err := ioutil.TempDir("", "cloudvision-plugin")
+	if err != nil {
+		return err
+	}
+	socket := filepath.Join(dir, "plugin.sock")
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Env = append(os.Environ(), socketEnv+"="+socket)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("Failed to start plugin %s: %v", path, err)
+	}
+	exited := make(chan struct{})
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+			logrus.Errorf("Plugin %s exited with error: %v", path, err)
+		}
+		os.RemoveAll(dir)
+		close(exited)
+	}()
+
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	go func() {
+		// Give up dialing right away if the plugin dies on startup.
+		select {
+		case <-exited:
+			cancel()
+		case <-dialCtx.Done():
+		}
+	}()
+	conn, err := grpc.DialContext(dialCtx, socket, grpc.WithInsecure(),
+		grpc.WithBlock(), grpc.WithContextDialer(dialUnix))
+	if err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("Failed to connect to plugin %s: %v", path, err)
+	}
+	go func() {
+		<-exited
+		conn.Close()
+	}()
+
+	devices, err := describe(ctx, gen.NewDevicePluginClient(conn))
+	if err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("Failed to describe plugin %s: %v", path, err)
+	}
+	// Don't let a plugin replace an in-tree device, or another
+	// plugin's, by registering the same name.
+	for _, d := range devices {
+		if _, err := device.Options(d.name); err == nil {
+			cmd.Process.Kill()
+			return fmt.Errorf("Plugin %s serves device %s, which is already registered",
+				path, d.name)
+		}
+	}
+	for _, d := range devices {
+		device.Register(d.name, d.creator, d.options)
+	}
+	return nil
+}
+
+// registration holds what's needed to register a plugin device.
+type registration struct {
+	name    string
+	creator device.Creator
+	options map[string]device.Option
+}
+
+// describe asks the plugin for the devices it serves and returns a
+// registration for each of them.
+func describe(ctx context.Context, client gen.DevicePluginClient) ([]registration, error) {
+	resp, err := client.Describe(ctx, &gen.DescribeRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var regs []registration
+	for _, d := range resp.GetDevices() {
+		regs = append(regs, registration{
+			name:    d.GetName(),
+			creator: newCreator(ctx, client, d.GetName()),
+			options: optionsFromProto(d.GetOptions()),
+		})
+	}
+	return regs, nil
+}
+
+func newCreator(ctx context.Context, client gen.DevicePluginClient,
+	name string) device.Creator {
+	return func(options map[string]string) (device.Device, error) {
+		resp, err := client.Create(ctx, &gen.CreateRequest{
+			DeviceConfig: &gen.DeviceConfig{
+				Options:    options,
+				DeviceType: name,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &pluginDevice{
+			ctx:    ctx,
+			client: client,
+			handle: resp.GetHandle(),
+		}, nil
+	}
+}
+
+// pluginDevice implements device.Device by forwarding requests to a
+// device created in a plugin.
+type pluginDevice struct {
+	ctx    context.Context
+	client gen.DevicePluginClient
+	handle string
+}
+
+func (d *pluginDevice) Alive() (bool, error) {
+	resp, err := d.client.Alive(d.ctx, &gen.AliveRequest{Handle: d.handle})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetAlive(), nil
+}
+
+func (d *pluginDevice) DeviceID() (string, error) {
+	resp, err := d.client.DeviceID(d.ctx, &gen.DeviceIDRequest{Handle: d.handle})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetDeviceID(), nil
+}
+
+func (d *pluginDevice) Providers() ([]provider.Provider, error) {
+	resp, err := d.client.Providers(d.ctx, &gen.ProvidersRequest{Handle: d.handle})
+	if err != nil {
+		return nil, err
+	}
+	var providers []provider.Provider
+	for _, p := range resp.GetProviders() {
+		providers = append(providers, &pluginProvider{
+			client:     d.client,
+			handle:     d.handle,
+			index:      p.GetIndex(),
+			openConfig: p.GetOpenConfig(),
+		})
+	}
+	return providers, nil
+}
+
+// pluginProvider implements provider.GNMIProvider by running a
+// provider in a plugin and forwarding the SetRequests it streams back
+// to the Collector's gNMI client.
+type pluginProvider struct {
+	client     gen.DevicePluginClient
+	handle     string
+	index      uint32
+	openConfig bool
+	gnmiClient gnmi.GNMIClient
+}
+
+func (p *pluginProvider) InitGNMI(client gnmi.GNMIClient) {
+	p.gnmiClient = client
+}
+
+func (p *pluginProvider) OpenConfig() bool {
+	return p.openConfig
+}
+
+func (p *pluginProvider) Run(ctx context.Context) error {
+	stream, err := p.client.RunProvider(ctx, &gen.RunProviderRequest{
+		Handle: p.handle,
+		Index:  p.index,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if _, err := p.gnmiClient.Set(ctx, req); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+// Package plugin allows devices to be implemented outside of the
+// Collector binary.
+//
+// A plugin is an executable that registers one or more devices with
+// device.Register, exactly as an in-tree device would, and then calls
+// Serve. The Collector starts the plugin with Load, which registers a
+// proxy for each device the plugin serves. From then on those devices
+// can be used like any other: the proxy forwards Alive, DeviceID and
+// Providers to the plugin over gRPC, and each provider runs inside the
+// plugin, streaming the SetRequests it issues back to the Collector.
+package plugin
+
+import (
+	"github.com/aristanetworks/cloudvision-go/device"
+	"github.com/aristanetworks/cloudvision-go/device/gen"
+)
+
+// socketEnv is the environment variable with which the Collector tells
+// a plugin the path of the unix socket it should serve on.
+const socketEnv = "CLOUDVISION_DEVICE_PLUGIN_SOCKET"
+
+func optionsToProto(options map[string]device.Option) map[string]*gen.PluginOption {
+	po := make(map[string]*gen.PluginOption, len(options))
+	for k, o := range options {
+		po[k] = &gen.PluginOption{
+			Description: o.Description,
+			Default:     o.Default,
+			Pattern:     o.Pattern,
+			Required:    o.Required,
+		}
+	}
+	return po
+}
+
+func optionsFromProto(po map[string]*gen.PluginOption) map[string]device.Option {
+	options := make(map[string]device.Option, len(po))
+	for k, o := range po {
+		options[k] = device.Option{
+			Description: o.GetDescription(),
+			Default:     o.GetDefault(),
+			Pattern:     o.GetPattern(),
+			Required:    o.GetRequired(),
+		}
+	}
+	return options
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package plugin
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aristanetworks/cloudvision-go/device"
+	"github.com/aristanetworks/cloudvision-go/device/gen"
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+const testDeviceName = "pluginTest"
+
+var testOptions = map[string]device.Option{
+	"id": {
+		Description: "device ID",
+		Required:    true,
+	},
+	"alive": {
+		Description: "whether the device is alive",
+		Default:     "true",
+		Pattern:     "true|false",
+	},
+}
+
+type testProvider struct {
+	client gnmi.GNMIClient
+	id     string
+}
+
+func (p *testProvider) InitGNMI(client gnmi.GNMIClient) {
+	p.client = client
+}
+
+func (p *testProvider) OpenConfig() bool {
+	return true
+}
+
+func (p *testProvider) Run(ctx context.Context) error {
+	_, err := p.client.Set(ctx, &gnmi.SetRequest{
+		Update: []*gnmi.Update{
+			pgnmi.Update(pgnmi.Path("system", "state", "hostname"),
+				pgnmi.Strval(p.id)),
+		},
+	})
+	return err
+}
+
+type testDevice struct {
+	id    string
+	alive bool
+}
+
+func (d *testDevice) Alive() (bool, error) {
+	return d.alive, nil
+}
+
+func (d *testDevice) DeviceID() (string, error) {
+	return d.id, nil
+}
+
+func (d *testDevice) Providers() ([]provider.Provider, error) {
+	return []provider.Provider{&testProvider{id: d.id}}, nil
+}
+
+func newTestDevice(options map[string]string) (device.Device, error) {
+	return &testDevice{
+		id:    options["id"],
+		alive: options["alive"] == "true",
+	}, nil
+}
+
+// startServer serves the device registry over a unix socket and
+// returns a client connected to it.
+func startServer(t *testing.T) (gen.DevicePluginClient, func()) {
+	dir, err := ioutil.TempDir("", "plugin_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	gen.RegisterDevicePluginServer(grpcServer, newServer())
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.Dial(socket, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(dialUnix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gen.NewDevicePluginClient(conn), func() {
+		conn.Close()
+		grpcServer.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	device.Register(testDeviceName, newTestDevice, testOptions)
+	defer device.Unregister(testDeviceName)
+
+	client, stop := startServer(t)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	regs, err := describe(ctx, client)
+	if err != nil {
+		t.Fatalf("Error in describe: %v", err)
+	}
+	var reg *registration
+	for i := range regs {
+		if regs[i].name == testDeviceName {
+			reg = &regs[i]
+		}
+	}
+	if reg == nil {
+		t.Fatalf("Device %s not described by plugin: %v", testDeviceName, regs)
+	}
+	if !reflect.DeepEqual(reg.options, testOptions) {
+		t.Fatalf("Expected options %v, got %v", testOptions, reg.options)
+	}
+
+	if _, err := reg.creator(map[string]string{"alive": "false"}); err == nil {
+		t.Fatalf("Expected error creating device without required option")
+	}
+	d, err := reg.creator(map[string]string{"id": "abc123", "alive": "true"})
+	if err != nil {
+		t.Fatalf("Error creating device: %v", err)
+	}
+	did, err := d.DeviceID()
+	if err != nil {
+		t.Fatalf("Error in DeviceID: %v", err)
+	}
+	if did != "abc123" {
+		t.Fatalf("Expected device ID abc123, got %s", did)
+	}
+	alive, err := d.Alive()
+	if err != nil {
+		t.Fatalf("Error in Alive: %v", err)
+	}
+	if !alive {
+		t.Fatalf("Expected device to be alive")
+	}
+
+	providers, err := d.Providers()
+	if err != nil {
+		t.Fatalf("Error in Providers: %v", err)
+	}
+	if len(providers) != 1 {
+		t.Fatalf("Expected 1 provider, got %d", len(providers))
+	}
+	p, ok := providers[0].(provider.GNMIProvider)
+	if !ok {
+		t.Fatalf("Plugin provider is not a GNMIProvider")
+	}
+	if !p.OpenConfig() {
+		t.Fatalf("Expected OpenConfig provider")
+	}
+	var received []*gnmi.SetRequest
+	p.InitGNMI(pgnmi.NewSimpleGNMIClient(func(ctx context.Context,
+		req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+		received = append(received, req)
+		return &gnmi.SetResponse{}, nil
+	}))
+	if err := p.Run(ctx); err != nil {
+		t.Fatalf("Error running provider: %v", err)
+	}
+	if len(received) != 1 || len(received[0].Update) != 1 {
+		t.Fatalf("Expected one SetRequest with one update, got %v", received)
+	}
+	u := received[0].Update[0]
+	if expected := pgnmi.Strval("abc123"); !proto.Equal(u.Val, expected) {
+		t.Fatalf("Expected update value %v, got %v", expected, u.Val)
+	}
+
+	// Once its providers have all returned, the device is released.
+	if _, err := d.DeviceID(); err == nil {
+		t.Fatalf("Expected error from released device")
+	}
+}
+
+// buildTestPlugin builds testdata/testplugin into dir and returns its
+// path.
+func buildTestPlugin(t *testing.T, dir string) string {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available to build test plugin")
+	}
+	path := filepath.Join(dir, "testplugin")
+	cmd := exec.Command(goBin, "build", "-o", path, "./testdata/testplugin")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Error building test plugin: %v\n%s", err, out)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := buildTestPlugin(t, dir)
+
+	// Keep the plugins' socket directories under dir, since they're
+	// removed only once the plugins exit.
+	tmpdir := os.Getenv("TMPDIR")
+	os.Setenv("TMPDIR", dir)
+	defer os.Setenv("TMPDIR", tmpdir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := Load(ctx, path); err != nil {
+		t.Fatalf("Error loading plugin: %v", err)
+	}
+	defer device.Unregister("execTest")
+
+	info, err := device.NewDeviceInfo(&device.Config{
+		Device:  "execTest",
+		Options: map[string]string{"id": "abc123"},
+	})
+	if err != nil {
+		t.Fatalf("Error creating plugin device: %v", err)
+	}
+	if info.ID != "abc123" {
+		t.Fatalf("Expected device ID abc123, got %s", info.ID)
+	}
+	providers, err := info.Device.Providers()
+	if err != nil {
+		t.Fatalf("Error in Providers: %v", err)
+	}
+	if len(providers) != 1 {
+		t.Fatalf("Expected 1 provider, got %d", len(providers))
+	}
+	p := providers[0].(provider.GNMIProvider)
+	var received []*gnmi.SetRequest
+	p.InitGNMI(pgnmi.NewSimpleGNMIClient(func(ctx context.Context,
+		req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+		received = append(received, req)
+		return &gnmi.SetResponse{}, nil
+	}))
+	if err := p.Run(ctx); err != nil {
+		t.Fatalf("Error running provider: %v", err)
+	}
+	if len(received) != 1 || len(received[0].Update) != 1 ||
+		!proto.Equal(received[0].Update[0].Val, pgnmi.Strval("abc123")) {
+		t.Fatalf("Expected hostname update abc123, got %v", received)
+	}
+
+	// Loading the plugin again, this time from its directory, would
+	// replace the device it already registered.
+	if err := Load(ctx, dir); err == nil {
+		t.Fatalf("Expected error loading plugin with registered device")
+	}
+	if _, err := device.Options("execTest"); err != nil {
+		t.Fatalf("Registered device lost: %v", err)
+	}
+
+	// Cancelling the context kills the plugin and removes its socket.
+	cancel()
+	Wait()
+	socketDirs, err := filepath.Glob(filepath.Join(dir, "cloudvision-plugin*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(socketDirs) != 0 {
+		t.Fatalf("Expected plugin socket directories to be removed, got %v",
+			socketDirs)
+	}
+}
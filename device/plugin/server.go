@@ -0,0 +1,221 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aristanetworks/cloudvision-go/device"
+	"github.com/aristanetworks/cloudvision-go/device/gen"
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+// parentCheckInterval is how often a plugin checks whether the
+// Collector that started it is still running.
+const parentCheckInterval = 5 * time.Second
+
+// Serve serves the devices registered with device.Register to the
+// Collector that started this process. It should be called from a
+// plugin's main function once all its devices are registered, and
+// returns when the Collector exits or if serving fails.
+func Serve() error {
+	socket := os.Getenv(socketEnv)
+	if socket == "" {
+		return fmt.Errorf("%s not set: plugins must be started by the Collector",
+			socketEnv)
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer()
+	gen.RegisterDevicePluginServer(grpcServer, newServer())
+
+	// If the Collector dies without killing us, we get reparented.
+	ppid := os.Getppid()
+	go func() {
+		for range time.Tick(parentCheckInterval) {
+			if os.Getppid() != ppid {
+				grpcServer.Stop()
+				return
+			}
+		}
+	}()
+	return grpcServer.Serve(listener)
+}
+
+// serverDevice holds a device created at the Collector's request and,
+// once they've been requested, its providers. finished holds the
+// indexes of the providers that have run and returned; once they all
+// have, the device is released.
+type serverDevice struct {
+	device    device.Device
+	providers []provider.GNMIProvider
+	finished  map[uint32]bool
+}
+
+// server implements gen.DevicePluginServer.
+type server struct {
+	devices    map[string]*serverDevice
+	nextHandle uint64
+	lock       sync.Mutex
+}
+
+func newServer() *server {
+	return &server{devices: make(map[string]*serverDevice)}
+}
+
+func (s *server) getDevice(handle string) (*serverDevice, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	d, ok := s.devices[handle]
+	if !ok {
+		return nil, fmt.Errorf("No device with handle '%s'", handle)
+	}
+	return d, nil
+}
+
+func (s *server) Describe(ctx context.Context,
+	req *gen.DescribeRequest) (*gen.DescribeResponse, error) {
+	resp := &gen.DescribeResponse{}
+	for _, name := range device.Registered() {
+		options, err := device.Options(name)
+		if err != nil {
+			return nil, err
+		}
+		resp.Devices = append(resp.Devices, &gen.PluginDevice{
+			Name:    name,
+			Options: optionsToProto(options),
+		})
+	}
+	return resp, nil
+}
+
+func (s *server) Create(ctx context.Context,
+	req *gen.CreateRequest) (*gen.CreateResponse, error) {
+	info, err := device.NewDeviceInfo(&device.Config{
+		Device:  req.GetDeviceConfig().GetDeviceType(),
+		Options: req.GetDeviceConfig().GetOptions(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.nextHandle++
+	handle := strconv.FormatUint(s.nextHandle, 10)
+	s.devices[handle] = &serverDevice{
+		device:   info.Device,
+		finished: make(map[uint32]bool),
+	}
+	return &gen.CreateResponse{Handle: handle}, nil
+}
+
+func (s *server) Alive(ctx context.Context,
+	req *gen.AliveRequest) (*gen.AliveResponse, error) {
+	d, err := s.getDevice(req.GetHandle())
+	if err != nil {
+		return nil, err
+	}
+	alive, err := d.device.Alive()
+	if err != nil {
+		return nil, err
+	}
+	return &gen.AliveResponse{Alive: alive}, nil
+}
+
+func (s *server) DeviceID(ctx context.Context,
+	req *gen.DeviceIDRequest) (*gen.DeviceIDResponse, error) {
+	d, err := s.getDevice(req.GetHandle())
+	if err != nil {
+		return nil, err
+	}
+	did, err := d.device.DeviceID()
+	if err != nil {
+		return nil, err
+	}
+	return &gen.DeviceIDResponse{DeviceID: did}, nil
+}
+
+func (s *server) Providers(ctx context.Context,
+	req *gen.ProvidersRequest) (*gen.ProvidersResponse, error) {
+	d, err := s.getDevice(req.GetHandle())
+	if err != nil {
+		return nil, err
+	}
+	providers, err := d.device.Providers()
+	if err != nil {
+		return nil, err
+	}
+	resp := &gen.ProvidersResponse{}
+	var gnmiProviders []provider.GNMIProvider
+	for i, p := range providers {
+		pt, ok := p.(provider.GNMIProvider)
+		if !ok {
+			return nil, errors.New("unexpected provider type; need GNMIProvider")
+		}
+		gnmiProviders = append(gnmiProviders, pt)
+		resp.Providers = append(resp.Providers, &gen.PluginProvider{
+			Index:      uint32(i),
+			OpenConfig: pt.OpenConfig(),
+		})
+	}
+	s.lock.Lock()
+	d.providers = gnmiProviders
+	s.lock.Unlock()
+	return resp, nil
+}
+
+func (s *server) RunProvider(req *gen.RunProviderRequest,
+	stream gen.DevicePlugin_RunProviderServer) error {
+	d, err := s.getDevice(req.GetHandle())
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	if int(req.GetIndex()) >= len(d.providers) {
+		s.lock.Unlock()
+		return fmt.Errorf("No provider %d for device with handle '%s'",
+			req.GetIndex(), req.GetHandle())
+	}
+	p := d.providers[req.GetIndex()]
+	s.lock.Unlock()
+
+	// Providers may issue Sets from several goroutines, but a stream
+	// can only be sent on from one at a time.
+	var sendLock sync.Mutex
+	p.InitGNMI(pgnmi.NewSimpleGNMIClient(func(ctx context.Context,
+		req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+		sendLock.Lock()
+		defer sendLock.Unlock()
+		if err := stream.Send(req); err != nil {
+			return nil, err
+		}
+		return &gnmi.SetResponse{}, nil
+	}))
+	defer s.finishProvider(req.GetHandle(), d, req.GetIndex())
+	return p.Run(stream.Context())
+}
+
+// finishProvider records that one of a device's providers has
+// returned, releasing the device if all of them have.
+func (s *server) finishProvider(handle string, d *serverDevice, index uint32) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	d.finished[index] = true
+	if len(d.finished) == len(d.providers) {
+		delete(s.devices, handle)
+	}
+}
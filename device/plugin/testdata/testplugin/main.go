@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+// The testplugin command is a device plugin used by the plugin
+// package's tests. It serves a single device, execTest, whose one
+// provider sets the system hostname to the device's ID.
+package main
+
+import (
+	"context"
+
+	"github.com/aristanetworks/cloudvision-go/device"
+	"github.com/aristanetworks/cloudvision-go/device/plugin"
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sirupsen/logrus"
+)
+
+type testProvider struct {
+	client gnmi.GNMIClient
+	id     string
+}
+
+func (p *testProvider) InitGNMI(client gnmi.GNMIClient) {
+	p.client = client
+}
+
+func (p *testProvider) OpenConfig() bool {
+	return true
+}
+
+func (p *testProvider) Run(ctx context.Context) error {
+	_, err := p.client.Set(ctx, &gnmi.SetRequest{
+		Update: []*gnmi.Update{
+			pgnmi.Update(pgnmi.Path("system", "state", "hostname"),
+				pgnmi.Strval(p.id)),
+		},
+	})
+	return err
+}
+
+type testDevice struct {
+	id string
+}
+
+func (d *testDevice) Alive() (bool, error) {
+	return true, nil
+}
+
+func (d *testDevice) DeviceID() (string, error) {
+	return d.id, nil
+}
+
+func (d *testDevice) Providers() ([]provider.Provider, error) {
+	return []provider.Provider{&testProvider{id: d.id}}, nil
+}
+
+func main() {
+	device.Register("execTest", func(options map[string]string) (device.Device, error) {
+		return &testDevice{id: options["id"]}, nil
+	}, map[string]device.Option{
+		"id": {
+			Description: "device ID",
+			Required:    true,
+		},
+	})
+	if err := plugin.Serve(); err != nil {
+		logrus.Fatal(err)
+	}
+}
@@ -8,13 +8,14 @@ require (
 	github.com/aristanetworks/goarista v0.0.0-20190911185947-7be905b7e422
 	github.com/fatih/color v1.7.0
 	github.com/fsnotify/fsnotify v1.4.7 // indirect
+	github.com/go-redis/redis v6.14.1+incompatible
 	github.com/golang/protobuf v1.3.2
+	github.com/gosnmp/gosnmp v1.32.0
 	github.com/mattn/go-colorable v0.1.2 // indirect
 	github.com/mattn/go-isatty v0.0.9 // indirect
 	github.com/openconfig/gnmi v0.0.0-20190823184014-89b2bf29312c
 	github.com/pkg/errors v0.8.1
 	github.com/sirupsen/logrus v1.4.2
-	github.com/soniah/gosnmp v1.22.0
 	golang.org/x/sync v0.0.0-20190911185100-cd5d95a43a6e
 	golang.org/x/time v0.0.0-20190308202827-9d24e82272b4 // indirect
 	google.golang.org/grpc v1.23.1
@@ -7,7 +7,9 @@ package darwin
 import (
 	"context"
 	"fmt"
+	"math"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -18,100 +20,302 @@ import (
 	"github.com/openconfig/gnmi/proto/gnmi"
 )
 
+// ChassisName is the name of the chassis component.
+const ChassisName = "chassis"
+
+// A Runner runs the named command with the specified arguments and
+// returns its standard output.
+type Runner func(name string, args ...string) ([]byte, error)
+
+// RunCommand is a Runner that executes commands on the local host.
+func RunCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
 type darwin struct {
 	client       gnmi.GNMIClient
 	errc         chan error
 	pollInterval time.Duration
+	run          Runner
+}
+
+func output(run Runner, name string, args ...string) (string, error) {
+	out, err := run(name, args...)
+	if err != nil {
+		return "", fmt.Errorf("Error running %s: %v", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
 }
 
 // Return a set of gNMI updates for in/out bytes, packets, and errors
-// for a given interface, as reported by netstat.
+// for a given interface, as reported by netstat. The Address column
+// is empty for interfaces without a link-layer address, so the
+// counters are found relative to the end of the line.
 func updatesFromNetstatLine(fields []string) ([]*gnmi.Update, error) {
-	intfName := fields[0]
-	inBytes, err := strconv.ParseUint(fields[6], 10, 64)
+	if len(fields) < 10 {
+		return nil, fmt.Errorf("Unexpected netstat line: %v", fields)
+	}
+	intfName := strings.TrimSuffix(fields[0], "*")
+	counters := fields[len(fields)-7:]
+	var updates []*gnmi.Update
+	for i, leaf := range []string{"in-unicast-pkts", "in-errors", "in-octets",
+		"out-unicast-pkts", "out-errors", "out-octets"} {
+		v, err := strconv.ParseUint(counters[i], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, pgnmi.Update(pgnmi.IntfStateCountersPath(intfName,
+			leaf), pgnmi.Uintval(v)))
+	}
+	return updates, nil
+}
+
+// netstatCounters returns counter updates, by interface, from the
+// link-level lines of netstat output.
+func netstatCounters(out string) (map[string][]*gnmi.Update, error) {
+	counters := make(map[string][]*gnmi.Update)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasPrefix(fields[2], "<Link#") {
+			continue
+		}
+		intfName := strings.TrimSuffix(fields[0], "*")
+		if _, ok := counters[intfName]; ok {
+			continue
+		}
+		u, err := updatesFromNetstatLine(fields)
+		if err != nil {
+			return nil, err
+		}
+		counters[intfName] = u
+	}
+	return counters, nil
+}
+
+type ifconfigIntf struct {
+	name   string
+	flags  map[string]bool
+	mtu    uint64
+	mac    string
+	status string
+}
+
+var ifconfigHeader = regexp.MustCompile(`^(\S+): flags=[0-9a-f]+<([^>]*)> mtu (\d+)`)
+
+// parseIfconfig returns the interfaces in ifconfig output, in the
+// order listed.
+func parseIfconfig(out string) ([]*ifconfigIntf, error) {
+	var intfs []*ifconfigIntf
+	var cur *ifconfigIntf
+	for _, line := range strings.Split(out, "\n") {
+		if m := ifconfigHeader.FindStringSubmatch(line); m != nil {
+			mtu, err := strconv.ParseUint(m[3], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			cur = &ifconfigIntf{name: m[1], flags: make(map[string]bool), mtu: mtu}
+			for _, f := range strings.Split(m[2], ",") {
+				cur.flags[f] = true
+			}
+			intfs = append(intfs, cur)
+			continue
+		}
+		fields := strings.Fields(line)
+		if cur == nil || len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "ether":
+			cur.mac = fields[1]
+		case "status:":
+			cur.status = fields[1]
+		}
+	}
+	return intfs, nil
+}
+
+func (i *ifconfigIntf) updates() []*gnmi.Update {
+	intfType := openconfig.InterfaceType(1) // other
+	if i.flags["LOOPBACK"] {
+		intfType = openconfig.InterfaceType(24) // softwareLoopback
+	} else if i.mac != "" {
+		intfType = openconfig.InterfaceType(6) // ethernetCsmacd
+	}
+	adminStatus := openconfig.IntfAdminStatus(2)
+	if i.flags["UP"] {
+		adminStatus = openconfig.IntfAdminStatus(1)
+	}
+	// Interfaces with media report their link status; for others,
+	// fall back to the RUNNING flag.
+	operStatus := openconfig.IntfOperStatus(2)
+	if i.status == "active" || (i.status == "" && i.flags["RUNNING"]) {
+		operStatus = openconfig.IntfOperStatus(1)
+	}
+	mtu := i.mtu
+	if mtu > math.MaxUint16 {
+		mtu = math.MaxUint16
+	}
+
+	updates := []*gnmi.Update{
+		pgnmi.Update(pgnmi.IntfPath(i.name, "name"), pgnmi.Strval(i.name)),
+		pgnmi.Update(pgnmi.IntfConfigPath(i.name, "name"), pgnmi.Strval(i.name)),
+		pgnmi.Update(pgnmi.IntfStatePath(i.name, "name"), pgnmi.Strval(i.name)),
+		pgnmi.Update(pgnmi.IntfStatePath(i.name, "type"),
+			pgnmi.Strval("iana-if-type:"+intfType)),
+		pgnmi.Update(pgnmi.IntfStatePath(i.name, "mtu"), pgnmi.Uintval(mtu)),
+		pgnmi.Update(pgnmi.IntfStatePath(i.name, "admin-status"),
+			pgnmi.Strval(adminStatus)),
+		pgnmi.Update(pgnmi.IntfStatePath(i.name, "oper-status"),
+			pgnmi.Strval(operStatus)),
+	}
+	if i.mac != "" {
+		updates = append(updates, pgnmi.Update(pgnmi.Path("interfaces",
+			pgnmi.ListWithKey("interface", "name", i.name), "ethernet", "state",
+			"mac-address"), pgnmi.Strval(i.mac)))
+	}
+	return updates
+}
+
+// InterfaceUpdates returns updates for each interface listed by
+// ifconfig, with counters from netstat.
+func InterfaceUpdates(run Runner) ([]*gnmi.Update, error) {
+	out, err := output(run, "ifconfig", "-a")
 	if err != nil {
 		return nil, err
 	}
-	inPkts, err := strconv.ParseUint(fields[4], 10, 64)
+	intfs, err := parseIfconfig(out)
 	if err != nil {
 		return nil, err
 	}
-	inErrs, err := strconv.ParseUint(fields[5], 10, 64)
+	out, err = output(run, "netstat", "-ibn")
 	if err != nil {
 		return nil, err
 	}
-	outBytes, err := strconv.ParseUint(fields[9], 10, 64)
+	counters, err := netstatCounters(out)
 	if err != nil {
 		return nil, err
 	}
-	outPkts, err := strconv.ParseUint(fields[7], 10, 64)
+
+	var updates []*gnmi.Update
+	for _, intf := range intfs {
+		updates = append(updates, intf.updates()...)
+		updates = append(updates, counters[intf.name]...)
+	}
+	return updates, nil
+}
+
+var bootTimeRegexp = regexp.MustCompile(`sec = (\d+)`)
+
+// SystemUpdates returns updates for the system hostname, domain name,
+// and boot time.
+func SystemUpdates(run Runner) ([]*gnmi.Update, error) {
+	hostname, err := output(run, "sysctl", "-n", "kern.hostname")
 	if err != nil {
 		return nil, err
 	}
-	outErrs, err := strconv.ParseUint(fields[8], 10, 64)
+	updates := pgnmi.HostnameUpdates(hostname)
+
+	// kern.boottime looks like "{ sec = 1594812345, usec = 123456 } ...".
+	bootTime, err := output(run, "sysctl", "-n", "kern.boottime")
 	if err != nil {
 		return nil, err
 	}
-
-	return []*gnmi.Update{
-		pgnmi.Update(pgnmi.IntfStatePath(intfName, "name"),
-			pgnmi.Strval(intfName)),
-		pgnmi.Update(pgnmi.IntfPath(intfName, "name"),
-			pgnmi.Strval(intfName)),
-		pgnmi.Update(pgnmi.IntfConfigPath(intfName, "name"),
-			pgnmi.Strval(intfName)),
-		pgnmi.Update(pgnmi.IntfStatePath(intfName, "type"),
-			pgnmi.Strval(openconfig.InterfaceType(6))),
-		pgnmi.Update(pgnmi.IntfStatePath(intfName, "admin-status"),
-			pgnmi.Strval(openconfig.IntfAdminStatus(1))),
-		pgnmi.Update(pgnmi.IntfStatePath(intfName, "oper-status"),
-			pgnmi.Strval(openconfig.IntfOperStatus(1))),
-		pgnmi.Update(pgnmi.IntfStateCountersPath(intfName, "in-octets"),
-			pgnmi.Uintval(inBytes)),
-		pgnmi.Update(pgnmi.IntfStateCountersPath(intfName, "in-unicast-pkts"),
-			pgnmi.Uintval(inPkts)),
-		pgnmi.Update(pgnmi.IntfStateCountersPath(intfName, "in-errors"),
-			pgnmi.Uintval(inErrs)),
-		pgnmi.Update(pgnmi.IntfStateCountersPath(intfName, "out-octets"),
-			pgnmi.Uintval(outBytes)),
-		pgnmi.Update(pgnmi.IntfStateCountersPath(intfName, "out-unicast-pkts"),
-			pgnmi.Uintval(outPkts)),
-		pgnmi.Update(pgnmi.IntfStateCountersPath(intfName, "out-errors"),
-			pgnmi.Uintval(outErrs)),
-	}, nil
-}
-
-func (d *darwin) updateInterfaces() ([]*gnmi.SetRequest, error) {
-	ns := exec.Command("netstat", "-ibn")
-	out, err := ns.CombinedOutput()
+	m := bootTimeRegexp.FindStringSubmatch(bootTime)
+	if m == nil {
+		return nil, fmt.Errorf("Unexpected kern.boottime: '%s'", bootTime)
+	}
+	secs, err := strconv.ParseInt(m[1], 10, 64)
 	if err != nil {
 		return nil, err
 	}
+	return append(updates, pgnmi.Update(pgnmi.Path("system", "state", "boot-time"),
+		pgnmi.Intval(secs))), nil
+}
 
-	setRequest := new(gnmi.SetRequest)
-	updates := make([]*gnmi.Update, 0)
-	interfaceList := make(map[string]bool)
+// serialNumber returns the hardware serial number reported by
+// system_profiler.
+func serialNumber(run Runner) (string, error) {
+	out, err := output(run, "system_profiler", "SPHardwareDataType")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "Serial Number") {
+			continue
+		}
+		if i := strings.Index(line, ": "); i >= 0 {
+			return strings.TrimSpace(line[i+2:]), nil
+		}
+	}
+	return "", fmt.Errorf("No serial number in system_profiler output")
+}
 
-	// Iterate over lines in output, selecting only ethernet interfaces
-	// to stream out updates for.
-	for _, line := range strings.Split(string(out), "\n") {
-		fields := strings.Fields(line)
-		if len(fields) > 0 && strings.HasPrefix(fields[0], "en") {
-			intfName := fields[0]
-			if _, ok := interfaceList[intfName]; ok {
-				continue
-			}
-			interfaceList[intfName] = true
-			u, err := updatesFromNetstatLine(fields)
-			if err != nil {
-				return nil, err
-			}
-			updates = append(updates, u...)
+// ComponentUpdates returns updates for the chassis component, with
+// the hardware model and serial number and the macOS version.
+func ComponentUpdates(run Runner) ([]*gnmi.Update, error) {
+	model, err := output(run, "sysctl", "-n", "hw.model")
+	if err != nil {
+		return nil, err
+	}
+	serial, err := serialNumber(run)
+	if err != nil {
+		return nil, err
+	}
+	// The OS version is nice to have but not worth failing the poll
+	// over.
+	version, _ := output(run, "sw_vers", "-productVersion")
+
+	state := func(leaf string) *gnmi.Path {
+		return pgnmi.PlatformComponentStatePath(ChassisName, leaf)
+	}
+	updates := []*gnmi.Update{
+		pgnmi.Update(pgnmi.PlatformComponentPath(ChassisName, "name"),
+			pgnmi.Strval(ChassisName)),
+		pgnmi.Update(pgnmi.PlatformComponentConfigPath(ChassisName, "name"),
+			pgnmi.Strval(ChassisName)),
+		pgnmi.Update(state("name"), pgnmi.Strval(ChassisName)),
+		pgnmi.Update(state("type"),
+			pgnmi.Strval("openconfig-platform-types:CHASSIS")),
+		pgnmi.Update(state("mfg-name"), pgnmi.Strval("Apple Inc.")),
+	}
+	for _, leaf := range []struct {
+		name, value string
+	}{
+		{"serial-no", serial},
+		{"description", model},
+		{"software-version", version},
+	} {
+		if leaf.value != "" {
+			updates = append(updates, pgnmi.Update(state(leaf.name),
+				pgnmi.Strval(leaf.value)))
 		}
 	}
+	return updates, nil
+}
+
+// DeviceID returns the host's serial number.
+func DeviceID(run Runner) (string, error) {
+	return serialNumber(run)
+}
 
-	setRequest.Delete = []*gnmi.Path{pgnmi.Path("interfaces")}
-	setRequest.Replace = updates
+func (d *darwin) poll() ([]*gnmi.SetRequest, error) {
+	setRequest := &gnmi.SetRequest{
+		Delete: []*gnmi.Path{
+			pgnmi.Path("interfaces"),
+			pgnmi.Path("system"),
+			pgnmi.Path("components"),
+		},
+	}
+	for _, fn := range []func(Runner) ([]*gnmi.Update, error){
+		InterfaceUpdates, SystemUpdates, ComponentUpdates,
+	} {
+		updates, err := fn(d.run)
+		if err != nil {
+			return nil, err
+		}
+		setRequest.Replace = append(setRequest.Replace, updates...)
+	}
 	return []*gnmi.SetRequest{setRequest}, nil
 }
 
@@ -127,13 +331,11 @@ func (d *darwin) handleErrors(ctx context.Context) error {
 }
 
 func (d *darwin) Run(ctx context.Context) error {
-	// Run updateInterfaces at the specified polling interval,
-	// forever. PollForever sends the updates produced by
-	// updateInterfaces to the gNMI client and sends any
-	// resulting errors to the error channel to be handled by
-	// handleErrors.
-	go pgnmi.PollForever(ctx, d.client, d.pollInterval,
-		d.updateInterfaces, d.errc)
+	// Run poll at the specified polling interval, forever.
+	// PollForever sends the updates produced by poll to the gNMI
+	// client and sends any resulting errors to the error channel to
+	// be handled by handleErrors.
+	go pgnmi.PollForever(ctx, d.client, d.pollInterval, d.poll, d.errc)
 
 	// handleErrors only returns if it sees an error.
 	return d.handleErrors(ctx)
@@ -147,11 +349,16 @@ func (d *darwin) OpenConfig() bool {
 	return true
 }
 
-// NewDarwinProvider returns a darwin provider that registers a
-// Darwin device and streams interface statistics.
-func NewDarwinProvider(pollInterval time.Duration) provider.GNMIProvider {
+// NewDarwinProvider returns a darwin provider that streams interface,
+// system, and platform data gathered by running commands with the
+// specified Runner, which is RunCommand if nil.
+func NewDarwinProvider(pollInterval time.Duration, run Runner) provider.GNMIProvider {
+	if run == nil {
+		run = RunCommand
+	}
 	return &darwin{
 		errc:         make(chan error),
 		pollInterval: pollInterval,
+		run:          run,
 	}
 }
@@ -0,0 +1,235 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package darwin
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+const ifconfigOutput = `lo0: flags=8049<UP,LOOPBACK,RUNNING,MULTICAST> mtu 16384
+	options=1203<RXCSUM,TXCSUM,TXSTATUS,SW_TIMESTAMP>
+	inet 127.0.0.1 netmask 0xff000000
+	nd6 options=201<PERFORMNUD,DAD>
+en0: flags=8863<UP,BROADCAST,SMART,RUNNING,SIMPLEX,MULTICAST> mtu 1500
+	options=400<CHANNEL_IO>
+	ether 3c:22:fb:00:00:01
+	inet 192.168.1.10 netmask 0xffffff00 broadcast 192.168.1.255
+	media: autoselect
+	status: active
+en1: flags=8822<BROADCAST,SMART,SIMPLEX,MULTICAST> mtu 1500
+	ether 3c:22:fb:00:00:02
+	media: autoselect (<unknown type>)
+	status: inactive
+`
+
+// netstatOutput is netstat -ibn output, with the header wrapped to
+// fit.
+const netstatOutput = `Name  Mtu   Network       Address            Ipkts Ierrs     Ibytes ` +
+	`   Opkts Oerrs     Obytes  Coll
+lo0   16384 <Link#1>                         1000     0     200000     1000     0     200000     0
+lo0   16384 127           127.0.0.1          1000     -     200000     1000     -     200000     -
+en0   1500  <Link#5>    3c:22:fb:00:00:01 54321     2   98765432    43210     4   12345678     0
+en0   1500  192.168.1     192.168.1.10      54000     -   98000000    43000     -   12000000     -
+en1*  1500  <Link#6>    3c:22:fb:00:00:02     0     0          0        0     0          0     0
+`
+
+const systemProfilerOutput = `Hardware:
+
+    Hardware Overview:
+
+      Model Name: MacBook Pro
+      Model Identifier: MacBookPro15,1
+      Serial Number (system): C02XK0AAJG5J
+      Hardware UUID: 00000000-0000-0000-0000-000000000000
+`
+
+var commandOutputs = map[string]string{
+	"ifconfig -a":                        ifconfigOutput,
+	"netstat -ibn":                       netstatOutput,
+	"sysctl -n kern.hostname":            "mac1.example.com\n",
+	"sysctl -n kern.boottime":            "{ sec = 1594812345, usec = 123456 } Wed Jul 15\n",
+	"sysctl -n hw.model":                 "MacBookPro15,1\n",
+	"system_profiler SPHardwareDataType": systemProfilerOutput,
+	"sw_vers -productVersion":            "10.15.5\n",
+}
+
+// fakeRunner returns the captured output of a command.
+func fakeRunner(name string, args ...string) ([]byte, error) {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	out, ok := commandOutputs[cmd]
+	if !ok {
+		return nil, fmt.Errorf("unexpected command: %s", cmd)
+	}
+	return []byte(out), nil
+}
+
+func counterUpdates(name string, inPkts, inErrs, inOctets, outPkts, outErrs,
+	outOctets uint64) []*gnmi.Update {
+	return []*gnmi.Update{
+		pgnmi.Update(pgnmi.IntfStateCountersPath(name, "in-unicast-pkts"), pgnmi.Uintval(inPkts)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath(name, "in-errors"), pgnmi.Uintval(inErrs)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath(name, "in-octets"), pgnmi.Uintval(inOctets)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath(name, "out-unicast-pkts"),
+			pgnmi.Uintval(outPkts)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath(name, "out-errors"), pgnmi.Uintval(outErrs)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath(name, "out-octets"), pgnmi.Uintval(outOctets)),
+	}
+}
+
+func TestUpdatesFromNetstatLine(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		line     string
+		expected []*gnmi.Update
+		err      bool
+	}{
+		{
+			name:     "with address",
+			line:     "en0 1500 <Link#5> 3c:22:fb:00:00:01 54321 2 98765432 43210 4 12345678 0",
+			expected: counterUpdates("en0", 54321, 2, 98765432, 43210, 4, 12345678),
+		},
+		{
+			name:     "without address",
+			line:     "lo0 16384 <Link#1> 1000 0 200000 1000 0 200000 0",
+			expected: counterUpdates("lo0", 1000, 0, 200000, 1000, 0, 200000),
+		},
+		{
+			name:     "down interface",
+			line:     "en1* 1500 <Link#6> 3c:22:fb:00:00:02 0 0 0 0 0 0 0",
+			expected: counterUpdates("en1", 0, 0, 0, 0, 0, 0),
+		},
+		{
+			name: "bad counter",
+			line: "en0 1500 <Link#5> 3c:22:fb:00:00:01 54321 - 98765432 43210 4 12345678 0",
+			err:  true,
+		},
+		{
+			name: "short line",
+			line: "en0 1500 <Link#5>",
+			err:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			updates, err := updatesFromNetstatLine(strings.Fields(tc.line))
+			if tc.err {
+				if err == nil {
+					t.Fatal("Expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(updates, tc.expected) {
+				t.Fatalf("Expected updates %v, got %v", tc.expected, updates)
+			}
+		})
+	}
+}
+
+func ethernetStatePath(name, leaf string) *gnmi.Path {
+	return pgnmi.Path("interfaces", pgnmi.ListWithKey("interface", "name", name),
+		"ethernet", "state", leaf)
+}
+
+func TestInterfaceUpdates(t *testing.T) {
+	updates, err := InterfaceUpdates(fakeRunner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected []*gnmi.Update
+	expected = append(expected,
+		pgnmi.Update(pgnmi.IntfPath("lo0", "name"), pgnmi.Strval("lo0")),
+		pgnmi.Update(pgnmi.IntfConfigPath("lo0", "name"), pgnmi.Strval("lo0")),
+		pgnmi.Update(pgnmi.IntfStatePath("lo0", "name"), pgnmi.Strval("lo0")),
+		pgnmi.Update(pgnmi.IntfStatePath("lo0", "type"),
+			pgnmi.Strval("iana-if-type:softwareLoopback")),
+		pgnmi.Update(pgnmi.IntfStatePath("lo0", "mtu"), pgnmi.Uintval(16384)),
+		pgnmi.Update(pgnmi.IntfStatePath("lo0", "admin-status"), pgnmi.Strval("UP")),
+		pgnmi.Update(pgnmi.IntfStatePath("lo0", "oper-status"), pgnmi.Strval("UP")))
+	expected = append(expected, counterUpdates("lo0", 1000, 0, 200000, 1000, 0, 200000)...)
+	expected = append(expected,
+		pgnmi.Update(pgnmi.IntfPath("en0", "name"), pgnmi.Strval("en0")),
+		pgnmi.Update(pgnmi.IntfConfigPath("en0", "name"), pgnmi.Strval("en0")),
+		pgnmi.Update(pgnmi.IntfStatePath("en0", "name"), pgnmi.Strval("en0")),
+		pgnmi.Update(pgnmi.IntfStatePath("en0", "type"),
+			pgnmi.Strval("iana-if-type:ethernetCsmacd")),
+		pgnmi.Update(pgnmi.IntfStatePath("en0", "mtu"), pgnmi.Uintval(1500)),
+		pgnmi.Update(pgnmi.IntfStatePath("en0", "admin-status"), pgnmi.Strval("UP")),
+		pgnmi.Update(pgnmi.IntfStatePath("en0", "oper-status"), pgnmi.Strval("UP")),
+		pgnmi.Update(ethernetStatePath("en0", "mac-address"), pgnmi.Strval("3c:22:fb:00:00:01")))
+	expected = append(expected,
+		counterUpdates("en0", 54321, 2, 98765432, 43210, 4, 12345678)...)
+	expected = append(expected,
+		pgnmi.Update(pgnmi.IntfPath("en1", "name"), pgnmi.Strval("en1")),
+		pgnmi.Update(pgnmi.IntfConfigPath("en1", "name"), pgnmi.Strval("en1")),
+		pgnmi.Update(pgnmi.IntfStatePath("en1", "name"), pgnmi.Strval("en1")),
+		pgnmi.Update(pgnmi.IntfStatePath("en1", "type"),
+			pgnmi.Strval("iana-if-type:ethernetCsmacd")),
+		pgnmi.Update(pgnmi.IntfStatePath("en1", "mtu"), pgnmi.Uintval(1500)),
+		pgnmi.Update(pgnmi.IntfStatePath("en1", "admin-status"), pgnmi.Strval("DOWN")),
+		pgnmi.Update(pgnmi.IntfStatePath("en1", "oper-status"), pgnmi.Strval("DOWN")),
+		pgnmi.Update(ethernetStatePath("en1", "mac-address"), pgnmi.Strval("3c:22:fb:00:00:02")))
+	expected = append(expected, counterUpdates("en1", 0, 0, 0, 0, 0, 0)...)
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestSystemUpdates(t *testing.T) {
+	updates, err := SystemUpdates(fakeRunner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*gnmi.Update{
+		pgnmi.Update(pgnmi.Path("system", "state", "hostname"), pgnmi.Strval("mac1")),
+		pgnmi.Update(pgnmi.Path("system", "state", "domain-name"),
+			pgnmi.Strval("example.com")),
+		pgnmi.Update(pgnmi.Path("system", "state", "boot-time"), pgnmi.Intval(1594812345)),
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestComponentUpdates(t *testing.T) {
+	updates, err := ComponentUpdates(fakeRunner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := func(leaf string) *gnmi.Path {
+		return pgnmi.PlatformComponentStatePath("chassis", leaf)
+	}
+	expected := []*gnmi.Update{
+		pgnmi.Update(pgnmi.PlatformComponentPath("chassis", "name"), pgnmi.Strval("chassis")),
+		pgnmi.Update(pgnmi.PlatformComponentConfigPath("chassis", "name"),
+			pgnmi.Strval("chassis")),
+		pgnmi.Update(state("name"), pgnmi.Strval("chassis")),
+		pgnmi.Update(state("type"), pgnmi.Strval("openconfig-platform-types:CHASSIS")),
+		pgnmi.Update(state("mfg-name"), pgnmi.Strval("Apple Inc.")),
+		pgnmi.Update(state("serial-no"), pgnmi.Strval("C02XK0AAJG5J")),
+		pgnmi.Update(state("description"), pgnmi.Strval("MacBookPro15,1")),
+		pgnmi.Update(state("software-version"), pgnmi.Strval("10.15.5")),
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestDeviceID(t *testing.T) {
+	did, err := DeviceID(fakeRunner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if did != "C02XK0AAJG5J" {
+		t.Fatalf("Expected device ID C02XK0AAJG5J, got %s", did)
+	}
+}
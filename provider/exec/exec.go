@@ -0,0 +1,355 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	osexec "os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aristanetworks/cloudvision-go/log"
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	agnmi "github.com/aristanetworks/goarista/gnmi"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Supported output formats.
+const (
+	// FormatJSONLines is one JSON object per line, each with a "path",
+	// a "value", and optionally an "op" ("update", "replace", or
+	// "delete"; default "update"). If the value is an object, each of
+	// its leaves is updated under the path, or for "replace", the
+	// object replaces the whole subtree at the path.
+	FormatJSONLines = "jsonl"
+	// FormatGNMI is one JSON-encoded gnmi.Notification per line.
+	FormatGNMI = "gnmi"
+)
+
+// Shell is the shell used to run commands.
+var Shell = "/bin/sh"
+
+// Command returns an exec.Cmd that runs the specified command line
+// in Shell.
+func Command(ctx context.Context, command string) *osexec.Cmd {
+	return osexec.CommandContext(ctx, Shell, "-c", command)
+}
+
+type execProvider struct {
+	client       gnmi.GNMIClient
+	errc         chan error
+	command      string
+	format       string
+	pollInterval time.Duration
+	timeout      time.Duration
+	longRunning  bool
+	openConfig   bool
+}
+
+// jsonLine is a single line of FormatJSONLines output.
+type jsonLine struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// parsePath is like pgnmi.PathFromString but returns an error rather
+// than panicking on a malformed path, since the path comes from
+// outside.
+func parsePath(path string) (*gnmi.Path, error) {
+	p, err := agnmi.ParseGNMIElements(agnmi.SplitPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid path '%s': %v", path, err)
+	}
+	p.Element = nil
+	return p, nil
+}
+
+// leafUpdates returns updates for the JSON value v at the specified
+// path, descending into objects.
+func leafUpdates(path string, v interface{}) ([]*gnmi.Update, error) {
+	var val *gnmi.TypedValue
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var updates []*gnmi.Update
+		for _, k := range keys {
+			u, err := leafUpdates(strings.TrimSuffix(path, "/")+"/"+k, t[k])
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, u...)
+		}
+		return updates, nil
+	case string:
+		val = pgnmi.Strval(t)
+	case bool:
+		val = pgnmi.Boolval(t)
+	case json.Number:
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			val = pgnmi.Uintval(u)
+		} else if i, err := strconv.ParseInt(t.String(), 10, 64); err == nil {
+			val = pgnmi.Intval(i)
+		} else if f, err := t.Float64(); err == nil {
+			val = pgnmi.Floatval(f)
+		} else {
+			return nil, fmt.Errorf("Invalid number %s at path '%s'", t, path)
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported value %v at path '%s'", v, path)
+	}
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return []*gnmi.Update{pgnmi.Update(p, val)}, nil
+}
+
+// addJSONLine adds the contents of a FormatJSONLines line to a
+// SetRequest.
+func addJSONLine(setreq *gnmi.SetRequest, line []byte) error {
+	var jl jsonLine
+	if err := json.Unmarshal(line, &jl); err != nil {
+		return fmt.Errorf("Invalid JSON line '%s': %v", line, err)
+	}
+	if jl.Op == "delete" {
+		p, err := parsePath(jl.Path)
+		if err != nil {
+			return err
+		}
+		setreq.Delete = append(setreq.Delete, p)
+		return nil
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(jl.Value))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return fmt.Errorf("Invalid value in line '%s': %v", line, err)
+	}
+	updates, err := leafUpdates(jl.Path, v)
+	if err != nil {
+		return err
+	}
+	switch jl.Op {
+	case "", "update":
+		setreq.Update = append(setreq.Update, updates...)
+	case "replace":
+		if _, ok := v.(map[string]interface{}); !ok {
+			setreq.Replace = append(setreq.Replace, updates...)
+			return nil
+		}
+		// Replacing each of an object's leaves would leave any
+		// others in its subtree, so replace the object as a whole.
+		p, err := parsePath(jl.Path)
+		if err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		if err := json.Compact(&b, jl.Value); err != nil {
+			return fmt.Errorf("Invalid value in line '%s': %v", line, err)
+		}
+		setreq.Replace = append(setreq.Replace, pgnmi.Update(p,
+			&gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: b.Bytes()}}))
+	default:
+		return fmt.Errorf("Invalid op '%s' in line '%s'", jl.Op, line)
+	}
+	return nil
+}
+
+// notificationSetRequest returns a SetRequest for a FormatGNMI line.
+func notificationSetRequest(line []byte) (*gnmi.SetRequest, error) {
+	var n gnmi.Notification
+	if err := jsonpb.Unmarshal(bytes.NewReader(line), &n); err != nil {
+		return nil, fmt.Errorf("Invalid gNMI notification '%s': %v", line, err)
+	}
+	return &gnmi.SetRequest{
+		Prefix: n.Prefix,
+		Delete: n.Delete,
+		Update: n.Update,
+	}, nil
+}
+
+// SetRequests converts the specified command output to SetRequests.
+// For FormatJSONLines all lines are gathered into a single SetRequest;
+// for FormatGNMI there is one SetRequest per notification.
+func SetRequests(format string, out []byte) ([]*gnmi.SetRequest, error) {
+	return setRequests(format, out, nil)
+}
+
+// setRequests is SetRequests, except that if skip is non-nil, the
+// error for each line that can't be parsed is passed to it and the
+// line is skipped.
+func setRequests(format string, out []byte,
+	skip func(error)) ([]*gnmi.SetRequest, error) {
+	var setreqs []*gnmi.SetRequest
+	jsonreq := &gnmi.SetRequest{}
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var err error
+		switch format {
+		case FormatJSONLines:
+			err = addJSONLine(jsonreq, line)
+		case FormatGNMI:
+			var setreq *gnmi.SetRequest
+			if setreq, err = notificationSetRequest(line); err == nil {
+				setreqs = append(setreqs, setreq)
+			}
+		default:
+			return nil, fmt.Errorf("Unknown format '%s'", format)
+		}
+		if err != nil {
+			if skip == nil {
+				return nil, err
+			}
+			skip(err)
+		}
+	}
+	if len(jsonreq.Delete) > 0 || len(jsonreq.Replace) > 0 || len(jsonreq.Update) > 0 {
+		setreqs = append(setreqs, jsonreq)
+	}
+	return setreqs, nil
+}
+
+// poll runs the command once, within the provider's timeout, and
+// returns the updates in its output. Lines that can't be parsed are
+// logged and skipped.
+func (e *execProvider) poll(ctx context.Context) ([]*gnmi.SetRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	out, err := Command(ctx, e.command).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Error running '%s': %v", e.command, err)
+	}
+	return setRequests(e.format, out, e.skip)
+}
+
+// skip logs a line of output that can't be parsed.
+func (e *execProvider) skip(err error) {
+	log.Log(e).Infof("Skipping output of '%s': %v", e.command, err)
+}
+
+// stream runs the command once, sending a set of updates for each line
+// it outputs, until it exits. Lines that can't be parsed are logged and
+// skipped.
+func (e *execProvider) stream(ctx context.Context) error {
+	cmd := Command(ctx, e.command)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// If we stop reading before the command exits, for instance
+	// because an update can't be sent, it could block forever
+	// writing to a full pipe, so kill it.
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	r := bufio.NewReader(stdout)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		setreqs, perr := setRequests(e.format, line, e.skip)
+		if perr != nil {
+			return perr
+		}
+		for _, setreq := range setreqs {
+			if _, err := e.client.Set(ctx, setreq); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+func (e *execProvider) runLongRunning(ctx context.Context) error {
+	for {
+		err := e.stream(ctx)
+		if ctx.Err() != nil {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("Error in exec provider: %v", err)
+		}
+		// If the command exits, wait a polling interval and
+		// start it again.
+		log.Log(e).Infof("Command '%s' exited", e.command)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(e.pollInterval):
+		}
+	}
+}
+
+func (e *execProvider) handleErrors(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-e.errc:
+			return fmt.Errorf("Error in exec provider: %v", err)
+		}
+	}
+}
+
+func (e *execProvider) Run(ctx context.Context) error {
+	if e.longRunning {
+		return e.runLongRunning(ctx)
+	}
+	go pgnmi.PollForever(ctx, e.client, e.pollInterval,
+		func() ([]*gnmi.SetRequest, error) { return e.poll(ctx) }, e.errc)
+	return e.handleErrors(ctx)
+}
+
+func (e *execProvider) InitGNMI(client gnmi.GNMIClient) {
+	e.client = client
+}
+
+func (e *execProvider) OpenConfig() bool {
+	return e.openConfig
+}
+
+// NewExecProvider returns a provider that runs the specified command
+// and converts its output, in the specified format, to gNMI updates.
+// If longRunning is false, the command is run every pollInterval, and
+// each run's output is sent as a whole; a run that takes longer than
+// timeout is killed. Otherwise the command is expected to keep running,
+// and each line of output is sent as soon as it's read; if the command
+// exits it is restarted after pollInterval.
+func NewExecProvider(command, format string, pollInterval, timeout time.Duration,
+	longRunning, openConfig bool) provider.GNMIProvider {
+	return &execProvider{
+		errc:         make(chan error),
+		command:      command,
+		format:       format,
+		pollInterval: pollInterval,
+		timeout:      timeout,
+		longRunning:  longRunning,
+		openConfig:   openConfig,
+	}
+}
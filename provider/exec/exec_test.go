@@ -0,0 +1,238 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package exec
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func checkSetRequests(t *testing.T, got, expected []*gnmi.SetRequest) {
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d SetRequests, got %d: %v", len(expected), len(got), got)
+	}
+	for i := range got {
+		if !proto.Equal(got[i], expected[i]) {
+			t.Fatalf("SetRequest %d: expected %v, got %v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestSetRequests(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		format   string
+		out      string
+		expected []*gnmi.SetRequest
+		err      error
+	}{
+		{
+			name:   "jsonl leaves",
+			format: FormatJSONLines,
+			out: `{"path": "/system/state/hostname", "value": "dev1"}
+{"path": "/interfaces/interface[name=eth0]/state/mtu", "value": 1500}
+
+{"path": "/a/b", "value": -3}
+{"path": "/a/c", "value": true, "op": "update"}
+{"path": "/a/d", "value": 2.5}
+`,
+			expected: []*gnmi.SetRequest{
+				{
+					Update: []*gnmi.Update{
+						pgnmi.Update(pgnmi.Path("system", "state", "hostname"),
+							pgnmi.Strval("dev1")),
+						pgnmi.Update(pgnmi.IntfStatePath("eth0", "mtu"),
+							pgnmi.Uintval(1500)),
+						pgnmi.Update(pgnmi.Path("a", "b"), pgnmi.Intval(-3)),
+						pgnmi.Update(pgnmi.Path("a", "c"), pgnmi.Boolval(true)),
+						pgnmi.Update(pgnmi.Path("a", "d"), pgnmi.Floatval(2.5)),
+					},
+				},
+			},
+		},
+		{
+			name:   "jsonl object, replace, and delete",
+			format: FormatJSONLines,
+			out: `{"op": "delete", "path": "/interfaces"}
+{"op": "replace", "path": "/interfaces/interface[name=eth0]/state",` +
+				` "value": {"name": "eth0", "counters": {"in-octets": 10}}}
+{"op": "replace", "path": "/interfaces/interface[name=eth0]/state/mtu",` +
+				` "value": 1500}
+{"path": "/interfaces/interface[name=eth1]/state",` +
+				` "value": {"name": "eth1", "counters": {"in-octets": 20}}}`,
+			expected: []*gnmi.SetRequest{
+				{
+					Delete: []*gnmi.Path{pgnmi.Path("interfaces")},
+					Replace: []*gnmi.Update{
+						pgnmi.Update(pgnmi.IntfPath("eth0", "state"),
+							&gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{
+								JsonVal: []byte(`{"name":"eth0",` +
+									`"counters":{"in-octets":10}}`)}}),
+						pgnmi.Update(pgnmi.IntfStatePath("eth0", "mtu"),
+							pgnmi.Uintval(1500)),
+					},
+					Update: []*gnmi.Update{
+						pgnmi.Update(pgnmi.IntfStateCountersPath("eth1", "in-octets"),
+							pgnmi.Uintval(20)),
+						pgnmi.Update(pgnmi.IntfStatePath("eth1", "name"),
+							pgnmi.Strval("eth1")),
+					},
+				},
+			},
+		},
+		{
+			name:   "gnmi notifications",
+			format: FormatGNMI,
+			out: `{"prefix": {"elem": [{"name": "system"}]}, "update": ` +
+				`[{"path": {"elem": [{"name": "state"}, {"name": "hostname"}]}, ` +
+				`"val": {"stringVal": "dev1"}}]}
+{"delete": [{"elem": [{"name": "interfaces"}]}]}
+`,
+			expected: []*gnmi.SetRequest{
+				{
+					Prefix: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "system"}}},
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+								{Name: "state"}, {Name: "hostname"}}},
+							Val: &gnmi.TypedValue{
+								Value: &gnmi.TypedValue_StringVal{StringVal: "dev1"}},
+						},
+					},
+				},
+				{
+					Delete: []*gnmi.Path{pgnmi.Path("interfaces")},
+				},
+			},
+		},
+		{
+			name:   "empty output",
+			format: FormatJSONLines,
+			out:    "\n",
+		},
+		{
+			name:   "bad op",
+			format: FormatJSONLines,
+			out:    `{"op": "frob", "path": "/a", "value": 1}`,
+			err: errors.New(`Invalid op 'frob' in line ` +
+				`'{"op": "frob", "path": "/a", "value": 1}'`),
+		},
+		{
+			name:   "unsupported value",
+			format: FormatJSONLines,
+			out:    `{"path": "/a", "value": [1, 2]}`,
+			err:    errors.New("Unsupported value [1 2] at path '/a'"),
+		},
+		{
+			name:   "bad format",
+			format: "xml",
+			out:    "<a/>",
+			err:    errors.New("Unknown format 'xml'"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setreqs, err := SetRequests(tc.format, []byte(tc.out))
+			if tc.err != nil {
+				if err == nil || err.Error() != tc.err.Error() {
+					t.Fatalf("Expected error '%v', got '%v'", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			checkSetRequests(t, setreqs, tc.expected)
+		})
+	}
+}
+
+// runProvider runs an exec provider until it has issued the specified
+// number of SetRequests and returns them.
+func runProvider(t *testing.T, command string, longRunning bool,
+	n int) []*gnmi.SetRequest {
+	p := NewExecProvider(command, FormatJSONLines, time.Hour, time.Minute,
+		longRunning, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan *gnmi.SetRequest)
+	p.InitGNMI(pgnmi.NewSimpleGNMIClient(func(ctx context.Context,
+		req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+		ch <- req
+		return &gnmi.SetResponse{}, nil
+	}))
+	errc := make(chan error, 1)
+	go func() { errc <- p.Run(ctx) }()
+
+	var setreqs []*gnmi.SetRequest
+	for len(setreqs) < n {
+		select {
+		case req := <-ch:
+			setreqs = append(setreqs, req)
+		case err := <-errc:
+			t.Fatalf("Provider exited early: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Timed out waiting for SetRequests")
+		}
+	}
+	return setreqs
+}
+
+func TestRun(t *testing.T) {
+	hostname := pgnmi.Update(pgnmi.Path("system", "state", "hostname"),
+		pgnmi.Strval("dev1"))
+	mtu := pgnmi.Update(pgnmi.IntfStatePath("eth0", "mtu"), pgnmi.Uintval(1500))
+	command := `echo '{"path": "/system/state/hostname", "value": "dev1"}'; ` +
+		`echo '{"path": "/interfaces/interface[name=eth0]/state/mtu", "value": 1500}'`
+
+	t.Run("poll", func(t *testing.T) {
+		checkSetRequests(t, runProvider(t, command, false, 1),
+			[]*gnmi.SetRequest{{Update: []*gnmi.Update{hostname, mtu}}})
+	})
+	t.Run("poll with bad line", func(t *testing.T) {
+		checkSetRequests(t, runProvider(t, "echo '{bad'; "+command, false, 1),
+			[]*gnmi.SetRequest{{Update: []*gnmi.Update{hostname, mtu}}})
+	})
+	t.Run("stream", func(t *testing.T) {
+		checkSetRequests(t, runProvider(t, command+"; sleep 60", true, 2),
+			[]*gnmi.SetRequest{
+				{Update: []*gnmi.Update{hostname}},
+				{Update: []*gnmi.Update{mtu}},
+			})
+	})
+	t.Run("stream with bad line", func(t *testing.T) {
+		checkSetRequests(t, runProvider(t, "echo '{bad'; "+command+"; sleep 60",
+			true, 2),
+			[]*gnmi.SetRequest{
+				{Update: []*gnmi.Update{hostname}},
+				{Update: []*gnmi.Update{mtu}},
+			})
+	})
+}
+
+// A poll that outlasts the timeout should be killed and reported.
+func TestPollTimeout(t *testing.T) {
+	p := NewExecProvider("exec sleep 60", FormatJSONLines, time.Hour,
+		100*time.Millisecond, false, true)
+	p.InitGNMI(pgnmi.NewSimpleGNMIClient(func(ctx context.Context,
+		req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+		return &gnmi.SetResponse{}, nil
+	}))
+	errc := make(chan error, 1)
+	go func() { errc <- p.Run(context.Background()) }()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("Expected error from timed-out poll")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the poll to time out")
+	}
+}
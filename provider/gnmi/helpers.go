@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	agnmi "github.com/aristanetworks/goarista/gnmi"
@@ -97,6 +98,11 @@ func Intval(i int64) *gnmi.TypedValue {
 	return jsonValue(i)
 }
 
+// Floatval returns a gnmi.TypedValue from a float64.
+func Floatval(f float64) *gnmi.TypedValue {
+	return jsonValue(f)
+}
+
 // Boolval returns a gnmi.TypedValue from a bool.
 func Boolval(b bool) *gnmi.TypedValue {
 	return jsonValue(b)
@@ -252,6 +258,20 @@ func PlatformComponentStatePath(name, leafName string) *gnmi.Path {
 		ListWithKey("component", "name", name), "state", leafName)
 }
 
+// HostnameUpdates returns updates for the system hostname and, if
+// the hostname is fully qualified, the domain name, splitting the
+// hostname at its first dot as the SNMP translator splits sysName.
+func HostnameUpdates(hostname string) []*gnmi.Update {
+	ss := strings.SplitN(hostname, ".", 2)
+	updates := []*gnmi.Update{Update(Path("system", "state", "hostname"),
+		Strval(ss[0]))}
+	if len(ss) > 1 {
+		updates = append(updates, Update(Path("system", "state", "domain-name"),
+			Strval(ss[1])))
+	}
+	return updates
+}
+
 type setRequestProcessor = func(ctx context.Context,
 	req *gnmi.SetRequest) (*gnmi.SetResponse, error)
 
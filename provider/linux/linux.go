@@ -0,0 +1,361 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package linux
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/openconfig"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// ChassisName is the name of the chassis component.
+const ChassisName = "chassis"
+
+type linux struct {
+	client       gnmi.GNMIClient
+	errc         chan error
+	pollInterval time.Duration
+	root         string
+}
+
+var now = time.Now
+
+// readFile returns the trimmed contents of the file at the specified
+// path relative to root.
+func readFile(root, path string) (string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// readOptionalFile is like readFile, but returns an empty string if
+// the file can't be read. Many sysfs attributes are unreadable for
+// some interfaces (e.g. speed on a down link) or unprivileged users.
+func readOptionalFile(root, path string) string {
+	s, err := readFile(root, path)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// netDevStats holds an interface's counters from /proc/net/dev.
+type netDevStats struct {
+	inOctets, inPkts, inErrs, inDiscards, inMulticast uint64
+	outOctets, outPkts, outErrs, outDiscards          uint64
+}
+
+// parseNetDev parses the contents of /proc/net/dev, returning
+// counters by interface name.
+func parseNetDev(s string) (map[string]*netDevStats, error) {
+	stats := make(map[string]*netDevStats)
+	for _, line := range strings.Split(s, "\n") {
+		// Skip the two header lines.
+		i := strings.Index(line, ":")
+		if i < 0 || strings.Contains(line, "|") {
+			continue
+		}
+		name := strings.TrimSpace(line[:i])
+		fields := strings.Fields(line[i+1:])
+		if len(fields) < 16 {
+			return nil, fmt.Errorf("Unexpected /proc/net/dev line: '%s'", line)
+		}
+		var vals [16]uint64
+		for j := range vals {
+			v, err := strconv.ParseUint(fields[j], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			vals[j] = v
+		}
+		stats[name] = &netDevStats{
+			inOctets:    vals[0],
+			inPkts:      vals[1],
+			inErrs:      vals[2],
+			inDiscards:  vals[3],
+			inMulticast: vals[7],
+			outOctets:   vals[8],
+			outPkts:     vals[9],
+			outErrs:     vals[10],
+			outDiscards: vals[11],
+		}
+	}
+	return stats, nil
+}
+
+// interfaceType maps an ARPHRD_* value from /sys/class/net/*/type
+// to an IANA interface type.
+func interfaceType(arphrd string) string {
+	switch arphrd {
+	case "1":
+		return openconfig.InterfaceType(6) // ethernetCsmacd
+	case "772":
+		return openconfig.InterfaceType(24) // softwareLoopback
+	}
+	return openconfig.InterfaceType(1) // other
+}
+
+var operStatus = map[string]string{
+	"up":             "UP",
+	"down":           "DOWN",
+	"testing":        "TESTING",
+	"unknown":        "UNKNOWN",
+	"dormant":        "DORMANT",
+	"notpresent":     "NOT_PRESENT",
+	"lowerlayerdown": "LOWER_LAYER_DOWN",
+}
+
+// intfUpdates returns updates for an interface, using /proc/net/dev
+// counters and the interface's sysfs attributes.
+func intfUpdates(root, name string, stats *netDevStats) []*gnmi.Update {
+	sysPath := filepath.Join("sys/class/net", name)
+	updates := []*gnmi.Update{
+		pgnmi.Update(pgnmi.IntfPath(name, "name"), pgnmi.Strval(name)),
+		pgnmi.Update(pgnmi.IntfConfigPath(name, "name"), pgnmi.Strval(name)),
+		pgnmi.Update(pgnmi.IntfStatePath(name, "name"), pgnmi.Strval(name)),
+		pgnmi.Update(pgnmi.IntfStatePath(name, "type"),
+			pgnmi.Strval("iana-if-type:"+
+				interfaceType(readOptionalFile(root, filepath.Join(sysPath, "type"))))),
+	}
+
+	if mtu, err := strconv.ParseUint(
+		readOptionalFile(root, filepath.Join(sysPath, "mtu")), 10, 64); err == nil {
+		if mtu > math.MaxUint16 {
+			mtu = math.MaxUint16
+		}
+		updates = append(updates,
+			pgnmi.Update(pgnmi.IntfStatePath(name, "mtu"), pgnmi.Uintval(mtu)))
+	}
+
+	// IFF_UP is the administrative state.
+	if flags, err := strconv.ParseUint(
+		readOptionalFile(root, filepath.Join(sysPath, "flags")), 0, 64); err == nil {
+		adminStatus := openconfig.IntfAdminStatus(2)
+		if flags&1 != 0 {
+			adminStatus = openconfig.IntfAdminStatus(1)
+		}
+		updates = append(updates, pgnmi.Update(pgnmi.IntfStatePath(name,
+			"admin-status"), pgnmi.Strval(adminStatus)))
+	}
+	if status, ok := operStatus[readOptionalFile(root,
+		filepath.Join(sysPath, "operstate"))]; ok {
+		updates = append(updates, pgnmi.Update(pgnmi.IntfStatePath(name,
+			"oper-status"), pgnmi.Strval(status)))
+	}
+
+	ethernetStatePath := func(leaf string) *gnmi.Path {
+		return pgnmi.Path("interfaces", pgnmi.ListWithKey("interface", "name", name),
+			"ethernet", "state", leaf)
+	}
+	if mac := readOptionalFile(root, filepath.Join(sysPath, "address")); mac != "" {
+		updates = append(updates,
+			pgnmi.Update(ethernetStatePath("mac-address"), pgnmi.Strval(mac)))
+	}
+	// speed is -1 (or unreadable) if the link is down.
+	if speed, err := strconv.ParseUint(
+		readOptionalFile(root, filepath.Join(sysPath, "speed")), 10, 64); err == nil {
+		updates = append(updates, pgnmi.Update(ethernetStatePath("port-speed"),
+			pgnmi.Strval("openconfig-if-ethernet:"+openconfig.PortSpeed(speed))))
+	}
+
+	counter := func(leaf string, v uint64) *gnmi.Update {
+		return pgnmi.Update(pgnmi.IntfStateCountersPath(name, leaf), pgnmi.Uintval(v))
+	}
+	// /proc/net/dev's packet count includes multicast packets.
+	inUnicast := stats.inPkts
+	if stats.inMulticast <= inUnicast {
+		inUnicast -= stats.inMulticast
+	}
+	return append(updates,
+		counter("in-octets", stats.inOctets),
+		counter("in-unicast-pkts", inUnicast),
+		counter("in-multicast-pkts", stats.inMulticast),
+		counter("in-errors", stats.inErrs),
+		counter("in-discards", stats.inDiscards),
+		counter("out-octets", stats.outOctets),
+		counter("out-unicast-pkts", stats.outPkts),
+		counter("out-errors", stats.outErrs),
+		counter("out-discards", stats.outDiscards))
+}
+
+// InterfaceUpdates returns updates for all interfaces found in
+// /proc/net/dev under the specified root directory.
+func InterfaceUpdates(root string) ([]*gnmi.Update, error) {
+	netDev, err := readFile(root, "proc/net/dev")
+	if err != nil {
+		return nil, err
+	}
+	stats, err := parseNetDev(netDev)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var updates []*gnmi.Update
+	for _, name := range names {
+		updates = append(updates, intfUpdates(root, name, stats[name])...)
+	}
+	return updates, nil
+}
+
+// SystemUpdates returns updates for the system hostname, domain name,
+// and boot time.
+func SystemUpdates(root string) ([]*gnmi.Update, error) {
+	hostname, err := readFile(root, "proc/sys/kernel/hostname")
+	if err != nil {
+		return nil, err
+	}
+	updates := pgnmi.HostnameUpdates(hostname)
+
+	uptime, err := readFile(root, "proc/uptime")
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(uptime)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("Unexpected /proc/uptime contents: '%s'", uptime)
+	}
+	secs, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, err
+	}
+	return append(updates, pgnmi.Update(pgnmi.Path("system", "state", "boot-time"),
+		pgnmi.Intval(now().Unix()-int64(secs)))), nil
+}
+
+// dmiSerial returns the first readable, non-empty serial number in
+// /sys/class/dmi/id.
+func dmiSerial(root string) string {
+	for _, f := range []string{"product_serial", "chassis_serial", "board_serial"} {
+		s := readOptionalFile(root, filepath.Join("sys/class/dmi/id", f))
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// ComponentUpdates returns updates for the chassis component, as
+// described by /sys/class/dmi/id, and the running kernel release.
+func ComponentUpdates(root string) ([]*gnmi.Update, error) {
+	state := func(leaf string) *gnmi.Path {
+		return pgnmi.PlatformComponentStatePath(ChassisName, leaf)
+	}
+	updates := []*gnmi.Update{
+		pgnmi.Update(pgnmi.PlatformComponentPath(ChassisName, "name"),
+			pgnmi.Strval(ChassisName)),
+		pgnmi.Update(pgnmi.PlatformComponentConfigPath(ChassisName, "name"),
+			pgnmi.Strval(ChassisName)),
+		pgnmi.Update(state("name"), pgnmi.Strval(ChassisName)),
+		pgnmi.Update(state("type"),
+			pgnmi.Strval("openconfig-platform-types:CHASSIS")),
+	}
+	for _, leaf := range []struct {
+		name, value string
+	}{
+		{"serial-no", dmiSerial(root)},
+		{"mfg-name", readOptionalFile(root, "sys/class/dmi/id/sys_vendor")},
+		{"description", readOptionalFile(root, "sys/class/dmi/id/product_name")},
+		{"hardware-version", readOptionalFile(root, "sys/class/dmi/id/product_version")},
+		{"software-version", readOptionalFile(root, "proc/sys/kernel/osrelease")},
+	} {
+		if leaf.value != "" {
+			updates = append(updates, pgnmi.Update(state(leaf.name),
+				pgnmi.Strval(leaf.value)))
+		}
+	}
+	return updates, nil
+}
+
+// DeviceID returns an ID for the host under the specified root
+// directory: its DMI serial number if readable, or else its machine
+// ID.
+func DeviceID(root string) (string, error) {
+	if s := dmiSerial(root); s != "" {
+		return s, nil
+	}
+	id, err := readFile(root, "etc/machine-id")
+	if err != nil {
+		return "", fmt.Errorf("No DMI serial number or machine ID: %v", err)
+	}
+	return id, nil
+}
+
+func (l *linux) poll() ([]*gnmi.SetRequest, error) {
+	setRequest := &gnmi.SetRequest{
+		Delete: []*gnmi.Path{
+			pgnmi.Path("interfaces"),
+			pgnmi.Path("system"),
+			pgnmi.Path("components"),
+		},
+	}
+	for _, fn := range []func(string) ([]*gnmi.Update, error){
+		InterfaceUpdates, SystemUpdates, ComponentUpdates,
+	} {
+		updates, err := fn(l.root)
+		if err != nil {
+			return nil, err
+		}
+		setRequest.Replace = append(setRequest.Replace, updates...)
+	}
+	return []*gnmi.SetRequest{setRequest}, nil
+}
+
+func (l *linux) handleErrors(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-l.errc:
+			return fmt.Errorf("Error in linux provider: %v", err)
+		}
+	}
+}
+
+func (l *linux) Run(ctx context.Context) error {
+	go pgnmi.PollForever(ctx, l.client, l.pollInterval, l.poll, l.errc)
+	return l.handleErrors(ctx)
+}
+
+func (l *linux) InitGNMI(client gnmi.GNMIClient) {
+	l.client = client
+}
+
+func (l *linux) OpenConfig() bool {
+	return true
+}
+
+// NewLinuxProvider returns a provider that streams interface, system,
+// and platform data read from /proc and /sys under the specified root
+// directory, which is "/" except when reading a host's filesystems
+// from a container or in tests.
+func NewLinuxProvider(pollInterval time.Duration, root string) provider.GNMIProvider {
+	if root == "" {
+		root = string(os.PathSeparator)
+	}
+	return &linux{
+		errc:         make(chan error),
+		pollInterval: pollInterval,
+		root:         root,
+	}
+}
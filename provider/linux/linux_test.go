@@ -0,0 +1,227 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package linux
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// netDev is /proc/net/dev output, with columns squeezed together to
+// fit.
+const netDev = `Inter-| Receive | Transmit
+ face |bytes packets errs drop fifo frame compressed multicast|bytes packets errs ` +
+	`drop fifo colls carrier compressed
+ lo: 123456 789 0 0 0 0 0 0 123456 789 0 0 0 0 0 0
+ eth0: 98765432 54321 2 3 0 0 0 321 12345678 43210 4 5 0 0 0 0
+`
+
+// makeRoot creates a fake root directory containing the specified
+// files.
+func makeRoot(t *testing.T, files map[string]string) string {
+	root, err := ioutil.TempDir("", "linux_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, contents := range files {
+		p := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+var fakeRoot = map[string]string{
+	"proc/net/dev":                     netDev,
+	"proc/uptime":                      "1000.52 3850.12",
+	"proc/sys/kernel/hostname":         "host1.example.com",
+	"proc/sys/kernel/osrelease":        "5.4.0-42-generic",
+	"sys/class/net/lo/type":            "772",
+	"sys/class/net/lo/mtu":             "65536",
+	"sys/class/net/lo/flags":           "0x9",
+	"sys/class/net/lo/operstate":       "unknown",
+	"sys/class/net/lo/address":         "00:00:00:00:00:00",
+	"sys/class/net/eth0/type":          "1",
+	"sys/class/net/eth0/mtu":           "9000",
+	"sys/class/net/eth0/flags":         "0x1002",
+	"sys/class/net/eth0/operstate":     "down",
+	"sys/class/net/eth0/address":       "52:54:00:12:34:56",
+	"sys/class/net/eth0/speed":         "10000",
+	"sys/class/dmi/id/product_serial":  "SN12345",
+	"sys/class/dmi/id/product_name":    "PowerEdge R640",
+	"sys/class/dmi/id/product_version": "1.0",
+	"sys/class/dmi/id/sys_vendor":      "Dell Inc.",
+	"sys/class/dmi/id/chassis_serial":  "CH999",
+	"etc/machine-id":                   "0123456789abcdef",
+}
+
+func ethernetStatePath(name, leaf string) *gnmi.Path {
+	return pgnmi.Path("interfaces", pgnmi.ListWithKey("interface", "name", name),
+		"ethernet", "state", leaf)
+}
+
+func TestInterfaceUpdates(t *testing.T) {
+	root := makeRoot(t, fakeRoot)
+	defer os.RemoveAll(root)
+
+	updates, err := InterfaceUpdates(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*gnmi.Update{
+		pgnmi.Update(pgnmi.IntfPath("eth0", "name"), pgnmi.Strval("eth0")),
+		pgnmi.Update(pgnmi.IntfConfigPath("eth0", "name"), pgnmi.Strval("eth0")),
+		pgnmi.Update(pgnmi.IntfStatePath("eth0", "name"), pgnmi.Strval("eth0")),
+		pgnmi.Update(pgnmi.IntfStatePath("eth0", "type"),
+			pgnmi.Strval("iana-if-type:ethernetCsmacd")),
+		pgnmi.Update(pgnmi.IntfStatePath("eth0", "mtu"), pgnmi.Uintval(9000)),
+		pgnmi.Update(pgnmi.IntfStatePath("eth0", "admin-status"), pgnmi.Strval("DOWN")),
+		pgnmi.Update(pgnmi.IntfStatePath("eth0", "oper-status"), pgnmi.Strval("DOWN")),
+		pgnmi.Update(ethernetStatePath("eth0", "mac-address"),
+			pgnmi.Strval("52:54:00:12:34:56")),
+		pgnmi.Update(ethernetStatePath("eth0", "port-speed"),
+			pgnmi.Strval("openconfig-if-ethernet:SPEED_10GB")),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "in-octets"), pgnmi.Uintval(98765432)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "in-unicast-pkts"),
+			pgnmi.Uintval(54000)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "in-multicast-pkts"),
+			pgnmi.Uintval(321)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "in-errors"), pgnmi.Uintval(2)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "in-discards"), pgnmi.Uintval(3)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "out-octets"), pgnmi.Uintval(12345678)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "out-unicast-pkts"),
+			pgnmi.Uintval(43210)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "out-errors"), pgnmi.Uintval(4)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("eth0", "out-discards"), pgnmi.Uintval(5)),
+
+		pgnmi.Update(pgnmi.IntfPath("lo", "name"), pgnmi.Strval("lo")),
+		pgnmi.Update(pgnmi.IntfConfigPath("lo", "name"), pgnmi.Strval("lo")),
+		pgnmi.Update(pgnmi.IntfStatePath("lo", "name"), pgnmi.Strval("lo")),
+		pgnmi.Update(pgnmi.IntfStatePath("lo", "type"),
+			pgnmi.Strval("iana-if-type:softwareLoopback")),
+		pgnmi.Update(pgnmi.IntfStatePath("lo", "mtu"), pgnmi.Uintval(65535)),
+		pgnmi.Update(pgnmi.IntfStatePath("lo", "admin-status"), pgnmi.Strval("UP")),
+		pgnmi.Update(pgnmi.IntfStatePath("lo", "oper-status"), pgnmi.Strval("UNKNOWN")),
+		pgnmi.Update(ethernetStatePath("lo", "mac-address"),
+			pgnmi.Strval("00:00:00:00:00:00")),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "in-octets"), pgnmi.Uintval(123456)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "in-unicast-pkts"), pgnmi.Uintval(789)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "in-multicast-pkts"), pgnmi.Uintval(0)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "in-errors"), pgnmi.Uintval(0)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "in-discards"), pgnmi.Uintval(0)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "out-octets"), pgnmi.Uintval(123456)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "out-unicast-pkts"), pgnmi.Uintval(789)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "out-errors"), pgnmi.Uintval(0)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("lo", "out-discards"), pgnmi.Uintval(0)),
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestSystemUpdates(t *testing.T) {
+	root := makeRoot(t, fakeRoot)
+	defer os.RemoveAll(root)
+
+	now = func() time.Time { return time.Unix(1500000000, 0) }
+	defer func() { now = time.Now }()
+
+	updates, err := SystemUpdates(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*gnmi.Update{
+		pgnmi.Update(pgnmi.Path("system", "state", "hostname"), pgnmi.Strval("host1")),
+		pgnmi.Update(pgnmi.Path("system", "state", "domain-name"),
+			pgnmi.Strval("example.com")),
+		pgnmi.Update(pgnmi.Path("system", "state", "boot-time"),
+			pgnmi.Intval(1500000000-1000)),
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestComponentUpdates(t *testing.T) {
+	root := makeRoot(t, fakeRoot)
+	defer os.RemoveAll(root)
+
+	updates, err := ComponentUpdates(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := func(leaf string) *gnmi.Path {
+		return pgnmi.PlatformComponentStatePath("chassis", leaf)
+	}
+	expected := []*gnmi.Update{
+		pgnmi.Update(pgnmi.PlatformComponentPath("chassis", "name"), pgnmi.Strval("chassis")),
+		pgnmi.Update(pgnmi.PlatformComponentConfigPath("chassis", "name"),
+			pgnmi.Strval("chassis")),
+		pgnmi.Update(state("name"), pgnmi.Strval("chassis")),
+		pgnmi.Update(state("type"), pgnmi.Strval("openconfig-platform-types:CHASSIS")),
+		pgnmi.Update(state("serial-no"), pgnmi.Strval("SN12345")),
+		pgnmi.Update(state("mfg-name"), pgnmi.Strval("Dell Inc.")),
+		pgnmi.Update(state("description"), pgnmi.Strval("PowerEdge R640")),
+		pgnmi.Update(state("hardware-version"), pgnmi.Strval("1.0")),
+		pgnmi.Update(state("software-version"), pgnmi.Strval("5.4.0-42-generic")),
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestDeviceID(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		remove   []string
+		expected string
+	}{
+		{
+			name:     "product serial",
+			expected: "SN12345",
+		},
+		{
+			name:     "chassis serial",
+			remove:   []string{"sys/class/dmi/id/product_serial"},
+			expected: "CH999",
+		},
+		{
+			name: "machine ID",
+			remove: []string{"sys/class/dmi/id/product_serial",
+				"sys/class/dmi/id/chassis_serial"},
+			expected: "0123456789abcdef",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := make(map[string]string)
+			for k, v := range fakeRoot {
+				files[k] = v
+			}
+			for _, r := range tc.remove {
+				delete(files, r)
+			}
+			root := makeRoot(t, files)
+			defer os.RemoveAll(root)
+			did, err := DeviceID(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if did != tc.expected {
+				t.Fatalf("Expected device ID %s, got %s", tc.expected, did)
+			}
+		})
+	}
+}
@@ -144,3 +144,42 @@ var lldpPortIDType = []string{
 func LLDPPortIDType(t int) string {
 	return oneIndexed(lldpPortIDType, t, "")
 }
+
+var portSpeeds = map[uint64]string{
+	10:     "SPEED_10MB",
+	100:    "SPEED_100MB",
+	1000:   "SPEED_1GB",
+	2500:   "SPEED_2500MB",
+	5000:   "SPEED_5GB",
+	10000:  "SPEED_10GB",
+	25000:  "SPEED_25GB",
+	40000:  "SPEED_40GB",
+	50000:  "SPEED_50GB",
+	100000: "SPEED_100GB",
+	200000: "SPEED_200GB",
+	400000: "SPEED_400GB",
+}
+
+// PortSpeed returns the OpenConfig Ethernet port speed identity
+// corresponding to a speed in Mb/s.
+func PortSpeed(mbps uint64) string {
+	if s, ok := portSpeeds[mbps]; ok {
+		return s
+	}
+	return "SPEED_UNKNOWN"
+}
+
+var routeProtocols = map[int]string{
+	2:  "DIRECTLY_CONNECTED",
+	3:  "STATIC",
+	9:  "ISIS",
+	13: "OSPF",
+	14: "BGP",
+}
+
+// RouteProtocol returns the OpenConfig install protocol identity
+// corresponding to an IANAipRouteProtocol value, or an empty string
+// if OpenConfig has no equivalent.
+func RouteProtocol(p int) string {
+	return routeProtocols[p]
+}
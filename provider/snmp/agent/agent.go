@@ -0,0 +1,542 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+// Package agent implements a simulated SNMP agent that answers
+// requests from recorded polls of a device, for testing SNMP
+// collection end to end without the device.
+package agent
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aristanetworks/cloudvision-go/log"
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	usmStatsUnknownUserNames = ".1.3.6.1.6.3.15.1.1.3.0"
+	usmStatsUnknownEngineIDs = ".1.3.6.1.6.3.15.1.1.4.0"
+
+	// defaultEngineID is an RFC 3411 engine ID in text format
+	// under Arista's enterprise number.
+	defaultEngineID = "\x80\x00\x75\x71\x04snmpagent"
+
+	// maxMsgSize bounds the size of a response to a GETBULK.
+	maxMsgSize = 65000
+
+	// defaultMaxRepetitions is the max-repetitions used for a
+	// GETBULK whose fields can't be decoded.
+	defaultMaxRepetitions = 10
+)
+
+// Config configures an Agent.
+type Config struct {
+	// Community is the SNMPv2c community the agent answers to. If
+	// it's empty, the agent answers to any community.
+	Community string
+
+	// Users are the SNMPv3 USM users the agent answers to, with
+	// their authentication and privacy protocols and passphrases.
+	Users []*gosnmp.UsmSecurityParameters
+
+	// EngineID is the agent's SNMPv3 authoritative engine ID. A
+	// default is used if it's empty.
+	EngineID string
+
+	// RotateInterval is how long the agent serves each poll before
+	// moving on to the next. If it's zero, the agent serves a poll
+	// until Advance is called.
+	RotateInterval time.Duration
+}
+
+// user is an SNMPv3 user, with a decoder holding its keys localized
+// to the agent's engine ID.
+type user struct {
+	params  *gosnmp.UsmSecurityParameters
+	decoder *gosnmp.GoSNMP
+}
+
+// Agent is a simulated SNMP agent. It answers SNMPv2c and SNMPv3 GET,
+// GETNEXT, and GETBULK requests over UDP from one of a set of recorded
+// polls, moving from one poll to the next on a timer or on demand.
+type Agent struct {
+	conn     net.PacketConn
+	cfg      Config
+	engineID string
+	users    []*user
+	polls    []table
+	start    time.Time
+
+	// decoder decodes messages that aren't authenticated.
+	decoder *gosnmp.GoSNMP
+
+	// advanced is the number of times Advance has been called.
+	advanced int32
+
+	// USM statistics, reported to clients.
+	unknownEngineIDs uint32
+	unknownUserNames uint32
+}
+
+// New returns an Agent listening on the specified UDP address and
+// serving the specified polls. Call Serve to start answering.
+func New(address string, polls [][]*gosnmp.SnmpPDU, cfg Config) (*Agent, error) {
+	if len(polls) == 0 {
+		return nil, errors.New("No polls to serve")
+	}
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	a := &Agent{
+		conn:     conn,
+		cfg:      cfg,
+		engineID: cfg.EngineID,
+		start:    time.Now(),
+		decoder: &gosnmp.GoSNMP{
+			Version:            gosnmp.Version3,
+			SecurityModel:      gosnmp.UserSecurityModel,
+			SecurityParameters: &gosnmp.UsmSecurityParameters{},
+		},
+	}
+	if a.engineID == "" {
+		a.engineID = defaultEngineID
+	}
+	for _, p := range polls {
+		a.polls = append(a.polls, newTable(p))
+	}
+	for _, u := range cfg.Users {
+		params := u.Copy().(*gosnmp.UsmSecurityParameters)
+		params.AuthoritativeEngineID = a.engineID
+		a.users = append(a.users, &user{
+			params: params,
+			decoder: &gosnmp.GoSNMP{
+				Version:            gosnmp.Version3,
+				SecurityModel:      gosnmp.UserSecurityModel,
+				SecurityParameters: params,
+			},
+		})
+	}
+	return a, nil
+}
+
+// Addr returns the address on which the Agent is listening.
+func (a *Agent) Addr() net.Addr {
+	return a.conn.LocalAddr()
+}
+
+// Close stops the Agent.
+func (a *Agent) Close() error {
+	return a.conn.Close()
+}
+
+// Advance moves the Agent on to its next poll, wrapping around after
+// the last.
+func (a *Agent) Advance() {
+	atomic.AddInt32(&a.advanced, 1)
+}
+
+// table returns the poll currently being served.
+func (a *Agent) table() table {
+	n := int(atomic.LoadInt32(&a.advanced))
+	if a.cfg.RotateInterval > 0 {
+		n += int(time.Since(a.start) / a.cfg.RotateInterval)
+	}
+	return a.polls[n%len(a.polls)]
+}
+
+// engineTime returns the agent's snmpEngineTime.
+func (a *Agent) engineTime() uint32 {
+	return uint32(time.Since(a.start) / time.Second)
+}
+
+// Serve answers requests until the Agent is closed.
+func (a *Agent) Serve() error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			if strings.Contains(err.Error(), "use of closed network connection") {
+				return nil
+			}
+			return err
+		}
+		msg := make([]byte, n)
+		copy(msg, buf[:n])
+		a.handle(msg, addr)
+	}
+}
+
+// handle answers a request from the specified address.
+func (a *Agent) handle(msg []byte, addr net.Addr) {
+	req, u := a.decode(msg)
+	if req == nil {
+		log.Log(a).Debugf("Dropping undecodable request from %v", addr)
+		return
+	}
+
+	var resp *gosnmp.SnmpPacket
+	if req.Version != gosnmp.Version3 {
+		if a.cfg.Community != "" && req.Community != a.cfg.Community {
+			log.Log(a).Debugf("Dropping request from %v with wrong community", addr)
+			return
+		}
+		resp = a.response(req, msg)
+	} else {
+		usm := req.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		if usm.AuthoritativeEngineID != a.engineID {
+			// This is discovery: tell the client who we are.
+			resp = a.report(req, usmStatsUnknownEngineIDs, &a.unknownEngineIDs)
+		} else if u == nil {
+			resp = a.report(req, usmStatsUnknownUserNames, &a.unknownUserNames)
+		} else {
+			resp = a.response(req, msg)
+			a.secure(resp, req)
+		}
+	}
+
+	b, err := resp.MarshalMsg()
+	for err == nil && len(b) > maxMsgSize && len(resp.Variables) > 1 {
+		resp.Variables = resp.Variables[:len(resp.Variables)*3/4]
+		b, err = resp.MarshalMsg()
+	}
+	if err != nil {
+		log.Log(a).Infof("Error marshaling response to %v: %v", addr, err)
+		return
+	}
+	if _, err := a.conn.WriteTo(b, addr); err != nil {
+		log.Log(a).Infof("Error sending response to %v: %v", addr, err)
+	}
+}
+
+// berInt decodes an ASN.1 INTEGER. gosnmp doesn't always encode
+// integers minimally, as DER and so asn1.Unmarshal require, so the
+// integers of raw messages are decoded with this.
+func berInt(v asn1.RawValue) (int, bool) {
+	if v.Class != asn1.ClassUniversal || v.Tag != asn1.TagInteger ||
+		len(v.Bytes) == 0 || len(v.Bytes) > 8 {
+		return 0, false
+	}
+	n := int64(int8(v.Bytes[0]))
+	for _, b := range v.Bytes[1:] {
+		n = n<<8 | int64(b)
+	}
+	return int(n), true
+}
+
+// v3Header returns the msgFlags and USM user name of an SNMPv3
+// message, and false if the message isn't one.
+func v3Header(msg []byte) (gosnmp.SnmpV3MsgFlags, string, bool) {
+	var hdr struct {
+		Version asn1.RawValue
+		Global  struct {
+			ID      asn1.RawValue
+			MaxSize asn1.RawValue
+			Flags   []byte
+			Model   asn1.RawValue
+		}
+		SecurityParameters []byte
+	}
+	if _, err := asn1.Unmarshal(msg, &hdr); err != nil ||
+		len(hdr.Global.Flags) != 1 {
+		return 0, "", false
+	}
+	if version, ok := berInt(hdr.Version); !ok || version != int(gosnmp.Version3) {
+		return 0, "", false
+	}
+	var usm struct {
+		EngineID []byte
+		Boots    asn1.RawValue
+		Time     asn1.RawValue
+		UserName []byte
+	}
+	if _, err := asn1.Unmarshal(hdr.SecurityParameters, &usm); err != nil {
+		return 0, "", false
+	}
+	return gosnmp.SnmpV3MsgFlags(hdr.Global.Flags[0]), string(usm.UserName), true
+}
+
+// bulkParams returns the non-repeaters and max-repetitions fields of
+// the GETBULK request in msg, whose decoded form is req, and false if
+// they can't be decoded. gosnmp's decoder drops max-repetitions, so
+// they're decoded from the raw message, after decrypting its scoped
+// PDU with the request's security parameters if need be.
+func bulkParams(msg []byte, req *gosnmp.SnmpPacket) (int, int, bool) {
+	var pdu asn1.RawValue
+	if req.Version != gosnmp.Version3 {
+		var m struct {
+			Version   asn1.RawValue
+			Community []byte
+			PDU       asn1.RawValue
+		}
+		if _, err := asn1.Unmarshal(msg, &m); err != nil {
+			return 0, 0, false
+		}
+		pdu = m.PDU
+	} else {
+		var m struct {
+			Version            asn1.RawValue
+			Global             asn1.RawValue
+			SecurityParameters []byte
+			Data               asn1.RawValue
+		}
+		if _, err := asn1.Unmarshal(msg, &m); err != nil {
+			return 0, 0, false
+		}
+		scoped := m.Data.FullBytes
+		if m.Data.Tag == asn1.TagOctetString {
+			usm, ok := req.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+			if !ok {
+				return 0, 0, false
+			}
+			var err error
+			if scoped, err = decryptScopedPDU(usm, m.Data.Bytes); err != nil {
+				return 0, 0, false
+			}
+		}
+		var s struct {
+			ContextEngineID []byte
+			ContextName     []byte
+			PDU             asn1.RawValue
+		}
+		// An encrypted scoped PDU may be followed by padding.
+		if _, err := asn1.Unmarshal(scoped, &s); err != nil {
+			return 0, 0, false
+		}
+		pdu = s.PDU
+	}
+	if pdu.Class != asn1.ClassContextSpecific ||
+		pdu.Tag != int(gosnmp.GetBulkRequest&0x1f) {
+		return 0, 0, false
+	}
+
+	var fields [3]int
+	rest := pdu.Bytes
+	for i := range fields {
+		var v asn1.RawValue
+		var err error
+		var ok bool
+		if rest, err = asn1.Unmarshal(rest, &v); err != nil {
+			return 0, 0, false
+		}
+		if fields[i], ok = berInt(v); !ok {
+			return 0, 0, false
+		}
+	}
+	// The fields are the request ID, non-repeaters, and
+	// max-repetitions.
+	return fields[1], fields[2], true
+}
+
+// decryptScopedPDU decrypts an SNMPv3 scoped PDU per RFC 3414
+// section 8 or RFC 3826, using the localized privacy key and the
+// salt and engine boots and time of the message.
+func decryptScopedPDU(usm *gosnmp.UsmSecurityParameters, b []byte) ([]byte, error) {
+	plaintext := make([]byte, len(b))
+	switch usm.PrivacyProtocol {
+	case gosnmp.DES:
+		if len(usm.PrivacyKey) < 16 || len(usm.PrivacyParameters) != 8 ||
+			len(b)%des.BlockSize != 0 {
+			return nil, errors.New("bad DES parameters")
+		}
+		var iv [8]byte
+		for i := range iv {
+			iv[i] = usm.PrivacyKey[8+i] ^ usm.PrivacyParameters[i]
+		}
+		block, err := des.NewCipher(usm.PrivacyKey[:8])
+		if err != nil {
+			return nil, err
+		}
+		cipher.NewCBCDecrypter(block, iv[:]).CryptBlocks(plaintext, b)
+	case gosnmp.AES, gosnmp.AES192, gosnmp.AES256, gosnmp.AES192C, gosnmp.AES256C:
+		if len(usm.PrivacyParameters) != 8 {
+			return nil, errors.New("bad AES parameters")
+		}
+		var iv [16]byte
+		binary.BigEndian.PutUint32(iv[:], usm.AuthoritativeEngineBoots)
+		binary.BigEndian.PutUint32(iv[4:], usm.AuthoritativeEngineTime)
+		copy(iv[8:], usm.PrivacyParameters)
+		block, err := aes.NewCipher(usm.PrivacyKey)
+		if err != nil {
+			return nil, err
+		}
+		cipher.NewCFBDecrypter(block, iv[:]).XORKeyStream(plaintext, b)
+	default:
+		return nil, fmt.Errorf("unsupported privacy protocol %v", usm.PrivacyProtocol)
+	}
+	return plaintext, nil
+}
+
+// decode decodes a request, returning it along with the SNMPv3 user
+// it's from, if it's from a known one at that user's security level.
+// It returns a nil request if the request can't be decoded, including
+// if it's authenticated but not by a known user.
+func (a *Agent) decode(msg []byte) (*gosnmp.SnmpPacket, *user) {
+	flags, name, v3 := v3Header(msg)
+	var u *user
+	for _, au := range a.users {
+		if au.params.UserName == name {
+			u = au
+		}
+	}
+	if !v3 || flags&gosnmp.AuthNoPriv == 0 {
+		req := a.decoder.UnmarshalTrap(msg, true)
+		if req == nil || req.Version != gosnmp.Version3 ||
+			u == nil || u.params.AuthenticationProtocol > gosnmp.NoAuth {
+			return req, nil
+		}
+		return req, u
+	}
+
+	// gosnmp can only check a message's authenticity with the
+	// sender's keys.
+	if u == nil || u.params.AuthenticationProtocol <= gosnmp.NoAuth ||
+		(flags&gosnmp.AuthPriv == gosnmp.AuthPriv) !=
+			(u.params.PrivacyProtocol > gosnmp.NoPriv) {
+		return nil, nil
+	}
+	req := u.decoder.UnmarshalTrap(msg, true)
+	if req == nil {
+		return nil, nil
+	}
+	return req, u
+}
+
+// response returns the response to a request, whose raw form is msg,
+// from the current poll.
+func (a *Agent) response(req *gosnmp.SnmpPacket, msg []byte) *gosnmp.SnmpPacket {
+	resp := &gosnmp.SnmpPacket{
+		Version:   req.Version,
+		Community: req.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: req.RequestID,
+	}
+	t := a.table()
+	switch req.PDUType {
+	case gosnmp.GetRequest:
+		for _, v := range req.Variables {
+			resp.Variables = append(resp.Variables, t.get(v.Name))
+		}
+	case gosnmp.GetNextRequest:
+		for _, v := range req.Variables {
+			resp.Variables = append(resp.Variables, t.next(v.Name))
+		}
+	case gosnmp.GetBulkRequest:
+		nonRepeaters, maxRepetitions, ok := bulkParams(msg, req)
+		if !ok {
+			log.Log(a).Debugf("Failed to decode GETBULK fields; using defaults")
+			nonRepeaters = int(req.NonRepeaters)
+			maxRepetitions = defaultMaxRepetitions
+		}
+		resp.Variables = bulk(t, req.Variables, nonRepeaters, maxRepetitions)
+	default:
+		resp.Error = gosnmp.NotWritable
+		resp.ErrorIndex = 1
+		resp.Variables = req.Variables
+	}
+	return resp
+}
+
+// bulk returns the variables answering a GETBULK request for the
+// specified variables, per RFC 3416 section 4.2.3.
+func bulk(t table, reqVars []gosnmp.SnmpPDU, nonRepeaters,
+	maxRepetitions int) []gosnmp.SnmpPDU {
+	if nonRepeaters < 0 {
+		nonRepeaters = 0
+	} else if nonRepeaters > len(reqVars) {
+		nonRepeaters = len(reqVars)
+	}
+	vars := []gosnmp.SnmpPDU{}
+	for _, v := range reqVars[:nonRepeaters] {
+		vars = append(vars, t.next(v.Name))
+	}
+
+	repeaters := make([]string, 0, len(reqVars)-nonRepeaters)
+	for _, v := range reqVars[nonRepeaters:] {
+		repeaters = append(repeaters, v.Name)
+	}
+	for i := 0; i < maxRepetitions && len(repeaters) > 0; i++ {
+		done := true
+		for j, name := range repeaters {
+			p := t.next(name)
+			vars = append(vars, p)
+			repeaters[j] = p.Name
+			if p.Type != gosnmp.EndOfMibView {
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+	}
+	return vars
+}
+
+// report returns a Report PDU for an SNMPv3 request, incrementing
+// and returning the specified USM statistic.
+func (a *Agent) report(req *gosnmp.SnmpPacket, oid string,
+	counter *uint32) *gosnmp.SnmpPacket {
+	usm := req.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	return &gosnmp.SnmpPacket{
+		Version:       gosnmp.Version3,
+		MsgFlags:      gosnmp.NoAuthNoPriv,
+		MsgID:         req.MsgID,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID:    a.engineID,
+			AuthoritativeEngineBoots: 1,
+			AuthoritativeEngineTime:  a.engineTime(),
+			UserName:                 usm.UserName,
+		},
+		ContextEngineID: a.engineID,
+		ContextName:     req.ContextName,
+		PDUType:         gosnmp.Report,
+		RequestID:       req.RequestID,
+		Variables: []gosnmp.SnmpPDU{{
+			Name:  oid,
+			Type:  gosnmp.Counter32,
+			Value: atomic.AddUint32(counter, 1),
+		}},
+	}
+}
+
+// secure fills in the SNMPv3 fields of the response to a request
+// from a known user, at the request's security level.
+func (a *Agent) secure(resp, req *gosnmp.SnmpPacket) {
+	resp.Version = gosnmp.Version3
+	resp.MsgFlags = req.MsgFlags &^ gosnmp.Reportable
+	resp.MsgID = req.MsgID
+	resp.SecurityModel = gosnmp.UserSecurityModel
+	resp.ContextEngineID = a.engineID
+	resp.ContextName = req.ContextName
+
+	// The request's security parameters hold the user's keys.
+	usm := req.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+
+	usm.AuthoritativeEngineBoots = 1
+	usm.AuthoritativeEngineTime = a.engineTime()
+	usm.AuthenticationParameters = ""
+	if usm.PrivacyProtocol > gosnmp.NoPriv {
+		// The salt must be unique per message. For DES, it starts
+		// with the engine boots.
+		salt := make([]byte, 8)
+		rand.Read(salt)
+		if usm.PrivacyProtocol == gosnmp.DES {
+			binary.BigEndian.PutUint32(salt, usm.AuthoritativeEngineBoots)
+		}
+		usm.PrivacyParameters = salt
+	}
+	resp.SecurityParameters = usm
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package agent
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+var testDump = `
+.1.3.6.1.2.1.1.1.0 = STRING: Arista Networks EOS
+.1.3.6.1.2.1.1.3.0 = Timeticks: (1000) 0:00:10.00
+.1.3.6.1.2.1.1.5.0 = STRING: switch1
+.1.3.6.1.2.1.2.2.1.2.1 = STRING: Ethernet1
+.1.3.6.1.2.1.2.2.1.2.2 = STRING: Ethernet2
+.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 100
+.1.3.6.1.2.1.2.2.1.10.2 = Counter32: 200
+.1.3.6.1.2.1.1.1.0 = STRING: Arista Networks EOS
+.1.3.6.1.2.1.1.3.0 = Timeticks: (2000) 0:00:20.00
+.1.3.6.1.2.1.1.5.0 = STRING: switch1
+.1.3.6.1.2.1.2.2.1.2.1 = STRING: Ethernet1
+.1.3.6.1.2.1.2.2.1.2.2 = STRING: Ethernet2
+.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 150
+.1.3.6.1.2.1.2.2.1.10.2 = Counter32: 250
+`
+
+func TestReadDump(t *testing.T) {
+	polls, err := ReadDump(strings.NewReader(testDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polls) != 2 {
+		t.Fatalf("Expected 2 polls, got %d", len(polls))
+	}
+	for i, p := range polls {
+		if len(p) != 7 {
+			t.Fatalf("Expected 7 PDUs in poll %d, got %d", i, len(p))
+		}
+	}
+	if v := polls[1][1].Value; v != uint32(2000) {
+		t.Fatalf("Expected sysUpTime 2000 in second poll, got %v", v)
+	}
+}
+
+func TestTable(t *testing.T) {
+	polls, err := ReadDump(strings.NewReader(testDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := newTable(polls[0])
+
+	for _, tc := range []struct {
+		name     string
+		get      gosnmp.SnmpPDU
+		nextName string
+	}{
+		{
+			name: ".1.3.6.1.2.1.1.5.0",
+			get: gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString,
+				Value: []byte("switch1")},
+			nextName: ".1.3.6.1.2.1.2.2.1.2.1",
+		},
+		{
+			// 10 sorts after 2 numerically.
+			name:     ".1.3.6.1.2.1.2.2.1.2.2",
+			get:      *polls[0][4],
+			nextName: ".1.3.6.1.2.1.2.2.1.10.1",
+		},
+		{
+			name:     ".1.3.6.1.2.1.1.5.1",
+			get:      gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1.5.1", Type: gosnmp.NoSuchInstance},
+			nextName: ".1.3.6.1.2.1.2.2.1.2.1",
+		},
+		{
+			name:     ".1.3.6.1.2.1.1",
+			get:      gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1", Type: gosnmp.NoSuchObject},
+			nextName: ".1.3.6.1.2.1.1.1.0",
+		},
+		{
+			name:     ".1.3.6.1.2.1.2.2.1.10.2",
+			get:      *polls[0][6],
+			nextName: ".1.3.6.1.2.1.2.2.1.10.2",
+		},
+	} {
+		if got := tbl.get(tc.name); !reflect.DeepEqual(got, tc.get) {
+			t.Errorf("get %s: expected %v, got %v", tc.name, tc.get, got)
+		}
+		if got := tbl.next(tc.name); got.Name != tc.nextName {
+			t.Errorf("next %s: expected %s, got %v", tc.name, tc.nextName, got)
+		}
+	}
+	if got := tbl.next(".1.3.6.1.2.1.2.2.1.10.2"); got.Type != gosnmp.EndOfMibView {
+		t.Errorf("Expected endOfMibView after last OID, got %v", got)
+	}
+
+	vars := bulk(tbl, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.3"},
+		{Name: ".1.3.6.1.2.1.2.2.1.2"},
+		{Name: ".1.3.6.1.2.1.2.2.1.10"},
+	}, 1, 3)
+	names := []string{}
+	for _, v := range vars {
+		names = append(names, v.Name)
+	}
+	expected := []string{
+		".1.3.6.1.2.1.1.3.0",
+		".1.3.6.1.2.1.2.2.1.2.1", ".1.3.6.1.2.1.2.2.1.10.1",
+		".1.3.6.1.2.1.2.2.1.2.2", ".1.3.6.1.2.1.2.2.1.10.2",
+		".1.3.6.1.2.1.2.2.1.10.1", ".1.3.6.1.2.1.2.2.1.10.2",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("GETBULK: expected %v, got %v", expected, names)
+	}
+}
+
+func newTestAgent(t *testing.T, cfg Config) *Agent {
+	polls, err := ReadDump(strings.NewReader(testDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New("127.0.0.1:0", polls, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go a.Serve()
+	return a
+}
+
+func client(t *testing.T, a *Agent, params gosnmp.GoSNMP) *gosnmp.GoSNMP {
+	params.Target = "127.0.0.1"
+	params.Port = uint16(a.Addr().(*net.UDPAddr).Port)
+	params.Timeout = 200 * time.Millisecond
+	params.Retries = 1
+	if err := params.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return &params
+}
+
+// checkPolls walks the agent with GETBULK and GETNEXT and checks
+// a GET, then moves the agent on and checks that it serves its next
+// poll.
+func checkPolls(t *testing.T, a *Agent, c *gosnmp.GoSNMP) {
+	for _, counter := range []uint{100, 150} {
+		for _, walk := range []func(string) ([]gosnmp.SnmpPDU, error){
+			c.BulkWalkAll, c.WalkAll,
+		} {
+			pdus, err := walk(".1.3.6.1.2.1.2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pdus) != 4 {
+				t.Fatalf("Expected 4 PDUs, got %v", pdus)
+			}
+			if pdus[2].Value != counter {
+				t.Fatalf("Expected counter value %d, got %v", counter, pdus[2])
+			}
+		}
+		// The agent should honor the request's non-repeaters and
+		// max-repetitions.
+		pkt, err := c.GetBulk([]string{".1.3.6.1.2.1.1.3", ".1.3.6.1.2.1.2"}, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pkt.Variables) != 3 || pkt.Variables[0].Name != ".1.3.6.1.2.1.1.3.0" {
+			t.Fatalf("Unexpected GETBULK response %v", pkt.Variables)
+		}
+		pkt, err = c.Get([]string{".1.3.6.1.2.1.1.5.0", ".1.3.6.1.2.1.1.6.0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(pkt.Variables[0].Value.([]byte)) != "switch1" ||
+			pkt.Variables[1].Type != gosnmp.NoSuchObject {
+			t.Fatalf("Unexpected GET response %v", pkt.Variables)
+		}
+		a.Advance()
+	}
+}
+
+func TestAgentV2c(t *testing.T) {
+	a := newTestAgent(t, Config{Community: "public"})
+	defer a.Close()
+
+	c := client(t, a, gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"})
+	defer c.Conn.Close()
+	checkPolls(t, a, c)
+
+	bad := client(t, a, gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "private"})
+	defer bad.Conn.Close()
+	if _, err := bad.Get([]string{".1.3.6.1.2.1.1.5.0"}); err == nil {
+		t.Fatal("Expected no response with wrong community")
+	}
+}
+
+// A GETBULK request's fields needn't be minimally encoded.
+func TestBulkParams(t *testing.T) {
+	msg := []byte{
+		0x30, 0x1c, // message
+		0x02, 0x01, 0x01, // version
+		0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c', // community
+		0xa5, 0x0f, // GetBulkRequest
+		0x02, 0x04, 0x00, 0x00, 0x00, 0x05, // request ID
+		0x02, 0x02, 0x00, 0x01, // non-repeaters
+		0x02, 0x01, 0x02, // max-repetitions
+		0x30, 0x00, // variable bindings
+	}
+	nonRepeaters, maxRepetitions, ok := bulkParams(msg,
+		&gosnmp.SnmpPacket{Version: gosnmp.Version2c})
+	if !ok || nonRepeaters != 1 || maxRepetitions != 2 {
+		t.Fatalf("Expected non-repeaters 1 and max-repetitions 2, got %d, %d (%v)",
+			nonRepeaters, maxRepetitions, ok)
+	}
+}
+
+func TestAgentV3(t *testing.T) {
+	users := []*gosnmp.UsmSecurityParameters{
+		{UserName: "noauth"},
+		{
+			UserName:                 "md5des",
+			AuthenticationProtocol:   gosnmp.MD5,
+			AuthenticationPassphrase: "authpass",
+			PrivacyProtocol:          gosnmp.DES,
+			PrivacyPassphrase:        "privpass",
+		},
+		{
+			UserName:                 "shaaes",
+			AuthenticationProtocol:   gosnmp.SHA,
+			AuthenticationPassphrase: "authpass",
+			PrivacyProtocol:          gosnmp.AES,
+			PrivacyPassphrase:        "privpass",
+		},
+		{
+			UserName:                 "sha256",
+			AuthenticationProtocol:   gosnmp.SHA256,
+			AuthenticationPassphrase: "authpass",
+		},
+		{
+			UserName:                 "sha1",
+			AuthenticationProtocol:   gosnmp.SHA,
+			AuthenticationPassphrase: "authpass",
+		},
+		{
+			UserName:                 "md5aes",
+			AuthenticationProtocol:   gosnmp.MD5,
+			AuthenticationPassphrase: "authpass",
+			PrivacyProtocol:          gosnmp.AES,
+			PrivacyPassphrase:        "privpass",
+		},
+		{
+			UserName:                 "sha512aes256",
+			AuthenticationProtocol:   gosnmp.SHA512,
+			AuthenticationPassphrase: "authpass",
+			PrivacyProtocol:          gosnmp.AES256,
+			PrivacyPassphrase:        "privpass",
+		},
+		{
+			UserName:                 "sha384aes192c",
+			AuthenticationProtocol:   gosnmp.SHA384,
+			AuthenticationPassphrase: "authpass",
+			PrivacyProtocol:          gosnmp.AES192C,
+			PrivacyPassphrase:        "privpass",
+		},
+	}
+	a := newTestAgent(t, Config{Users: users})
+	defer a.Close()
+
+	for _, u := range users {
+		t.Run(u.UserName, func(t *testing.T) {
+			flags := gosnmp.NoAuthNoPriv
+			if u.AuthenticationProtocol > gosnmp.NoAuth {
+				flags = gosnmp.AuthNoPriv
+			}
+			if u.PrivacyProtocol > gosnmp.NoPriv {
+				flags = gosnmp.AuthPriv
+			}
+			c := client(t, a, gosnmp.GoSNMP{
+				Version:            gosnmp.Version3,
+				SecurityModel:      gosnmp.UserSecurityModel,
+				MsgFlags:           flags,
+				SecurityParameters: u.Copy(),
+			})
+			defer c.Conn.Close()
+			checkPolls(t, a, c)
+		})
+	}
+
+	t.Run("wrongPassphrase", func(t *testing.T) {
+		params := users[2].Copy().(*gosnmp.UsmSecurityParameters)
+		params.AuthenticationPassphrase = "wrongpass"
+		c := client(t, a, gosnmp.GoSNMP{
+			Version:            gosnmp.Version3,
+			SecurityModel:      gosnmp.UserSecurityModel,
+			MsgFlags:           gosnmp.AuthPriv,
+			SecurityParameters: params,
+		})
+		defer c.Conn.Close()
+		if _, err := c.Get([]string{".1.3.6.1.2.1.1.5.0"}); err == nil {
+			t.Fatal("Expected no response with wrong passphrase")
+		}
+	})
+}
+
+func TestAgentRotation(t *testing.T) {
+	a := newTestAgent(t, Config{RotateInterval: 100 * time.Millisecond})
+	defer a.Close()
+	c := client(t, a, gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"})
+	defer c.Conn.Close()
+
+	seen := map[uint32]bool{}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(seen) < 2 && time.Now().Before(deadline) {
+		pkt, err := c.Get([]string{".1.3.6.1.2.1.1.3.0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[pkt.Variables[0].Value.(uint32)] = true
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !seen[1000] || !seen[2000] {
+		t.Fatalf("Expected the agent to serve both polls, saw %v", seen)
+	}
+}
+
+func TestReadMarkedDump(t *testing.T) {
+	dump := `POLL: 1
+WALK: .1.3.6.1.2.1.1
+.1.3.6.1.2.1.1.5.0 = STRING: "switch1"
+GET: .1.3.6.1.2.1.1.5.0
+.1.3.6.1.2.1.1.5.0 = STRING: "switch1"
+POLL: 2
+WALK: .1.3.6.1.2.1.1
+.1.3.6.1.2.1.1.5.0 = STRING: "switch2"
+GET: .1.3.6.1.2.1.1.5.0
+.1.3.6.1.2.1.1.5.0 = STRING: "switch2"
+`
+	polls, err := ReadDump(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polls) != 2 {
+		t.Fatalf("Expected 2 polls, got %d", len(polls))
+	}
+	for i, name := range []string{"switch1", "switch2"} {
+		p := newTable(polls[i]).get(".1.3.6.1.2.1.1.5.0")
+		if string(p.Value.([]byte)) != name {
+			t.Errorf("Expected %s in poll %d, got %v", name, i, p)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package agent
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/snmpoc"
+	"github.com/gosnmp/gosnmp"
+)
+
+// ReadDump reads a dump in the format written by gendump or
+// `snmpwalk -O ne`, gzipped or not, and returns the PDUs of each poll
+// it records. Polls start at poll markers, or in a dump without them,
+// each time the first PDU line of the dump recurs.
+func ReadDump(r io.Reader) ([][]*gosnmp.SnmpPDU, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to unzip dump: %v", err)
+		}
+		defer gz.Close()
+		br = bufio.NewReader(gz)
+	}
+
+	polls := [][]*gosnmp.SnmpPDU{}
+	var poll []*gosnmp.SnmpPDU
+	firstLine := ""
+	marked := false
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if snmpoc.IsPollString(line) {
+			marked = true
+			if poll != nil {
+				polls = append(polls, poll)
+				poll = nil
+			}
+			continue
+		}
+		pdus := snmpoc.PDUsFromString(line)
+		if len(pdus) == 0 {
+			continue
+		}
+		if !marked {
+			if firstLine == "" {
+				firstLine = line
+			} else if line == firstLine {
+				polls = append(polls, poll)
+				poll = nil
+			}
+		}
+		poll = append(poll, pdus...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if poll == nil {
+		return nil, errors.New("No PDUs in dump")
+	}
+	return append(polls, poll), nil
+}
+
+// LoadDump reads the polls of the specified dump file.
+func LoadDump(filename string) ([][]*gosnmp.SnmpPDU, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open dump file: %v", err)
+	}
+	defer f.Close()
+	return ReadDump(f)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package agent
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/pdu"
+	"github.com/gosnmp/gosnmp"
+)
+
+// oid is a parsed object identifier.
+type oid []uint32
+
+func parseOID(s string) (oid, error) {
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return oid{}, nil
+	}
+	parts := strings.Split(s, ".")
+	o := make(oid, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		o[i] = uint32(n)
+	}
+	return o, nil
+}
+
+// hasPrefix returns whether o is equal to or beneath prefix.
+func (o oid) hasPrefix(prefix oid) bool {
+	return len(o) >= len(prefix) && pdu.CompareOIDs(o[:len(prefix)], prefix) == 0
+}
+
+type entry struct {
+	oid oid
+	pdu gosnmp.SnmpPDU
+}
+
+// table is a set of PDUs in OID order, as an agent's MIB view.
+type table []entry
+
+// newTable returns a table of the specified PDUs. PDUs with
+// unparseable OIDs are dropped, and if an OID appears more than once,
+// the last PDU with that OID wins.
+func newTable(pdus []*gosnmp.SnmpPDU) table {
+	t := make(table, 0, len(pdus))
+	for _, p := range pdus {
+		o, err := parseOID(p.Name)
+		if err != nil {
+			continue
+		}
+		t = append(t, entry{oid: o, pdu: *p})
+	}
+	sort.SliceStable(t, func(i, j int) bool {
+		return pdu.CompareOIDs(t[i].oid, t[j].oid) < 0
+	})
+	deduped := t[:0]
+	for i, e := range t {
+		if i+1 < len(t) && pdu.CompareOIDs(t[i+1].oid, e.oid) == 0 {
+			continue
+		}
+		deduped = append(deduped, e)
+	}
+	return deduped
+}
+
+// search returns the index of the first entry not before o.
+func (t table) search(o oid) int {
+	return sort.Search(len(t), func(i int) bool {
+		return pdu.CompareOIDs(t[i].oid, o) >= 0
+	})
+}
+
+// get returns the PDU with the specified OID, or a noSuchInstance or
+// noSuchObject exception.
+func (t table) get(name string) gosnmp.SnmpPDU {
+	o, err := parseOID(name)
+	if err != nil {
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.NoSuchObject}
+	}
+	i := t.search(o)
+	if i < len(t) && pdu.CompareOIDs(t[i].oid, o) == 0 {
+		return t[i].pdu
+	}
+	// If the object exists but this instance doesn't, there's an
+	// instance of the same length under the OID with its last
+	// component removed.
+	if len(o) > 1 {
+		parent := o[:len(o)-1]
+		for j := t.search(parent); j < len(t) && t[j].oid.hasPrefix(parent); j++ {
+			if len(t[j].oid) == len(o) {
+				return gosnmp.SnmpPDU{Name: name, Type: gosnmp.NoSuchInstance}
+			}
+		}
+	}
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.NoSuchObject}
+}
+
+// next returns the first PDU after the specified OID, or an
+// endOfMibView exception.
+func (t table) next(name string) gosnmp.SnmpPDU {
+	o, err := parseOID(name)
+	if err != nil {
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.EndOfMibView}
+	}
+	i := t.search(o)
+	if i < len(t) && pdu.CompareOIDs(t[i].oid, o) == 0 {
+		i++
+	}
+	if i == len(t) {
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.EndOfMibView}
+	}
+	return t[i].pdu
+}
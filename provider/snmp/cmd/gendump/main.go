@@ -6,21 +6,55 @@ package main
 
 import (
 	"compress/gzip"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"os/exec"
 	"strings"
+	"time"
+
+	psnmp "github.com/aristanetworks/cloudvision-go/provider/snmp"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/snmpoc"
+	"github.com/gosnmp/gosnmp"
 )
 
+// The SNMP flags are named after the options of the snmp device,
+// except for -version, since glog's verbosity flag is -v.
 var (
-	community = flag.String("c", "", "SNMP community string")
-	dev       = flag.String("d", "", "Device hostname/IP")
-	dumpfile  = flag.String("o", "", "Name of file to write SNMP dump to")
-	oids      = oidFlags{}
-	polls     = flag.Int("n", 2, "Number of polls to perform")
+	dev      = flag.String("d", "", "Device hostname/IP")
+	dumpfile = flag.String("o", "", "Name of file to write SNMP dump to")
+	gets     = oidFlags{}
+	interval = flag.Duration("i", 0, "Time to wait between polls")
+	oids     = oidFlags{}
+	polls    = flag.Int("n", 2, "Number of polls to perform")
+
+	authProt = flag.String("a", "", "SNMPv3 authentication protocol "+
+		"(md5|sha|sha224|sha256|sha384|sha512)")
+	authKey         = flag.String("A", "", "SNMPv3 authentication key")
+	community       = flag.String("c", "", "SNMP community string")
+	contextEngineID = flag.String("contextEngineID", "",
+		"SNMPv3 context engine ID, in hex (default the authoritative engine ID)")
+	contextName = flag.String("contextName", "", "SNMPv3 context name")
+	engineID    = flag.String("engineID", "", "SNMPv3 authoritative engine ID "+
+		"of the device, in hex (discovered if empty)")
+	expTimeout = flag.Bool("exponentialTimeout", true,
+		"Double the SNMP timeout with each retry")
+	level = flag.String("l", "authPriv",
+		"SNMPv3 security level (noAuthNoPriv|authNoPriv|authPriv)")
+	maxReps = flag.Uint("maxRepetitions", 12,
+		"Max-repetitions value of SNMP GETBULK requests")
+	port     = flag.Uint("port", 161, "Device SNMP port to use")
+	privProt = flag.String("x", "", "SNMPv3 privacy protocol "+
+		"(des|aes|aes192|aes256|aes192c|aes256c)")
+	privKey   = flag.String("X", "", "SNMPv3 privacy key")
+	retries   = flag.Int("retries", 3, "Number of times to retry an SNMP request")
+	secName   = flag.String("u", "", "SNMPv3 security name")
+	timeout   = flag.Duration("timeout", 2*time.Second, "Time to wait for an SNMP response")
+	version   = flag.String("version", "2c", "SNMP version (2c|3)")
+	walkStrat = flag.String("walk", "bulk", "How to walk OIDs: with GETBULK "+
+		"or with GETNEXT (bulk|getnext)")
 )
 
 type oidFlags []string
@@ -36,25 +70,118 @@ func (o *oidFlags) Set(v string) error {
 
 func init() {
 	flag.Var(&oids, "oid", "OID to walk - may be repeated to specify multiple")
+	flag.Var(&gets, "get", "OID to get - may be repeated to specify multiple")
+}
+
+func hexFlag(name, value string) string {
+	b, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		log.Fatalf("Invalid hex value for -%s: %v", name, err)
+	}
+	return string(b)
 }
 
-func snmpWalkCmd(oid string) (string, []string) {
-	cmd := []string{"-O", "ne", "-Cc"}
-	if *community != "" {
-		cmd = append(cmd, "-c", *community)
+// snmpParams returns the parameters of an SNMP session with the
+// device, as specified by the flags.
+func snmpParams() *gosnmp.GoSNMP {
+	g := &gosnmp.GoSNMP{
+		Target:             *dev,
+		Port:               uint16(*port),
+		Community:          *community,
+		Retries:            *retries,
+		Timeout:            *timeout,
+		ExponentialTimeout: *expTimeout,
+		MaxOids:            gosnmp.MaxOids,
+		MaxRepetitions:     uint32(*maxReps),
+	}
+	switch *version {
+	case "2c":
+		g.Version = gosnmp.Version2c
+		return g
+	case "3":
+		g.Version = gosnmp.Version3
+	default:
+		log.Fatalf("Unknown SNMP version %q", *version)
+	}
+
+	usm := &gosnmp.UsmSecurityParameters{
+		UserName:                 *secName,
+		AuthenticationPassphrase: *authKey,
+		PrivacyPassphrase:        *privKey,
+		AuthoritativeEngineID:    hexFlag("engineID", *engineID),
+	}
+	switch *level {
+	case "noAuthNoPriv":
+		g.MsgFlags = gosnmp.NoAuthNoPriv
+	case "authNoPriv":
+		g.MsgFlags = gosnmp.AuthNoPriv
+	case "authPriv":
+		g.MsgFlags = gosnmp.AuthPriv
+	default:
+		log.Fatalf("Unknown SNMPv3 security level %q", *level)
+	}
+	if g.MsgFlags&gosnmp.AuthNoPriv != 0 {
+		p, ok := psnmp.AuthProtocols[strings.ToLower(*authProt)]
+		if !ok {
+			log.Fatalf("Unknown SNMPv3 authentication protocol %q", *authProt)
+		}
+		usm.AuthenticationProtocol = p
 	}
-	cmd = append(cmd, *dev, oid)
-	return "snmpbulkwalk", cmd
+	if g.MsgFlags&gosnmp.AuthPriv == gosnmp.AuthPriv {
+		p, ok := psnmp.PrivacyProtocols[strings.ToLower(*privProt)]
+		if !ok {
+			log.Fatalf("Unknown SNMPv3 privacy protocol %q", *privProt)
+		}
+		usm.PrivacyProtocol = p
+	}
+	g.SecurityModel = gosnmp.UserSecurityModel
+	g.SecurityParameters = usm
+	g.ContextName = *contextName
+	g.ContextEngineID = hexFlag("contextEngineID", *contextEngineID)
+	return g
 }
 
-func snmpWalk(f io.Writer) {
+func writePDU(w io.Writer, pdu gosnmp.SnmpPDU) error {
+	s := snmpoc.PDUToString(pdu)
+	if s == "" {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, s)
+	return err
+}
+
+func snmpWalk(g *gosnmp.GoSNMP, w io.Writer) {
+	walk := g.BulkWalk
+	if *walkStrat == "getnext" {
+		walk = g.Walk
+	}
 	for _, o := range oids {
-		c, args := snmpWalkCmd(o)
-		cmd := exec.Command(c, args...)
-		cmd.Stdout = f
 		fmt.Printf("Walking OID '%s'...\n", o)
-		if err := cmd.Run(); err != nil {
-			log.Fatalf("Walk command failed: %v", err)
+		if _, err := fmt.Fprintln(w, snmpoc.WalkString(o)); err != nil {
+			log.Fatalf("Failed to write dump: %v", err)
+		}
+		if err := walk(o, func(pdu gosnmp.SnmpPDU) error {
+			return writePDU(w, pdu)
+		}); err != nil {
+			log.Fatalf("Walk failed: %v", err)
+		}
+	}
+}
+
+func snmpGet(g *gosnmp.GoSNMP, w io.Writer) {
+	for _, o := range gets {
+		fmt.Printf("Getting OID '%s'...\n", o)
+		if _, err := fmt.Fprintln(w, snmpoc.GetString(o)); err != nil {
+			log.Fatalf("Failed to write dump: %v", err)
+		}
+		pkt, err := g.Get([]string{o})
+		if err != nil {
+			log.Fatalf("Get failed: %v", err)
+		}
+		for _, pdu := range pkt.Variables {
+			if err := writePDU(w, pdu); err != nil {
+				log.Fatalf("Failed to write dump: %v", err)
+			}
 		}
 	}
 }
@@ -70,10 +197,20 @@ func main() {
 		fmt.Println("-o must be specified")
 		os.Exit(1)
 	}
-	if len(oids) == 0 {
-		oids = []string{"."}
+	if *walkStrat != "bulk" && *walkStrat != "getnext" {
+		fmt.Println("-walk must be bulk or getnext")
+		os.Exit(1)
+	}
+	if len(oids) == 0 && len(gets) == 0 {
+		oids = []string{".1"}
 	}
 
+	g := snmpParams()
+	if err := g.Connect(); err != nil {
+		log.Fatalf("Failed to connect to device: %v", err)
+	}
+	defer g.Conn.Close()
+
 	f, err := os.Create(*dumpfile)
 	if err != nil {
 		log.Fatalf("Failed to open dumpfile: %v", err)
@@ -84,7 +221,14 @@ func main() {
 	gf.Header.Name = *dumpfile + ".gz"
 	defer gf.Close()
 
-	for i := 0; i < *polls; i++ {
-		snmpWalk(gf)
+	for i := 1; i <= *polls; i++ {
+		if i > 1 {
+			time.Sleep(*interval)
+		}
+		if _, err := fmt.Fprintln(gf, snmpoc.PollString(i)); err != nil {
+			log.Fatalf("Failed to write dump: %v", err)
+		}
+		snmpWalk(g, gf)
+		snmpGet(g, gf)
 	}
 }
@@ -0,0 +1,216 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+)
+
+var mibs = flag.String("m", "", "Comma-separated list of MIB files/directories "+
+	"to load on top of the bundled MIBs")
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: %s [flags] <command> [args]
+
+Commands:
+  translate OID...  Translate text OIDs to numeric ones and vice versa,
+                    keeping any instance suffix
+  show OBJECT...    Show the details of the specified objects
+  tree OBJECT       Show the tree of objects under the specified object
+  lint FILE|DIR...  Report MIB files that can't be parsed, imports from
+                    unknown modules, and OIDs that can't be resolved
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
+func loadMIBs() smi.Store {
+	var files []string
+	if *mibs != "" {
+		files = strings.Split(*mibs, ",")
+	}
+	store, err := smi.NewStore(files...)
+	if err != nil {
+		log.Fatalf("Failed to load MIBs: %v", err)
+	}
+	return store
+}
+
+func lookup(store smi.Store, oid string) *smi.Object {
+	o := store.GetObject(oid)
+	if o == nil {
+		log.Fatalf("Unknown object %q", oid)
+	}
+	return o
+}
+
+func isNumeric(oid string) bool {
+	return strings.Trim(oid, ".0123456789") == ""
+}
+
+// instanceSuffix returns the part of an OID following the OID or name
+// of the object it identifies, including the leading ".".
+func instanceSuffix(oid string, o *smi.Object) string {
+	oid = strings.TrimPrefix(oid, ".")
+	if i := strings.Index(oid, "::"); i >= 0 {
+		oid = oid[i+2:]
+	}
+	for _, prefix := range []string{o.Oid, o.Name} {
+		if strings.HasPrefix(oid, prefix+".") {
+			return oid[len(prefix):]
+		}
+	}
+	return ""
+}
+
+func translate(store smi.Store, oids []string) {
+	for _, oid := range oids {
+		o := lookup(store, oid)
+		if isNumeric(oid) {
+			fmt.Printf("%s::%s%s\n", o.Module, o.Name, instanceSuffix(oid, o))
+		} else {
+			fmt.Printf(".%s%s\n", o.Oid, instanceSuffix(oid, o))
+		}
+	}
+}
+
+func indexes(o *smi.Object) string {
+	var idx []string
+	implied := false
+	if o.Kind == smi.KindRow {
+		idx, implied = o.Indexes, o.Implied
+	} else if o.Kind == smi.KindColumn && o.Parent != nil {
+		idx, implied = o.Parent.Indexes, o.Parent.Implied
+	}
+	if len(idx) == 0 {
+		return ""
+	}
+	s := strings.Join(idx, ", ")
+	if implied {
+		s += " (last IMPLIED)"
+	}
+	return s
+}
+
+func show(store smi.Store, oids []string) {
+	for i, oid := range oids {
+		o := lookup(store, oid)
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Name:        %s::%s\n", o.Module, o.Name)
+		fmt.Printf("OID:         .%s\n", o.Oid)
+		fmt.Printf("Kind:        %s\n", o.Kind)
+		fmt.Printf("Access:      %s\n", o.Access)
+		fmt.Printf("Status:      %s\n", o.Status)
+		if o.Syntax != nil {
+			fmt.Printf("Syntax:      %s\n", o.Syntax)
+			if o.Syntax.DisplayHint != "" {
+				fmt.Printf("DisplayHint: %s\n", o.Syntax.DisplayHint)
+			}
+			if len(o.Syntax.Enums) > 0 {
+				enums := make([]string, len(o.Syntax.Enums))
+				for i, e := range o.Syntax.Enums {
+					enums[i] = fmt.Sprintf("%s(%d)", e.Name, e.Value)
+				}
+				fmt.Printf("Values:      %s\n", strings.Join(enums, ", "))
+			}
+		}
+		if idx := indexes(o); idx != "" {
+			fmt.Printf("Indexes:     %s\n", idx)
+		}
+		if len(o.Objects) > 0 {
+			fmt.Printf("Objects:     %s\n", strings.Join(o.Objects, ", "))
+		}
+		if o.Parent != nil {
+			fmt.Printf("Parent:      %s\n", o.Parent.Name)
+		}
+		if len(o.Children) > 0 {
+			children := make([]string, len(o.Children))
+			for i, c := range sortedChildren(o) {
+				children[i] = c.Name
+			}
+			fmt.Printf("Children:    %s\n", strings.Join(children, ", "))
+		}
+		if o.Description != "" {
+			fmt.Printf("Description: %s\n", o.Description)
+		}
+	}
+}
+
+func lastSubid(o *smi.Object) int {
+	subid, _ := strconv.Atoi(o.Oid[strings.LastIndex(o.Oid, ".")+1:])
+	return subid
+}
+
+func sortedChildren(o *smi.Object) []*smi.Object {
+	children := make([]*smi.Object, len(o.Children))
+	copy(children, o.Children)
+	sort.SliceStable(children, func(i, j int) bool {
+		return lastSubid(children[i]) < lastSubid(children[j])
+	})
+	return children
+}
+
+func tree(o *smi.Object, depth int) {
+	line := fmt.Sprintf("%s%s(%d)", strings.Repeat("  ", depth), o.Name, lastSubid(o))
+	if o.Kind != smi.KindObject {
+		line += " " + o.Kind.String()
+	}
+	if idx := indexes(o); idx != "" && o.Kind == smi.KindRow {
+		line += " [" + idx + "]"
+	}
+	fmt.Println(line)
+	for _, c := range sortedChildren(o) {
+		tree(c, depth+1)
+	}
+}
+
+func lint(files []string) {
+	problems, err := smi.Lint(files...)
+	if err != nil {
+		log.Fatalf("Failed to lint MIBs: %v", err)
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 2 || (args[0] == "tree" && len(args) != 2) {
+		usage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "translate":
+		translate(loadMIBs(), args[1:])
+	case "show":
+		show(loadMIBs(), args[1:])
+	case "tree":
+		store := loadMIBs()
+		tree(lookup(store, args[1]), 0)
+	case "lint":
+		lint(args[1:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
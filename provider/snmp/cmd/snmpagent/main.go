@@ -0,0 +1,93 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	psnmp "github.com/aristanetworks/cloudvision-go/provider/snmp"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/agent"
+	"github.com/gosnmp/gosnmp"
+)
+
+var (
+	address   = flag.String("l", "127.0.0.1:161", "UDP address to listen on")
+	community = flag.String("c", "", "SNMPv2c community string (any if empty)")
+	dumpfile  = flag.String("f", "", "Name of SNMP dump file to serve")
+	engineID  = flag.String("e", "", "SNMPv3 authoritative engine ID, in hex")
+	rotate    = flag.Duration("rotate", 0,
+		"Interval at which to move on to the next poll in the dump")
+
+	username = flag.String("u", "", "SNMPv3 user name")
+	authProt = flag.String("a", "", "SNMPv3 authentication protocol "+
+		"(MD5|SHA|SHA224|SHA256|SHA384|SHA512)")
+	authPass = flag.String("A", "", "SNMPv3 authentication passphrase")
+	privProt = flag.String("x", "", "SNMPv3 privacy protocol "+
+		"(DES|AES|AES192|AES256|AES192C|AES256C)")
+	privPass = flag.String("X", "", "SNMPv3 privacy passphrase")
+)
+
+func main() {
+	flag.Parse()
+
+	if *dumpfile == "" {
+		fmt.Println("-f must be specified")
+		os.Exit(1)
+	}
+
+	cfg := agent.Config{
+		Community:      *community,
+		RotateInterval: *rotate,
+	}
+	if *engineID != "" {
+		id, err := hex.DecodeString(strings.TrimPrefix(*engineID, "0x"))
+		if err != nil {
+			log.Fatalf("Invalid engine ID: %v", err)
+		}
+		cfg.EngineID = string(id)
+	}
+	if *username != "" {
+		auth, priv := gosnmp.NoAuth, gosnmp.NoPriv
+		if *authProt != "" {
+			p, ok := psnmp.AuthProtocols[strings.ToLower(*authProt)]
+			if !ok {
+				log.Fatalf("Unknown authentication protocol %q", *authProt)
+			}
+			auth = p
+		}
+		if *privProt != "" {
+			p, ok := psnmp.PrivacyProtocols[strings.ToLower(*privProt)]
+			if !ok {
+				log.Fatalf("Unknown privacy protocol %q", *privProt)
+			}
+			priv = p
+		}
+		cfg.Users = []*gosnmp.UsmSecurityParameters{{
+			UserName:                 *username,
+			AuthenticationProtocol:   auth,
+			AuthenticationPassphrase: *authPass,
+			PrivacyProtocol:          priv,
+			PrivacyPassphrase:        *privPass,
+		}}
+	}
+
+	polls, err := agent.LoadDump(*dumpfile)
+	if err != nil {
+		log.Fatalf("Failed to load dump: %v", err)
+	}
+	a, err := agent.New(*address, polls, cfg)
+	if err != nil {
+		log.Fatalf("Failed to start agent: %v", err)
+	}
+	fmt.Printf("Serving %d poll(s) from %s on %v\n", len(polls), *dumpfile, a.Addr())
+	if err := a.Serve(); err != nil {
+		log.Fatalf("Agent failed: %v", err)
+	}
+}
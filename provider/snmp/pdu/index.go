@@ -0,0 +1,200 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+)
+
+// IndexValue is the value of one of a row's indexes, decoded from the
+// instance portion of a columnar PDU's OID. Instance holds the OID
+// components encoding the index, which is the index's value in an
+// Index constraint. Value holds the index's value, typed as gosnmp
+// would type a PDU value of the index's syntax: int for INTEGER, uint
+// for Unsigned32, Gauge32, and Counter32, uint32 for TimeTicks, string
+// for IpAddress and OBJECT IDENTIFIER, and []byte for OCTET STRING and
+// BITS.
+type IndexValue struct {
+	Name     string
+	Instance string
+	Value    interface{}
+}
+
+var errTruncatedIndex = errors.New("Truncated index")
+
+// instance returns the instance portion of a columnar PDU's OID. The
+// PDU may be named by numeric or text OID.
+func instance(pdu *gosnmp.SnmpPDU, o *smi.Object) (string, error) {
+	name := strings.TrimPrefix(pdu.Name, ".")
+	if strings.HasPrefix(name, o.Oid+".") {
+		return name[len(o.Oid)+1:], nil
+	}
+	if i := strings.Index(name, o.Name+"."); i >= 0 {
+		return name[i+len(o.Name)+1:], nil
+	}
+	return "", fmt.Errorf("OID '%s' is not an instance of %s", pdu.Name, o.Name)
+}
+
+func instanceSubids(pdu *gosnmp.SnmpPDU, o *smi.Object) ([]uint32, error) {
+	inst, err := instance(pdu, o)
+	if err != nil {
+		return nil, err
+	}
+	ss := strings.Split(inst, ".")
+	subids := make([]uint32, len(ss))
+	for i, s := range ss {
+		v, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Bad index in OID '%s': %v", pdu.Name, err)
+		}
+		subids[i] = uint32(v)
+	}
+	return subids, nil
+}
+
+func subidString(subids []uint32) string {
+	ss := make([]string, len(subids))
+	for i, v := range subids {
+		ss[i] = strconv.FormatUint(uint64(v), 10)
+	}
+	return strings.Join(ss, ".")
+}
+
+func octets(subids []uint32) ([]byte, error) {
+	b := make([]byte, len(subids))
+	for i, v := range subids {
+		if v > 255 {
+			return nil, fmt.Errorf("Bad octet %d in string index", v)
+		}
+		b[i] = byte(v)
+	}
+	return b, nil
+}
+
+// stringLength returns the length of a string or OID index at the
+// start of subids, and the number of subidentifiers preceding it.
+// Per RFC 2578 section 7.7, a fixed-length string isn't preceded by
+// its length and neither is an IMPLIED index, which occupies the rest
+// of the instance OID.
+func stringLength(syntax *smi.Syntax, implied bool,
+	subids []uint32) (int, int, error) {
+	if n, ok := syntax.FixedSize(); ok && syntax.Base != smi.BaseObjectIdentifier {
+		return n, 0, nil
+	}
+	if implied {
+		return len(subids), 0, nil
+	}
+	if len(subids) == 0 {
+		return 0, 0, errTruncatedIndex
+	}
+	return int(subids[0]), 1, nil
+}
+
+// decodeIndex decodes the index at the start of subids, returning
+// its value and the number of subidentifiers it occupies. An index
+// of unknown syntax is assumed to occupy a single subidentifier.
+func decodeIndex(syntax *smi.Syntax, implied bool,
+	subids []uint32) (interface{}, int, error) {
+	base := smi.BaseUnknown
+	if syntax != nil {
+		base = syntax.Base
+	}
+	switch base {
+	case smi.BaseIPAddress:
+		if len(subids) < net.IPv4len {
+			return nil, 0, errTruncatedIndex
+		}
+		b, err := octets(subids[:net.IPv4len])
+		if err != nil {
+			return nil, 0, err
+		}
+		return net.IP(b).String(), net.IPv4len, nil
+	case smi.BaseOctetString, smi.BaseBits, smi.BaseOpaque,
+		smi.BaseObjectIdentifier:
+		n, start, err := stringLength(syntax, implied, subids)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(subids) < start+n {
+			return nil, 0, errTruncatedIndex
+		}
+		if base == smi.BaseObjectIdentifier {
+			return "." + subidString(subids[start:start+n]), start + n, nil
+		}
+		b, err := octets(subids[start : start+n])
+		if err != nil {
+			return nil, 0, err
+		}
+		return b, start + n, nil
+	}
+
+	if len(subids) == 0 {
+		return nil, 0, errTruncatedIndex
+	}
+	v := subids[0]
+	switch base {
+	case smi.BaseCounter32, smi.BaseGauge32, smi.BaseUnsigned32:
+		return uint(v), 1, nil
+	case smi.BaseTimeTicks:
+		return v, 1, nil
+	case smi.BaseCounter64, smi.BaseUnsigned64:
+		return uint64(v), 1, nil
+	case smi.BaseInteger64:
+		return int64(v), 1, nil
+	}
+	return int(v), 1, nil
+}
+
+func decodeIndexes(mibStore smi.Store, pdu *gosnmp.SnmpPDU,
+	o *smi.Object) ([]IndexValue, error) {
+	if o.Parent == nil || len(o.Parent.Indexes) == 0 {
+		return nil, fmt.Errorf("OID %s has no indexes", pdu.Name)
+	}
+	subids, err := instanceSubids(pdu, o)
+	if err != nil {
+		return nil, err
+	}
+	indexes := o.Parent.Indexes
+	values := make([]IndexValue, len(indexes))
+	for i, name := range indexes {
+		var syntax *smi.Syntax
+		if io := mibStore.GetObject(name); io != nil {
+			syntax = io.Syntax
+		}
+		implied := o.Parent.Implied && i == len(indexes)-1
+		v, n, err := decodeIndex(syntax, implied, subids)
+		if err != nil {
+			return nil, fmt.Errorf("Bad index '%s' in OID '%s': %v",
+				name, pdu.Name, err)
+		}
+		values[i] = IndexValue{
+			Name:     name,
+			Instance: subidString(subids[:n]),
+			Value:    v,
+		}
+		subids = subids[n:]
+	}
+	if len(subids) > 0 {
+		return nil, fmt.Errorf("Too many index components in OID '%s'", pdu.Name)
+	}
+	return values, nil
+}
+
+// DecodeIndexes returns the values of the indexes in the OID of the
+// specified columnar PDU, in the order the row declares them.
+func DecodeIndexes(mibStore smi.Store, pdu *gosnmp.SnmpPDU) ([]IndexValue, error) {
+	o := mibStore.GetObject(pdu.Name)
+	if o == nil {
+		return nil, fmt.Errorf("No object for OID '%s'", pdu.Name)
+	}
+	return decodeIndexes(mibStore, pdu, o)
+}
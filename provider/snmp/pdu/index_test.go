@@ -0,0 +1,199 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package pdu
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	lldpRemManAddrIfIDOid = "1.0.8802.1.1.2.1.4.2.1.4"
+	snmpTargetAddrTagOid  = "1.3.6.1.6.3.12.1.2.1.6"
+	inetCidrRouteIfIdxOid = "1.3.6.1.2.1.4.24.7.1.7"
+	ipAdEntIfIndexOid     = "1.3.6.1.2.1.4.20.1.2"
+)
+
+func TestDecodeIndexes(t *testing.T) {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error creating smi.Store: %s", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		oid      string
+		expected []IndexValue
+		err      error
+	}{
+		{
+			name: "integer index",
+			oid:  ifDescrOid + ".3",
+			expected: []IndexValue{
+				{Name: "ifIndex", Instance: "3", Value: 3},
+			},
+		},
+		{
+			name: "IpAddress index",
+			oid:  ipAdEntIfIndexOid + ".10.0.0.1",
+			expected: []IndexValue{
+				{Name: "ipAdEntAddr", Instance: "10.0.0.1", Value: "10.0.0.1"},
+			},
+		},
+		{
+			name: "variable-length string index",
+			oid:  lldpRemManAddrIfIDOid + ".0.5.1.1.4.10.0.0.1",
+			expected: []IndexValue{
+				{Name: "lldpRemTimeMark", Instance: "0", Value: uint32(0)},
+				{Name: "lldpRemLocalPortNum", Instance: "5", Value: 5},
+				{Name: "lldpRemIndex", Instance: "1", Value: 1},
+				{Name: "lldpRemManAddrSubtype", Instance: "1", Value: 1},
+				{Name: "lldpRemManAddr", Instance: "4.10.0.0.1",
+					Value: []byte{10, 0, 0, 1}},
+			},
+		},
+		{
+			name: "IPv6 InetAddress index",
+			oid: ipAddressIfIndexOid +
+				".2.16.32.1.13.184.0.0.0.0.0.0.0.0.0.0.0.1",
+			expected: []IndexValue{
+				{Name: "ipAddressAddrType", Instance: "2", Value: 2},
+				{Name: "ipAddressAddr",
+					Instance: "16.32.1.13.184.0.0.0.0.0.0.0.0.0.0.0.1",
+					Value: []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0,
+						0, 0, 0, 0, 0, 0, 0, 1}},
+			},
+		},
+		{
+			name: "IMPLIED string index",
+			oid:  snmpTargetAddrTagOid + ".104.111.115.116",
+			expected: []IndexValue{
+				{Name: "snmpTargetAddrName", Instance: "104.111.115.116",
+					Value: []byte("host")},
+			},
+		},
+		{
+			name: "OID index",
+			oid:  inetCidrRouteIfIdxOid + ".1.4.10.1.0.0.24.2.0.0.1.4.10.0.0.254",
+			expected: []IndexValue{
+				{Name: "inetCidrRouteDestType", Instance: "1", Value: 1},
+				{Name: "inetCidrRouteDest", Instance: "4.10.1.0.0",
+					Value: []byte{10, 1, 0, 0}},
+				{Name: "inetCidrRoutePfxLen", Instance: "24", Value: uint(24)},
+				{Name: "inetCidrRoutePolicy", Instance: "2.0.0", Value: ".0.0"},
+				{Name: "inetCidrRouteNextHopType", Instance: "1", Value: 1},
+				{Name: "inetCidrRouteNextHop", Instance: "4.10.0.0.254",
+					Value: []byte{10, 0, 0, 254}},
+			},
+		},
+		{
+			name: "truncated string index",
+			oid:  lldpRemManAddrIfIDOid + ".0.5.1.1.4.10.0",
+			err: errors.New("Bad index 'lldpRemManAddr' in OID '" +
+				lldpRemManAddrIfIDOid + ".0.5.1.1.4.10.0': Truncated index"),
+		},
+		{
+			name: "too many index components",
+			oid:  ifDescrOid + ".3.1",
+			err: errors.New("Too many index components in OID '" +
+				ifDescrOid + ".3.1'"),
+		},
+		{
+			name: "bad string octet",
+			oid:  snmpTargetAddrTagOid + ".104.256",
+			err: errors.New("Bad index 'snmpTargetAddrName' in OID '" +
+				snmpTargetAddrTagOid + ".104.256': Bad octet 256 in string index"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := DecodeIndexes(mibStore,
+				pdu(tc.oid, gosnmp.OctetString, ""))
+			checkError(t, err, tc.err)
+			if !reflect.DeepEqual(values, tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, values)
+			}
+		})
+	}
+}
+
+func TestMultiComponentIndexes(t *testing.T) {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error creating smi.Store: %s", err)
+	}
+	store, err := NewStore(mibStore)
+	if err != nil {
+		t.Fatalf("Error creating store: %s", err)
+	}
+
+	v4 := pdu(lldpRemManAddrIfIDOid+".0.5.1.1.4.10.0.0.1", gosnmp.Integer, 1)
+	v6 := pdu(lldpRemManAddrIfIDOid+
+		".0.5.1.2.16.32.1.13.184.0.0.0.0.0.0.0.0.0.0.0.1", gosnmp.Integer, 2)
+	other := pdu(lldpRemManAddrIfIDOid+".0.6.1.1.4.10.0.0.2", gosnmp.Integer, 3)
+	for _, p := range []*gosnmp.SnmpPDU{v4, v6, other} {
+		if err := store.Add(p); err != nil {
+			t.Fatalf("Error in Add: %s", err)
+		}
+	}
+
+	for _, tc := range []storeTestCase{
+		{
+			name: "constrain by string index",
+			get: testGet{
+				oid: lldpRemManAddrIfIDOid,
+				constraints: []Index{
+					{Name: "lldpRemManAddr", Value: "4.10.0.0.1"},
+				},
+				expectedPDUs: []*gosnmp.SnmpPDU{v4},
+			},
+		},
+		{
+			name: "constrain by integer index",
+			get: testGet{
+				oid: lldpRemManAddrIfIDOid,
+				constraints: []Index{
+					{Name: "lldpRemLocalPortNum", Value: "5"},
+				},
+				expectedPDUs: []*gosnmp.SnmpPDU{v4, v6},
+			},
+		},
+		{
+			name: "fully constrained",
+			get: testGet{
+				oid: lldpRemManAddrIfIDOid,
+				constraints: []Index{
+					{Name: "lldpRemTimeMark", Value: "0"},
+					{Name: "lldpRemLocalPortNum", Value: "5"},
+					{Name: "lldpRemIndex", Value: "1"},
+					{Name: "lldpRemManAddrSubtype", Value: "2"},
+					{Name: "lldpRemManAddr",
+						Value: "16.32.1.13.184.0.0.0.0.0.0.0.0.0.0.0.1"},
+				},
+				expectedPDUs: []*gosnmp.SnmpPDU{v6},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			runStoreTest(t, store, tc)
+		})
+	}
+
+	if v, err := IndexValueByName(mibStore, other, "lldpRemManAddr"); err != nil ||
+		v != "4.10.0.0.2" {
+		t.Errorf("Expected lldpRemManAddr 4.10.0.0.2, got %q (%v)", v, err)
+	}
+	if vs, err := IndexValues(mibStore, other); err != nil ||
+		!reflect.DeepEqual(vs, []string{"0", "6", "1", "1", "4.10.0.0.2"}) {
+		t.Errorf("Unexpected index values %v (%v)", vs, err)
+	}
+	bad := pdu("1.3.6.1.2.1.2.2.1.2.1.7", gosnmp.OctetString, "intf1")
+	if vs, err := IndexValues(mibStore, bad); err == nil {
+		t.Errorf("Expected error for bad index, got %v", vs)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package pdu
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+)
+
+// oid is a parsed numeric object identifier.
+type oid []uint32
+
+func parseOID(s string) (oid, error) {
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return oid{}, nil
+	}
+	parts := strings.Split(s, ".")
+	o := make(oid, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		o[i] = uint32(n)
+	}
+	return o, nil
+}
+
+// CompareOIDs returns -1, 0, or 1 as numeric OID a sorts before,
+// with, or after b in lexicographic OID order.
+func CompareOIDs(a, b []uint32) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] < b[i] {
+			return -1
+		} else if a[i] > b[i] {
+			return 1
+		}
+	}
+	if len(a) < len(b) {
+		return -1
+	} else if len(a) > len(b) {
+		return 1
+	}
+	return 0
+}
+
+// numericOID returns the numeric form of a text or numeric OID. A
+// text OID naming an object rather than an instance of one gives the
+// object's OID.
+func numericOID(mibStore smi.Store, name string) (oid, error) {
+	if o, err := parseOID(name); err == nil {
+		return o, nil
+	}
+	o := mibStore.GetObject(name)
+	if o == nil {
+		return nil, fmt.Errorf("No corresponding object in MIB store for OID %s", name)
+	}
+	if inst, err := instance(&gosnmp.SnmpPDU{Name: name}, o); err == nil {
+		return parseOID(o.Oid + "." + inst)
+	}
+	return parseOID(o.Oid)
+}
+
+// lessInstance reports whether instance a sorts before instance b,
+// in OID order if both are numeric.
+func lessInstance(a, b string) bool {
+	oa, erra := parseOID(a)
+	ob, errb := parseOID(b)
+	if erra != nil || errb != nil {
+		return a < b
+	}
+	return CompareOIDs(oa, ob) < 0
+}
+
+// sortedEntries returns the PDUs in a map keyed by instance, in
+// instance order.
+func sortedEntries(entries map[string]*gosnmp.SnmpPDU) []*gosnmp.SnmpPDU {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessInstance(keys[i], keys[j])
+	})
+	pdus := make([]*gosnmp.SnmpPDU, len(keys))
+	for i, k := range keys {
+		pdus[i] = entries[k]
+	}
+	return pdus
+}
+
+// orderedPDU is a PDU in a store's list of PDUs in OID order.
+type orderedPDU struct {
+	oid oid
+	pdu *gosnmp.SnmpPDU
+}
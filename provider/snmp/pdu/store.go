@@ -6,11 +6,12 @@ package pdu
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
-	"github.com/soniah/gosnmp"
+	"github.com/gosnmp/gosnmp"
 )
 
 // Index represents a constraint on a PDU query. It consists of a
@@ -20,6 +21,13 @@ type Index struct {
 	Value string
 }
 
+// A Row holds the PDUs of one row of a table by column name.
+// Instance is the index portion of their OIDs.
+type Row struct {
+	Instance string
+	Columns  map[string]*gosnmp.SnmpPDU
+}
+
 // Store is an interface for adding SNMP PDUs and flexibly querying
 // those stored PDUs.
 type Store interface {
@@ -27,6 +35,17 @@ type Store interface {
 	Clear() error
 	GetScalar(oid string) (*gosnmp.SnmpPDU, error)
 	GetTabular(oid string, indexes ...Index) ([]*gosnmp.SnmpPDU, error)
+	// GetByValue returns the PDUs of the specified column with the
+	// specified value, in instance order. Values are compared by
+	// their text form, with byte strings taken as strings.
+	GetByValue(oid string, value interface{}) ([]*gosnmp.SnmpPDU, error)
+	// GetNext returns the first PDU whose OID follows the specified
+	// OID in lexicographic order, or nil if there's none.
+	GetNext(oid string) (*gosnmp.SnmpPDU, error)
+	// GetRows returns the rows of the table containing the specified
+	// table, row, or column object, in instance order. Constraints
+	// on the table's indexes work as they do for GetTabular.
+	GetRows(oid string, indexes ...Index) ([]*Row, error)
 }
 
 // NewStore returns a new Store.
@@ -55,63 +74,97 @@ type indexStore struct {
 }
 
 // column stores columnar PDUs. It indexes those PDUs by full OID value
-// (in `entries`) and by index (`indexes`).
+// (in `entries`) and by index (`indexes`). They're indexed by value in
+// `values` only when looked up that way, and the index is dropped
+// whenever a PDU is added.
 type columnStore struct {
 	indexes map[string]*indexStore
 	entries map[string]*gosnmp.SnmpPDU
+	values  map[string]map[string]*gosnmp.SnmpPDU
 }
 
 // store implements the Store interface. It holds scalar data in its
 // `scalars` member, and columnar data is stored by column name in
-// `columns`.
+// `columns`. All the PDUs are listed in OID order in `order`, which
+// is rebuilt when needed after PDUs are added.
 type store struct {
 	scalars  map[string]*gosnmp.SnmpPDU
 	columns  map[string]*columnStore
+	order    []orderedPDU
 	mibStore smi.Store
 	lock     sync.RWMutex
 }
 
+// valueKey returns the key under which a PDU value is indexed.
+func valueKey(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
 func (s *store) addScalar(p *gosnmp.SnmpPDU, o *smi.Object) error {
 	s.scalars[o.Oid] = p
 	return nil
 }
 
-func indexValues(pdu *gosnmp.SnmpPDU, o *smi.Object) []string {
-	ss := strings.Split(pdu.Name, ".")
-	return ss[(len(ss) - len(o.Parent.Indexes)):]
+// indexValues returns the OID components encoding each of the
+// indexes of a columnar PDU.
+func indexValues(mibStore smi.Store, pdu *gosnmp.SnmpPDU,
+	o *smi.Object) ([]string, error) {
+	values, err := decodeIndexes(mibStore, pdu, o)
+	if err != nil {
+		return nil, err
+	}
+	ss := make([]string, len(values))
+	for i, v := range values {
+		ss[i] = v.Instance
+	}
+	return ss, nil
 }
 
 // IndexValues returns the index portion of the OID of the specified
-// PDU.
-func IndexValues(mibStore smi.Store, pdu *gosnmp.SnmpPDU) []string {
+// PDU, split into the OID components encoding each index. It fails if
+// the OID doesn't encode its object's indexes, as can happen with a
+// malformed or truncated OID from an agent.
+func IndexValues(mibStore smi.Store, pdu *gosnmp.SnmpPDU) ([]string, error) {
 	o := mibStore.GetObject(pdu.Name)
 	if o == nil {
-		return nil
+		return nil, fmt.Errorf("No object for OID '%s'", pdu.Name)
 	}
-	return indexValues(pdu, o)
+	return indexValues(mibStore, pdu, o)
 }
 
 // IndexValueByName returns the value of the index specified by
 // indexName in the OID of the provided PDU.
 func IndexValueByName(mibStore smi.Store, pdu *gosnmp.SnmpPDU,
 	indexName string) (string, error) {
-	// XXX TODO: Right now we assume that an index occupies a single
-	// OID component, i.e., does not span any periods in the OID.
-	// This assumption is not correct: String indexes, for example,
-	// may span many OID components. We need to teach this method
-	// to understand such cases.
 	o := mibStore.GetObject(pdu.Name)
 	if o == nil {
 		return "", fmt.Errorf("No object for OID '%s'", pdu.Name)
 	}
-	for i, iname := range o.Parent.Indexes {
-		if indexName == iname {
-			return indexValues(pdu, o)[i], nil
+	values, err := decodeIndexes(mibStore, pdu, o)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range values {
+		if v.Name == indexName {
+			return v.Instance, nil
 		}
 	}
 	return "", fmt.Errorf("No index '%s' for OID '%s'", indexName, pdu.Name)
 }
 
+// entryKey returns the key under which a columnar PDU is stored: the
+// full instance portion of its OID.
+func entryKey(pdu *gosnmp.SnmpPDU, o *smi.Object) string {
+	key, err := instance(pdu, o)
+	if err != nil {
+		return pdu.Name
+	}
+	return key
+}
+
 func (s *store) addTabular(p *gosnmp.SnmpPDU, o *smi.Object) error {
 	if o.Parent == nil {
 		return fmt.Errorf("OID %s has nil parent", p.Name)
@@ -119,7 +172,10 @@ func (s *store) addTabular(p *gosnmp.SnmpPDU, o *smi.Object) error {
 	if len(o.Parent.Indexes) == 0 {
 		return fmt.Errorf("OID %s has no indexes", p.Name)
 	}
-	indexVals := indexValues(p, o)
+	// A PDU whose indexes we can't decode, for instance because the
+	// MIB describing them is missing, is still available to
+	// unconstrained queries.
+	indexVals, _ := indexValues(s.mibStore, p, o)
 	col, ok := s.columns[o.Oid]
 	if !ok {
 		col = &columnStore{
@@ -129,8 +185,9 @@ func (s *store) addTabular(p *gosnmp.SnmpPDU, o *smi.Object) error {
 		s.columns[o.Oid] = col
 	}
 
-	allIndexes := strings.Join(indexVals, ".")
+	allIndexes := entryKey(p, o)
 	col.entries[allIndexes] = p
+	col.values = nil
 
 	for i, indexVal := range indexVals {
 		indexName := o.Parent.Indexes[i]
@@ -160,6 +217,7 @@ func (s *store) Add(p *gosnmp.SnmpPDU) error {
 	if o == nil {
 		return fmt.Errorf("No corresponding object in MIB store for OID %s", p.Name)
 	}
+	s.order = nil
 	switch o.Kind {
 	case smi.KindScalar:
 		return s.addScalar(p, o)
@@ -179,6 +237,7 @@ func (s *store) Clear() error {
 
 	s.scalars = make(map[string]*gosnmp.SnmpPDU)
 	s.columns = make(map[string]*columnStore)
+	s.order = nil
 	return nil
 }
 
@@ -271,7 +330,7 @@ func (s *store) getTabularPartiallyConstrained(o *smi.Object,
 		}
 		intersection := []*gosnmp.SnmpPDU{}
 		for _, p := range pdus {
-			allIndexes := strings.Join(indexValues(p, o), ".")
+			allIndexes := entryKey(p, o)
 			idx, ok := col.indexes[c.Name]
 			if !ok {
 				return nil, nil
@@ -287,43 +346,38 @@ func (s *store) getTabularPartiallyConstrained(o *smi.Object,
 	return pdus, nil
 }
 
-func (s *store) GetTabular(oid string, constraints ...Index) ([]*gosnmp.SnmpPDU, error) {
-	s.lock.RLock()
-	defer s.lock.RUnlock()
-
-	o := s.mibStore.GetObject(oid)
-	if o == nil {
-		return nil,
-			fmt.Errorf("No corresponding object in MIB store for OID %s", oid)
-	}
-	if o.Kind != smi.KindColumn {
-		return nil,
-			fmt.Errorf("Object for OID %s is not a scalar (%d)", oid, o.Kind)
-	}
-	if o.Parent == nil {
-		return nil, fmt.Errorf("No parent for OID %s", oid)
-	}
-	if len(constraints) > len(o.Parent.Indexes) {
-		return nil, fmt.Errorf("%d constraints is more than %d indexes",
-			len(constraints), len(o.Parent.Indexes))
+// checkConstraints checks that constraints apply to the specified
+// indexes of the object with the specified OID, replacing any OIDs
+// naming the indexes with their names.
+func (s *store) checkConstraints(oid string, indexes []string,
+	constraints []Index) error {
+	if len(constraints) > len(indexes) {
+		return fmt.Errorf("%d constraints is more than %d indexes",
+			len(constraints), len(indexes))
 	}
 	indexMap := make(map[string]bool)
-	for _, i := range o.Parent.Indexes {
+	for _, i := range indexes {
 		indexMap[i] = true
 	}
 	for i, c := range constraints {
 		co := s.mibStore.GetObject(c.Name)
 		if co == nil {
-			return nil, fmt.Errorf("Index '%s' not found in MIB store",
+			return fmt.Errorf("Index '%s' not found in MIB store",
 				c.Name)
 		}
 		constraints[i].Name = co.Name
 		if _, ok := indexMap[co.Name]; !ok {
-			return nil, fmt.Errorf("Invalid constraint '%s' for OID %s",
+			return fmt.Errorf("Invalid constraint '%s' for OID %s",
 				co.Name, oid)
 		}
 	}
+	return nil
+}
 
+// getTabular returns the PDUs of a column satisfying the specified
+// constraints, which must have been checked.
+func (s *store) getTabular(o *smi.Object,
+	constraints []Index) ([]*gosnmp.SnmpPDU, error) {
 	// Unconstrained
 	if len(constraints) == 0 {
 		return s.getTabularUnconstrained(o)
@@ -337,3 +391,186 @@ func (s *store) GetTabular(oid string, constraints ...Index) ([]*gosnmp.SnmpPDU,
 	// Partially constrained
 	return s.getTabularPartiallyConstrained(o, constraints...)
 }
+
+// column returns the columnar object with the specified OID.
+func (s *store) column(oid string) (*smi.Object, error) {
+	o := s.mibStore.GetObject(oid)
+	if o == nil {
+		return nil,
+			fmt.Errorf("No corresponding object in MIB store for OID %s", oid)
+	}
+	if o.Kind != smi.KindColumn {
+		return nil,
+			fmt.Errorf("Object for OID %s is not a column (%d)", oid, o.Kind)
+	}
+	if o.Parent == nil {
+		return nil, fmt.Errorf("No parent for OID %s", oid)
+	}
+	return o, nil
+}
+
+func (s *store) GetTabular(oid string, constraints ...Index) ([]*gosnmp.SnmpPDU, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	o, err := s.column(oid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkConstraints(oid, o.Parent.Indexes, constraints); err != nil {
+		return nil, err
+	}
+	return s.getTabular(o, constraints)
+}
+
+// byValue returns the column's PDUs indexed by value, indexing them
+// first if they've changed since they were last indexed.
+func (col *columnStore) byValue() map[string]map[string]*gosnmp.SnmpPDU {
+	if col.values != nil {
+		return col.values
+	}
+	values := make(map[string]map[string]*gosnmp.SnmpPDU)
+	for key, p := range col.entries {
+		vk := valueKey(p.Value)
+		if _, ok := values[vk]; !ok {
+			values[vk] = make(map[string]*gosnmp.SnmpPDU)
+		}
+		values[vk][key] = p
+	}
+	col.values = values
+	return values
+}
+
+func (s *store) GetByValue(oid string, value interface{}) ([]*gosnmp.SnmpPDU, error) {
+	// Indexing a column by value modifies the store.
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	o, err := s.column(oid)
+	if err != nil {
+		return nil, err
+	}
+	col, ok := s.columns[o.Oid]
+	if !ok {
+		return nil, nil
+	}
+	return sortedEntries(col.byValue()[valueKey(value)]), nil
+}
+
+// ordered returns the store's PDUs in OID order, listing them first
+// if they've changed since they were last listed. PDUs whose OIDs
+// can't be made numeric are left out.
+func (s *store) ordered() []orderedPDU {
+	if s.order != nil {
+		return s.order
+	}
+	order := []orderedPDU{}
+	add := func(p *gosnmp.SnmpPDU) {
+		if o, err := numericOID(s.mibStore, p.Name); err == nil {
+			order = append(order, orderedPDU{oid: o, pdu: p})
+		}
+	}
+	for _, p := range s.scalars {
+		add(p)
+	}
+	for _, col := range s.columns {
+		for _, p := range col.entries {
+			add(p)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return CompareOIDs(order[i].oid, order[j].oid) < 0
+	})
+	s.order = order
+	return order
+}
+
+func (s *store) GetNext(oid string) (*gosnmp.SnmpPDU, error) {
+	// Listing the PDUs in order modifies the store.
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	o, err := numericOID(s.mibStore, oid)
+	if err != nil {
+		return nil, err
+	}
+	order := s.ordered()
+	i := sort.Search(len(order), func(i int) bool {
+		return CompareOIDs(order[i].oid, o) > 0
+	})
+	if i == len(order) {
+		return nil, nil
+	}
+	return order[i].pdu, nil
+}
+
+// row returns the row object of the table containing the object
+// with the specified OID.
+func (s *store) row(oid string) (*smi.Object, error) {
+	o := s.mibStore.GetObject(oid)
+	if o == nil {
+		return nil,
+			fmt.Errorf("No corresponding object in MIB store for OID %s", oid)
+	}
+	switch o.Kind {
+	case smi.KindTable:
+		for _, c := range o.Children {
+			if c.Kind == smi.KindRow {
+				return c, nil
+			}
+		}
+	case smi.KindRow:
+		return o, nil
+	case smi.KindColumn:
+		if o.Parent != nil {
+			return o.Parent, nil
+		}
+	}
+	return nil, fmt.Errorf("Object for OID %s is not part of a table (%d)",
+		oid, o.Kind)
+}
+
+func (s *store) GetRows(oid string, constraints ...Index) ([]*Row, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	ro, err := s.row(oid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkConstraints(oid, ro.Indexes, constraints); err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]*Row)
+	for _, c := range ro.Children {
+		if c.Kind != smi.KindColumn {
+			continue
+		}
+		pdus, err := s.getTabular(c, constraints)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pdus {
+			key := entryKey(p, c)
+			r, ok := rows[key]
+			if !ok {
+				r = &Row{
+					Instance: key,
+					Columns:  make(map[string]*gosnmp.SnmpPDU),
+				}
+				rows[key] = r
+			}
+			r.Columns[c.Name] = p
+		}
+	}
+
+	sorted := make([]*Row, 0, len(rows))
+	for _, r := range rows {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return lessInstance(sorted[i].Instance, sorted[j].Instance)
+	})
+	return sorted, nil
+}
@@ -10,7 +10,7 @@ import (
 	"testing"
 
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
-	"github.com/soniah/gosnmp"
+	"github.com/gosnmp/gosnmp"
 )
 
 func pdusMatch(pdu, expected *gosnmp.SnmpPDU) bool {
@@ -120,6 +120,8 @@ const (
 	sysNameOid        = "1.3.6.1.2.1.1.5.0"
 	ifDescrOid        = "1.3.6.1.2.1.2.2.1.2"
 	lldpRemSysNameOid = "1.0.8802.1.1.2.1.4.1.1.9"
+
+	ipAddressIfIndexOid = "1.3.6.1.2.1.4.34.1.3"
 )
 
 func ifDescrPDU(i, d string) *gosnmp.SnmpPDU {
@@ -135,6 +137,10 @@ func lldpRemSysNamePDU(l, r, name string) *gosnmp.SnmpPDU {
 		gosnmp.OctetString, name)
 }
 
+func ipAddressIfIndexPDU(index string, ifIndex int) *gosnmp.SnmpPDU {
+	return pdu(ipAddressIfIndexOid+"."+index, gosnmp.Integer, ifIndex)
+}
+
 func TestStore(t *testing.T) {
 	mibStore, err := smi.NewStore("../smi/mibs")
 	if err != nil {
@@ -282,6 +288,21 @@ func TestStore(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "multi-component index values",
+			clear: true,
+			adds: []*gosnmp.SnmpPDU{
+				ipAddressIfIndexPDU("1.4.10.0.0.1", 1),
+				ipAddressIfIndexPDU("2.16.0.0.0.0.0.0.0.0.0.0.0.0.0.10.0.0.1", 2),
+			},
+			get: testGet{
+				oid: ipAddressIfIndexOid,
+				expectedPDUs: []*gosnmp.SnmpPDU{
+					ipAddressIfIndexPDU("1.4.10.0.0.1", 1),
+					ipAddressIfIndexPDU("2.16.0.0.0.0.0.0.0.0.0.0.0.0.0.10.0.0.1", 2),
+				},
+			},
+		},
 		{
 			name:  "PDU not added scalar",
 			clear: true,
@@ -386,3 +407,297 @@ func TestStore(t *testing.T) {
 		})
 	}
 }
+
+func ifMtuPDU(i string, mtu int) *gosnmp.SnmpPDU {
+	return pdu("1.3.6.1.2.1.2.2.1.4."+i, gosnmp.Integer, mtu)
+}
+
+func newTestStore(t *testing.T, adds ...*gosnmp.SnmpPDU) Store {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error creating smi.Store: %s", err)
+	}
+	store, err := NewStore(mibStore)
+	if err != nil {
+		t.Fatalf("Error creating store: %s", err)
+	}
+	for _, p := range adds {
+		if err := store.Add(p); err != nil {
+			t.Fatalf("Error in Add: %s", err)
+		}
+	}
+	return store
+}
+
+func checkOrderedPDUs(t *testing.T, pdus, expected []*gosnmp.SnmpPDU) {
+	if len(pdus) != len(expected) {
+		t.Fatalf("got %d PDUs, expected %d", len(pdus), len(expected))
+	}
+	for i, e := range expected {
+		if !pdusMatch(pdus[i], e) {
+			t.Fatalf("expected %v at %d, got %v", e, i, pdus[i])
+		}
+	}
+}
+
+func TestGetByValue(t *testing.T) {
+	store := newTestStore(t,
+		ifDescrPDU("10", "eth"),
+		ifDescrPDU("2", "eth"),
+		ifDescrPDU("3", "lo"),
+		ifMtuPDU("2", 1500),
+		ifMtuPDU("3", 9000),
+		pdu("1.3.6.1.2.1.2.2.1.2.4", gosnmp.OctetString, []byte("mgmt")),
+	)
+
+	// Replacing a PDU replaces its value.
+	if err := store.Add(ifDescrPDU("3", "eth")); err != nil {
+		t.Fatalf("Error in Add: %s", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		oid      string
+		value    interface{}
+		expected []*gosnmp.SnmpPDU
+		err      error
+	}{
+		{
+			name:  "string",
+			oid:   "ifDescr",
+			value: "eth",
+			expected: []*gosnmp.SnmpPDU{
+				ifDescrPDU("2", "eth"),
+				ifDescrPDU("3", "eth"),
+				ifDescrPDU("10", "eth"),
+			},
+		},
+		{
+			name:     "replaced value",
+			oid:      ifDescrOid,
+			value:    "lo",
+			expected: []*gosnmp.SnmpPDU{},
+		},
+		{
+			name:  "byte string",
+			oid:   "ifDescr",
+			value: "mgmt",
+			expected: []*gosnmp.SnmpPDU{
+				pdu("1.3.6.1.2.1.2.2.1.2.4", gosnmp.OctetString, "mgmt"),
+			},
+		},
+		{
+			name:     "integer",
+			oid:      "ifMtu",
+			value:    9000,
+			expected: []*gosnmp.SnmpPDU{ifMtuPDU("3", 9000)},
+		},
+		{
+			name:  "empty column",
+			oid:   "ifSpeed",
+			value: 0,
+		},
+		{
+			name:  "scalar",
+			oid:   "sysName",
+			value: "foo",
+			err: errors.New("Object for OID sysName is not a column " +
+				fmt.Sprintf("(%d)", smi.KindScalar)),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pdus, err := store.GetByValue(tc.oid, tc.value)
+			checkError(t, err, tc.err)
+			// Byte strings don't compare equal, so compare them as
+			// strings.
+			for i, p := range pdus {
+				if b, ok := p.Value.([]byte); ok {
+					pdus[i] = pdu(p.Name, p.Type, string(b))
+				}
+			}
+			checkOrderedPDUs(t, pdus, tc.expected)
+		})
+	}
+
+	// Adding a PDU after a lookup by value is seen by the next one.
+	if err := store.Add(ifDescrPDU("5", "lo")); err != nil {
+		t.Fatalf("Error in Add: %s", err)
+	}
+	pdus, err := store.GetByValue("ifDescr", "lo")
+	checkError(t, err, nil)
+	checkOrderedPDUs(t, pdus, []*gosnmp.SnmpPDU{ifDescrPDU("5", "lo")})
+}
+
+func TestGetNext(t *testing.T) {
+	store := newTestStore(t,
+		ifDescrPDU("10", "intf10"),
+		ifDescrPDU("2", "intf2"),
+		ifMtuPDU("2", 1500),
+		sysNamePDU("device123"),
+	)
+
+	for _, tc := range []struct {
+		name     string
+		oid      string
+		expected *gosnmp.SnmpPDU
+		err      error
+	}{
+		{
+			name:     "start",
+			oid:      "1",
+			expected: sysNamePDU("device123"),
+		},
+		{
+			name:     "scalar to column",
+			oid:      sysNameOid,
+			expected: ifDescrPDU("2", "intf2"),
+		},
+		{
+			name:     "numeric instance order",
+			oid:      ifDescrOid + ".2",
+			expected: ifDescrPDU("10", "intf10"),
+		},
+		{
+			name:     "next column",
+			oid:      ifDescrOid + ".10",
+			expected: ifMtuPDU("2", 1500),
+		},
+		{
+			name:     "text OID",
+			oid:      "ifDescr",
+			expected: ifDescrPDU("2", "intf2"),
+		},
+		{
+			name:     "text OID with instance",
+			oid:      "ifDescr.2",
+			expected: ifDescrPDU("10", "intf10"),
+		},
+		{
+			name: "end",
+			oid:  ifMtuPDU("2", 1500).Name,
+		},
+		{
+			name: "unknown text OID",
+			oid:  "fooBar",
+			err:  errors.New("No corresponding object in MIB store for OID fooBar"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := store.GetNext(tc.oid)
+			checkError(t, err, tc.err)
+			if p == nil || tc.expected == nil {
+				if p != tc.expected {
+					t.Fatalf("expected %v, got %v", tc.expected, p)
+				}
+				return
+			}
+			if !pdusMatch(p, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, p)
+			}
+		})
+	}
+
+	// The order reflects PDUs added later.
+	if err := store.Add(ifDescrPDU("3", "intf3")); err != nil {
+		t.Fatalf("Error in Add: %s", err)
+	}
+	p, err := store.GetNext(ifDescrOid + ".2")
+	checkError(t, err, nil)
+	if !pdusMatch(p, ifDescrPDU("3", "intf3")) {
+		t.Fatalf("expected %v, got %v", ifDescrPDU("3", "intf3"), p)
+	}
+}
+
+func TestGetRows(t *testing.T) {
+	store := newTestStore(t,
+		ifDescrPDU("10", "intf10"),
+		ifDescrPDU("2", "intf2"),
+		ifMtuPDU("2", 1500),
+		lldpRemSysNamePDU("1", "5", "remote1"),
+		lldpRemSysNamePDU("2", "6", "remote2"),
+	)
+
+	type row struct {
+		instance string
+		columns  map[string]*gosnmp.SnmpPDU
+	}
+	for _, tc := range []struct {
+		name        string
+		oid         string
+		constraints []Index
+		expected    []row
+		err         error
+	}{
+		{
+			name: "table",
+			oid:  "ifTable",
+			expected: []row{
+				{"2", map[string]*gosnmp.SnmpPDU{
+					"ifDescr": ifDescrPDU("2", "intf2"),
+					"ifMtu":   ifMtuPDU("2", 1500),
+				}},
+				{"10", map[string]*gosnmp.SnmpPDU{
+					"ifDescr": ifDescrPDU("10", "intf10"),
+				}},
+			},
+		},
+		{
+			name:        "row with constraint",
+			oid:         "ifEntry",
+			constraints: []Index{{Name: "ifIndex", Value: "2"}},
+			expected: []row{
+				{"2", map[string]*gosnmp.SnmpPDU{
+					"ifDescr": ifDescrPDU("2", "intf2"),
+					"ifMtu":   ifMtuPDU("2", 1500),
+				}},
+			},
+		},
+		{
+			name:        "column with partial constraint",
+			oid:         lldpRemSysNameOid,
+			constraints: []Index{{Name: "lldpRemLocalPortNum", Value: "2"}},
+			expected: []row{
+				{"42.2.6", map[string]*gosnmp.SnmpPDU{
+					"lldpRemSysName": lldpRemSysNamePDU("2", "6", "remote2"),
+				}},
+			},
+		},
+		{
+			name:        "invalid constraint",
+			oid:         "ifTable",
+			constraints: []Index{{Name: "lldpRemIndex", Value: "1"}},
+			err:         errors.New("Invalid constraint 'lldpRemIndex' for OID ifTable"),
+		},
+		{
+			name: "not a table",
+			oid:  "sysName",
+			err: errors.New("Object for OID sysName is not part of a table " +
+				fmt.Sprintf("(%d)", smi.KindScalar)),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := store.GetRows(tc.oid, tc.constraints...)
+			checkError(t, err, tc.err)
+			if len(rows) != len(tc.expected) {
+				t.Fatalf("got %d rows, expected %d", len(rows), len(tc.expected))
+			}
+			for i, e := range tc.expected {
+				r := rows[i]
+				if r.Instance != e.instance {
+					t.Fatalf("expected instance %s at %d, got %s",
+						e.instance, i, r.Instance)
+				}
+				if len(r.Columns) != len(e.columns) {
+					t.Fatalf("got %d columns in row %s, expected %d",
+						len(r.Columns), r.Instance, len(e.columns))
+				}
+				for name, p := range e.columns {
+					if c, ok := r.Columns[name]; !ok || !pdusMatch(c, p) {
+						t.Fatalf("expected %v in column %s, got %v",
+							p, name, c)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// WalkStrategy is the way in which the provider walks the device's
+// MIB tables.
+type WalkStrategy int
+
+const (
+	// WalkBulk walks with GETBULK requests.
+	WalkBulk WalkStrategy = iota
+	// WalkGetNext walks with GETNEXT requests, for agents that don't
+	// handle GETBULK.
+	WalkGetNext
+	// WalkAuto walks with GETBULK requests, falling back to GETNEXT
+	// for good if the agent fails GETBULK requests that it answers
+	// with GETNEXT.
+	WalkAuto
+)
+
+// rateLimiter spaces out requests so that no more than a given
+// number are sent per second.
+type rateLimiter struct {
+	lock     sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(maxRate float64) *rateLimiter {
+	if maxRate <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / maxRate)}
+}
+
+// wait blocks until the next request may be sent.
+func (r *rateLimiter) wait() {
+	r.lock.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	d := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	r.lock.Unlock()
+	time.Sleep(d)
+}
+
+// sessionPool is a set of gosnmp sessions to a device. gosnmp can't
+// handle parallel requests on one session, so each request checks out
+// a session for its duration, and the number of sessions bounds the
+// number of requests in flight. All sessions share one rate limit.
+type sessionPool struct {
+	all      []*gosnmp.GoSNMP
+	sessions chan *gosnmp.GoSNMP
+
+	// strategy is how the pool walks, and getNext is set once an
+	// automatic walk has fallen back to GETNEXT.
+	strategy WalkStrategy
+	getNext  int32
+}
+
+// newSessionPool returns a pool of size sessions with the specified
+// parameters, sending at most maxRate requests per second in total,
+// or any number if maxRate is zero, and walking with the specified
+// strategy.
+func newSessionPool(params *gosnmp.GoSNMP, size int, maxRate float64,
+	strategy WalkStrategy) *sessionPool {
+	if size < 1 {
+		size = 1
+	}
+	limiter := newRateLimiter(maxRate)
+	p := &sessionPool{
+		sessions: make(chan *gosnmp.GoSNMP, size),
+		strategy: strategy,
+	}
+	for i := 0; i < size; i++ {
+		s := *params
+		if params.SecurityParameters != nil {
+			s.SecurityParameters = params.SecurityParameters.Copy()
+		}
+		if limiter != nil {
+			s.PreSend = func(*gosnmp.GoSNMP) {
+				limiter.wait()
+			}
+		}
+		p.all = append(p.all, &s)
+		p.sessions <- &s
+	}
+	return p
+}
+
+// connect connects each of the pool's sessions.
+func (p *sessionPool) connect() error {
+	for _, s := range p.all {
+		if err := s.Connect(); err != nil {
+			p.close()
+			return err
+		}
+	}
+	return nil
+}
+
+// close closes each of the pool's connected sessions.
+func (p *sessionPool) close() {
+	for _, s := range p.all {
+		if s.Conn != nil {
+			s.Conn.Close()
+		}
+	}
+}
+
+func (p *sessionPool) get(oids []string) (*gosnmp.SnmpPacket, error) {
+	s := <-p.sessions
+	defer func() { p.sessions <- s }()
+	return s.Get(oids)
+}
+
+func (p *sessionPool) walk(rootOid string, walkFn gosnmp.WalkFunc) error {
+	s := <-p.sessions
+	defer func() { p.sessions <- s }()
+	switch {
+	case p.strategy == WalkGetNext, atomic.LoadInt32(&p.getNext) == 1:
+		return s.Walk(rootOid, walkFn)
+	case p.strategy == WalkBulk:
+		return s.BulkWalk(rootOid, walkFn)
+	}
+
+	// Walk automatically: try GETBULK, and if the agent returns an
+	// error, or nothing (gosnmp ends a walk quietly on an error
+	// status), walk again with GETNEXT, skipping the PDUs already
+	// seen. If GETNEXT does better, use it from now on.
+	n := 0
+	var fnErr error
+	err := s.BulkWalk(rootOid, func(pdu gosnmp.SnmpPDU) error {
+		n++
+		fnErr = walkFn(pdu)
+		return fnErr
+	})
+	if fnErr != nil || (err == nil && n > 0) {
+		return err
+	}
+	skip, m := n, 0
+	if werr := s.Walk(rootOid, func(pdu gosnmp.SnmpPDU) error {
+		m++
+		if m <= skip {
+			return nil
+		}
+		return walkFn(pdu)
+	}); werr != nil {
+		return err
+	}
+	if err != nil || m > 0 {
+		atomic.StoreInt32(&p.getNext, 1)
+	}
+	return nil
+}
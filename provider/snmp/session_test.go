@@ -0,0 +1,188 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmp
+
+import (
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if newRateLimiter(0) != nil {
+		t.Fatal("Expected no rate limiter for rate 0")
+	}
+
+	r := newRateLimiter(100)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		r.wait()
+	}
+	// The first request goes out right away and the rest follow at
+	// 10ms intervals.
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("Expected six requests to take at least 50ms, took %v", elapsed)
+	}
+}
+
+func TestSessionPool(t *testing.T) {
+	params := &gosnmp.GoSNMP{
+		Target:             "192.0.2.1",
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{UserName: "user"},
+	}
+	p := newSessionPool(params, 3, 10, WalkBulk)
+	if len(p.all) != 3 {
+		t.Fatalf("Expected 3 sessions, got %d", len(p.all))
+	}
+	for i, s := range p.all {
+		if s.Target != params.Target {
+			t.Fatalf("Session %d has target %s", i, s.Target)
+		}
+		if s.SecurityParameters == params.SecurityParameters {
+			t.Fatalf("Session %d shares security parameters with the template", i)
+		}
+		if s.PreSend == nil {
+			t.Fatalf("Session %d is not rate-limited", i)
+		}
+	}
+
+	// Only as many requests as there are sessions may be in flight.
+	for i := 0; i < 3; i++ {
+		<-p.sessions
+	}
+	select {
+	case <-p.sessions:
+		t.Fatal("Checked out more sessions than the pool has")
+	default:
+	}
+
+	if newSessionPool(params, 0, 0, WalkBulk).all[0].PreSend != nil {
+		t.Fatal("Expected no rate limit")
+	}
+}
+
+// getNextAgent answers GETNEXT requests from a table of PDUs, and
+// GETBULK requests with genErr, like agents that don't support GETBULK.
+type getNextAgent struct {
+	conn  net.PacketConn
+	pdus  []gosnmp.SnmpPDU
+	bulks int32
+}
+
+func newGetNextAgent(t *testing.T, pdus []gosnmp.SnmpPDU) *getNextAgent {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &getNextAgent{conn: conn, pdus: pdus}
+	go a.serve()
+	return a
+}
+
+func (a *getNextAgent) serve() {
+	decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c}
+	buf := make([]byte, 65536)
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req, err := decoder.SnmpDecodePacket(buf[:n])
+		if err != nil || len(req.Variables) == 0 {
+			continue
+		}
+		resp := &gosnmp.SnmpPacket{
+			Version:   req.Version,
+			Community: req.Community,
+			PDUType:   gosnmp.GetResponse,
+			RequestID: req.RequestID,
+		}
+		if req.PDUType == gosnmp.GetBulkRequest {
+			atomic.AddInt32(&a.bulks, 1)
+			resp.Error = gosnmp.GenErr
+			resp.ErrorIndex = 1
+			resp.Variables = req.Variables
+		} else {
+			resp.Variables = []gosnmp.SnmpPDU{a.next(req.Variables[0].Name)}
+		}
+		b, err := resp.MarshalMsg()
+		if err != nil {
+			continue
+		}
+		a.conn.WriteTo(b, addr)
+	}
+}
+
+// next returns the PDU following oid, or endOfMibView.
+func (a *getNextAgent) next(oid string) gosnmp.SnmpPDU {
+	if strings.HasPrefix(a.pdus[0].Name, oid+".") {
+		return a.pdus[0]
+	}
+	for i := 0; i+1 < len(a.pdus); i++ {
+		if a.pdus[i].Name == oid {
+			return a.pdus[i+1]
+		}
+	}
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+}
+
+func TestWalkStrategy(t *testing.T) {
+	pdus := []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("a")},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(100)},
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("b")},
+	}
+	for _, tc := range []struct {
+		name     string
+		strategy WalkStrategy
+		expected int
+		bulks    int32
+	}{
+		{name: "bulk", strategy: WalkBulk, expected: 0, bulks: 2},
+		{name: "getNext", strategy: WalkGetNext, expected: 3, bulks: 0},
+		{name: "auto", strategy: WalkAuto, expected: 3, bulks: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newGetNextAgent(t, pdus)
+			defer a.conn.Close()
+			params := &gosnmp.GoSNMP{
+				Target:    "127.0.0.1",
+				Port:      uint16(a.conn.LocalAddr().(*net.UDPAddr).Port),
+				Community: "public",
+				Version:   gosnmp.Version2c,
+				Timeout:   time.Second,
+			}
+			p := newSessionPool(params, 1, 0, tc.strategy)
+			if err := p.connect(); err != nil {
+				t.Fatal(err)
+			}
+			defer p.close()
+
+			// Walk twice to check that an automatic walk sticks with
+			// GETNEXT once GETBULK has failed.
+			for i := 0; i < 2; i++ {
+				n := 0
+				if err := p.walk(".1.3.6.1.2.1.1", func(gosnmp.SnmpPDU) error {
+					n++
+					return nil
+				}); err != nil {
+					t.Fatal(err)
+				}
+				if n != tc.expected {
+					t.Fatalf("Walk %d: expected %d PDUs, got %d", i, tc.expected, n)
+				}
+			}
+			if bulks := atomic.LoadInt32(&a.bulks); bulks != tc.bulks {
+				t.Fatalf("Expected %d GETBULK requests, got %d", tc.bulks, bulks)
+			}
+		})
+	}
+}
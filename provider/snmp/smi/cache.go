@@ -0,0 +1,368 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package smi
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi/mibs"
+)
+
+// cacheVersion is the version of the on-disk cache format. It must
+// change whenever the cached representation of a Store does.
+const cacheVersion = 4
+
+// A sharedStore is the Store for a set of MIB files, which is ready
+// once done is closed. Callers wanting the same Store wait for the
+// first to finish loading it rather than holding up everyone else.
+type sharedStore struct {
+	fingerprint string
+	done        chan struct{}
+	store       Store
+	err         error
+}
+
+var (
+	sharedStoresLock sync.Mutex
+	sharedStores     = make(map[string]*sharedStore)
+
+	bundledHashOnce sync.Once
+	bundledHash     string
+)
+
+// SharedStore returns a lenient Store for the specified MIB files and
+// directories, parsing them only if there's no Store for the same
+// set of files yet or the files have changed since it was created,
+// so that all users of the same MIBs share one Store. If cacheDir
+// isn't empty, parsed Stores are also saved to that directory and
+// loaded from it in preference to parsing the files again. The
+// returned Store must not be modified.
+func SharedStore(cacheDir string, files ...string) (Store, error) {
+	key, err := fileSetKey(files)
+	if err != nil {
+		return nil, err
+	}
+	fp, err := fingerprint(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedStoresLock.Lock()
+	ss, ok := sharedStores[key]
+	if ok && ss.fingerprint == fp {
+		sharedStoresLock.Unlock()
+		<-ss.done
+		return ss.store, ss.err
+	}
+	ss = &sharedStore{fingerprint: fp, done: make(chan struct{})}
+	sharedStores[key] = ss
+	sharedStoresLock.Unlock()
+
+	s, err := loadSharedStore(cacheDir, key, fp, files)
+	if err != nil {
+		// Let the next caller try again.
+		sharedStoresLock.Lock()
+		if sharedStores[key] == ss {
+			delete(sharedStores, key)
+		}
+		sharedStoresLock.Unlock()
+		ss.err = err
+	} else {
+		ss.store = s
+	}
+	close(ss.done)
+	return ss.store, ss.err
+}
+
+// loadSharedStore loads the Store for a set of MIB files from the
+// cache directory, if any, or else parses them.
+func loadSharedStore(cacheDir, key, fp string, files []string) (*store, error) {
+	var cacheFile string
+	var s *store
+	if cacheDir != "" {
+		cacheFile = filepath.Join(cacheDir,
+			fmt.Sprintf("%x.gob", sha256.Sum256([]byte(key))))
+		// A missing, stale, or unreadable cache file just means
+		// we have to parse the files.
+		s, _ = loadStore(cacheFile, fp)
+	}
+	if s == nil {
+		st, err := NewLenientStore(files...)
+		if err != nil {
+			return nil, err
+		}
+		s = st.(*store)
+		if cacheFile != "" {
+			// The cache only saves time, so failing to write it
+			// isn't fatal.
+			_ = saveStore(cacheFile, fp, s)
+		}
+	}
+	// A shared Store lives as long as the process and sees the
+	// instance OIDs of every device using it, so it can't remember
+	// them all.
+	s.shared = true
+	return s, nil
+}
+
+// fileSetKey returns a key identifying a set of MIB files and
+// directories regardless of their order or how their paths are
+// written.
+func fileSetKey(files []string) (string, error) {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		p, err := filepath.Abs(f)
+		if err != nil {
+			return "", err
+		}
+		paths[i] = p
+	}
+	sort.Strings(paths)
+	return strings.Join(paths, "\n"), nil
+}
+
+// bundledFingerprint returns a hash of the bundled MIBs, which are
+// part of every Store, so that a cache written by a binary with
+// different bundled MIBs isn't used.
+func bundledFingerprint() string {
+	bundledHashOnce.Do(func() {
+		h := sha256.New()
+		for _, name := range mibs.Names() {
+			text, _ := mibs.File(name)
+			fmt.Fprintf(h, "%s %d\n%s", name, len(text), text)
+		}
+		bundledHash = fmt.Sprintf("%x", h.Sum(nil))
+	})
+	return bundledHash
+}
+
+// fingerprint returns a hash of the bundled MIBs and of the names,
+// sizes, and modification times of a set of MIB files, which changes
+// if any file is added, removed, or modified.
+func fingerprint(files []string) (string, error) {
+	entries := []string{"bundled " + bundledFingerprint()}
+	err := walkMIBFiles(files, func(path string, info os.FileInfo) error {
+		p, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, fmt.Sprintf("%s %d %d", p, info.Size(),
+			info.ModTime().UnixNano()))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(entries)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(entries, "\n")))), nil
+}
+
+// cachedObject is an Object as saved in the on-disk cache, with its
+// parent and children given by their positions in the cache's list
+// of objects, since gob can't encode the cycles between them.
+type cachedObject struct {
+	Access      Access
+	Description string
+	Indexes     []string
+	Implied     bool
+	Kind        Kind
+	Module      string
+	Name        string
+	Oid         string
+	Status      Status
+	Syntax      *Syntax
+	Objects     []string
+	Parent      int
+	Children    []int
+}
+
+type cachedModule struct {
+	Name       string
+	ObjectTree []int
+	Imports    []Import
+}
+
+type storeCache struct {
+	Version     int
+	Fingerprint string
+	Objects     []cachedObject
+	Modules     []cachedModule
+	Oids        map[string]int
+	Names       map[string]int
+	Types       map[string]map[string]*Syntax
+	Diagnostics []Diagnostic
+}
+
+func saveStore(filename, fingerprint string, s *store) error {
+	c := &storeCache{
+		Version:     cacheVersion,
+		Fingerprint: fingerprint,
+		Oids:        make(map[string]int),
+		Names:       make(map[string]int),
+		Types:       s.types,
+		Diagnostics: s.diagnostics,
+	}
+	ids := make(map[*Object]int)
+	var add func(o *Object) int
+	add = func(o *Object) int {
+		if id, ok := ids[o]; ok {
+			return id
+		}
+		id := len(c.Objects)
+		ids[o] = id
+		c.Objects = append(c.Objects, cachedObject{
+			Access:      o.Access,
+			Description: o.Description,
+			Indexes:     o.Indexes,
+			Implied:     o.Implied,
+			Kind:        o.Kind,
+			Module:      o.Module,
+			Name:        o.Name,
+			Oid:         o.Oid,
+			Status:      o.Status,
+			Syntax:      o.Syntax,
+			Objects:     o.Objects,
+			Parent:      -1,
+		})
+		if o.Parent != nil {
+			c.Objects[id].Parent = add(o.Parent)
+		}
+		children := make([]int, len(o.Children))
+		for i, child := range o.Children {
+			children[i] = add(child)
+		}
+		c.Objects[id].Children = children
+		return id
+	}
+	for _, m := range s.modules {
+		cm := cachedModule{Name: m.Name, Imports: m.Imports}
+		for _, o := range m.ObjectTree {
+			cm.ObjectTree = append(cm.ObjectTree, add(o))
+		}
+		c.Modules = append(c.Modules, cm)
+	}
+	for oid, o := range s.oids {
+		c.Oids[oid] = add(o)
+	}
+	for name, o := range s.names {
+		c.Names[name] = add(o)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file and rename it, so that nobody loads
+	// a partially written cache.
+	f, err := ioutil.TempFile(filepath.Dir(filename), ".mibcache")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := gob.NewEncoder(f).Encode(c); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+func loadStore(filename, fingerprint string) (*store, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c := &storeCache{}
+	if err := gob.NewDecoder(f).Decode(c); err != nil {
+		return nil, err
+	}
+	if c.Version != cacheVersion || c.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("MIB cache %s is out of date", filename)
+	}
+
+	valid := func(id int) bool {
+		return id >= 0 && id < len(c.Objects)
+	}
+	for _, co := range c.Objects {
+		if co.Parent != -1 && !valid(co.Parent) {
+			return nil, fmt.Errorf("Bad MIB cache %s", filename)
+		}
+		for _, id := range co.Children {
+			if !valid(id) {
+				return nil, fmt.Errorf("Bad MIB cache %s", filename)
+			}
+		}
+	}
+	for _, cm := range c.Modules {
+		for _, id := range cm.ObjectTree {
+			if !valid(id) {
+				return nil, fmt.Errorf("Bad MIB cache %s", filename)
+			}
+		}
+	}
+	for _, m := range []map[string]int{c.Oids, c.Names} {
+		for _, id := range m {
+			if !valid(id) {
+				return nil, fmt.Errorf("Bad MIB cache %s", filename)
+			}
+		}
+	}
+
+	objects := make([]*Object, len(c.Objects))
+	for i, co := range c.Objects {
+		objects[i] = &Object{
+			Access:      co.Access,
+			Description: co.Description,
+			Indexes:     co.Indexes,
+			Implied:     co.Implied,
+			Kind:        co.Kind,
+			Module:      co.Module,
+			Name:        co.Name,
+			Oid:         co.Oid,
+			Status:      co.Status,
+			Syntax:      co.Syntax,
+			Objects:     co.Objects,
+		}
+	}
+	for i, co := range c.Objects {
+		if co.Parent >= 0 {
+			objects[i].Parent = objects[co.Parent]
+		}
+		for _, child := range co.Children {
+			objects[i].Children = append(objects[i].Children, objects[child])
+		}
+	}
+
+	s := newStore()
+	s.lenient = true
+	s.diagnostics = c.Diagnostics
+	if c.Types != nil {
+		s.types = c.Types
+	}
+	for _, cm := range c.Modules {
+		m := &Module{Name: cm.Name, ObjectTree: []*Object{}, Imports: cm.Imports}
+		for _, id := range cm.ObjectTree {
+			m.ObjectTree = append(m.ObjectTree, objects[id])
+		}
+		s.modules[m.Name] = m
+	}
+	for oid, id := range c.Oids {
+		s.oids[oid] = objects[id]
+	}
+	for name, id := range c.Names {
+		s.names[name] = objects[id]
+	}
+	return s, nil
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package smi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func copyMIBs(t *testing.T, dir string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob("mibs/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.Base(f)),
+			b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkSameObject(t *testing.T, name string, s, exp Store) {
+	o, e := s.GetObject(name), exp.GetObject(name)
+	if o == nil || e == nil {
+		t.Fatalf("Missing object %s: %v, %v", name, o, e)
+	}
+	checkEqual(t, o, e)
+	if !reflect.DeepEqual(o.Syntax, e.Syntax) {
+		t.Fatalf("Expected %s syntax %v, got %v", name, e.Syntax, o.Syntax)
+	}
+	if !reflect.DeepEqual(o.Objects, e.Objects) {
+		t.Fatalf("Expected %s objects %v, got %v", name, e.Objects, o.Objects)
+	}
+	if o.Implied != e.Implied {
+		t.Fatalf("Expected %s Implied %v, got %v", name, e.Implied, o.Implied)
+	}
+	if (o.Parent == nil) != (e.Parent == nil) ||
+		(o.Parent != nil && o.Parent.Name != e.Parent.Name) {
+		t.Fatalf("Expected %s parent %v, got %v", name, e.Parent, o.Parent)
+	}
+	if len(o.Children) != len(e.Children) {
+		t.Fatalf("Expected %d children of %s, got %d", len(e.Children),
+			name, len(o.Children))
+	}
+}
+
+func TestSharedStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mibDir := filepath.Join(dir, "mibs")
+	cacheDir := filepath.Join(dir, "cache")
+	copyMIBs(t, mibDir)
+
+	s1, err := SharedStore(cacheDir, mibDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := SharedStore(cacheDir, mibDir+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1 != s2 {
+		t.Fatal("Expected the same Store for the same MIB files")
+	}
+
+	// A shared Store shouldn't remember every instance OID looked up.
+	if o := s1.GetObject("1.3.6.1.2.1.2.2.1.2.3"); o == nil || o.Name != "ifDescr" {
+		t.Fatalf("Expected ifDescr, got %v", o)
+	}
+	if s1.GetObject("ifDescr") == nil {
+		t.Fatal("Missing ifDescr")
+	}
+	if o := s1.(*store).checkKnown("1.3.6.1.2.1.2.2.1.2.3"); o != nil {
+		t.Fatal("Expected instance OID not to be remembered")
+	}
+	if o := s1.(*store).checkKnown("ifDescr"); o == nil {
+		t.Fatal("Expected object name to be remembered")
+	}
+
+	// The Store saved to disk should match the one we parsed.
+	key, err := fileSetKey([]string{mibDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp, err := fingerprint([]string{mibDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(cacheDir, "*.gob"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one cache file, got %v (%v)", files, err)
+	}
+	loaded, err := loadStore(files[0], fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"interfaces", "ifTable", "ifEntry",
+		"ifDescr", "ifOperStatus", "lldpRemManAddrEntry", "lldpRemManAddr",
+		"snmpTargetAddrEntry", "sysUpTimeInstance", "1.3.6.1.2.1.2.2.1.2.3",
+		"linkDown"} {
+		checkSameObject(t, name, loaded, s1)
+	}
+	if len(loaded.modules) != len(s1.(*store).modules) {
+		t.Fatalf("Expected %d modules, got %d", len(s1.(*store).modules),
+			len(loaded.modules))
+	}
+
+	// Another process would load the Store from disk.
+	sharedStoresLock.Lock()
+	delete(sharedStores, key)
+	sharedStoresLock.Unlock()
+	s3, err := SharedStore(cacheDir, mibDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s3 == s1 {
+		t.Fatal("Expected a new Store")
+	}
+	checkSameObject(t, "lldpRemManAddr", s3, s1)
+
+	// Changing a MIB file invalidates both caches.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(mibDir, "IF-MIB"), later,
+		later); err != nil {
+		t.Fatal(err)
+	}
+	fp, err = fingerprint([]string{mibDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadStore(files[0], fp); err == nil {
+		t.Fatal("Expected out-of-date cache error")
+	}
+	s4, err := SharedStore(cacheDir, mibDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s4 == s3 {
+		t.Fatal("Expected a new Store after a MIB file changed")
+	}
+	if _, err := loadStore(files[0], fp); err != nil {
+		t.Fatalf("Expected the cache to be rewritten: %v", err)
+	}
+}
+
+// Concurrent callers wanting the same MIBs should get the one Store
+// parsed for the first of them.
+func TestSharedStoreConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mibDir := filepath.Join(dir, "mibs")
+	copyMIBs(t, mibDir)
+
+	stores := make([]Store, 4)
+	errs := make([]error, len(stores))
+	var wg sync.WaitGroup
+	for i := range stores {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stores[i], errs[i] = SharedStore("", mibDir)
+		}(i)
+	}
+	wg.Wait()
+	for i, s := range stores {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if s != stores[0] {
+			t.Fatal("Expected the same Store for all callers")
+		}
+	}
+
+	// A failure isn't remembered.
+	if _, err := SharedStore("", filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("Expected error for missing MIB directory")
+	}
+	key, err := fileSetKey([]string{filepath.Join(dir, "missing")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharedStoresLock.Lock()
+	_, ok := sharedStores[key]
+	sharedStoresLock.Unlock()
+	if ok {
+		t.Fatal("Expected failed Store to be forgotten")
+	}
+}
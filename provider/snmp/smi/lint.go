@@ -0,0 +1,51 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package smi
+
+import (
+	"sort"
+)
+
+// A Diagnostic describes a problem with a MIB file or module found
+// loading a lenient Store. Module is empty for a problem with a file
+// as a whole.
+type Diagnostic struct {
+	File    string
+	Module  string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	s := d.File
+	if d.Module != "" {
+		if s != "" {
+			s += ": "
+		}
+		s += d.Module
+	}
+	return s + ": " + d.Message
+}
+
+// Lint parses the specified MIB files and directories on top of the
+// bundled MIBs and reports any problems with them: files that can't
+// be parsed, imports from modules that aren't available, and objects
+// whose OIDs can't be resolved. Unlike NewStore, it carries on past
+// each problem so as to report them all.
+func Lint(files ...string) ([]Diagnostic, error) {
+	s, err := NewLenientStore(files...)
+	if err != nil {
+		return nil, err
+	}
+	return s.Diagnostics(), nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package smi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const goodMIB = `GOOD-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    enterprises FROM SNMPv2-SMI;
+
+goodRoot OBJECT IDENTIFIER ::= { enterprises 99999 }
+goodObject OBJECT IDENTIFIER ::= { goodRoot 1 }
+END
+`
+
+const missingMIB = `MISSING-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    enterprises FROM SNMPv2-SMI
+    fooRoot, fooTable FROM FOO-MIB;
+
+missingRoot OBJECT IDENTIFIER ::= { enterprises 99998 }
+fromFoo OBJECT IDENTIFIER ::= { fooRoot 1 }
+fromNowhere OBJECT IDENTIFIER ::= { nowhere 2 }
+END
+`
+
+func writeMIBs(t *testing.T, dir string, mibs map[string]string) {
+	for name, text := range mibs {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text),
+			0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMIBs(t, dir, map[string]string{
+		"GOOD-MIB":    goodMIB,
+		"MISSING-MIB": missingMIB,
+		"BROKEN-MIB":  "BROKEN-MIB DEFINITIONS ::= BEGIN\nthis isn't SMI\n",
+	})
+
+	problems, err := Lint(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) == 0 || problems[0].File != filepath.Join(dir, "BROKEN-MIB") ||
+		problems[0].Module != "" {
+		t.Fatalf("Expected a parse problem with BROKEN-MIB, got %v", problems)
+	}
+	missing := filepath.Join(dir, "MISSING-MIB")
+	expected := []Diagnostic{
+		{File: missing, Module: "MISSING-MIB",
+			Message: "Import from unknown module FOO-MIB: fooRoot, fooTable"},
+		{File: missing, Module: "MISSING-MIB",
+			Message: "Could not find OID for 'fooRoot' in OID of fromFoo"},
+		{File: missing, Module: "MISSING-MIB",
+			Message: "Could not find OID for 'nowhere' in OID of fromNowhere"},
+	}
+	if !reflect.DeepEqual(problems[1:], expected) {
+		t.Fatalf("Expected problems %v, got %v", expected, problems[1:])
+	}
+
+	// The bundled MIBs themselves have no problems.
+	problems, err = Lint("mibs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("Expected no problems with bundled MIBs, got %v", problems)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+//go:build ignore
+// +build ignore
+
+// generate.go writes mibs_gen.go, which holds the contents of the MIB
+// files in this directory so that they're built into binaries. Line
+// endings are normalized to newlines.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// rawString returns s as a raw string literal, splicing in any
+// backquotes it contains.
+func rawString(s string) string {
+	return "`" + strings.Replace(s, "`", "` + \"`\" + `", -1) + "`"
+}
+
+func main() {
+	entries, err := ioutil.ReadDir(".")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "// Code generated by generate.go. DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package mibs")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "var files = map[string]string{")
+	for _, e := range entries {
+		// The MIB files are the ones without an extension.
+		if e.IsDir() || filepath.Ext(e.Name()) != "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(e.Name())
+		if err != nil {
+			log.Fatal(err)
+		}
+		s := strings.Replace(string(data), "\r\n", "\n", -1)
+		s = strings.Replace(s, "\r", "\n", -1)
+		fmt.Fprintf(&b, "%q: %s,\n", e.Name(), rawString(s))
+	}
+	fmt.Fprintln(&b, "}")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("mibs_gen.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -2,4 +2,28 @@
 // Use of this source code is governed by the Apache License 2.0
 // that can be found in the COPYING file.
 
+// Package mibs holds the MIB files bundled with the SNMP provider,
+// which are built into binaries so that they needn't be installed
+// alongside them.
 package mibs
+
+import "sort"
+
+//go:generate go run generate.go
+
+// Names returns the names of the bundled MIB files in lexical order.
+func Names() []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// File returns the contents of the named bundled MIB file, and
+// whether there is such a file.
+func File(name string) (string, bool) {
+	s, ok := files[name]
+	return s, ok
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package smi
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// snmpTrapsOid is the OID of snmpTraps, under which RFC 3584
+	// places the SNMPv2 notifications for the SMIv1 generic traps.
+	snmpTrapsOid = "1.3.6.1.6.3.1.1.5"
+
+	// enterpriseSpecific is the generic-trap value of an SMIv1
+	// enterprise-specific trap.
+	enterpriseSpecific = 6
+)
+
+// TrapOID returns the SNMPv2 notification OID of an SMIv1 trap, as
+// described in RFC 3584 section 3.1. A generic trap maps to one of
+// the notifications under snmpTraps and an enterprise-specific trap
+// to its enterprise followed by 0 and its specific-trap number.
+func TrapOID(enterprise string, generic, specific int) string {
+	if generic != enterpriseSpecific {
+		return fmt.Sprintf("%s.%d", snmpTrapsOid, generic+1)
+	}
+	return fmt.Sprintf("%s.0.%d", strings.TrimPrefix(enterprise, "."), specific)
+}
+
+// GetNotification returns the notification with the specified text or
+// numeric OID, or nil if there's no such notification. Its Objects
+// name the objects in its varbinds, in order.
+func (s *store) GetNotification(oid string) *Object {
+	o := s.GetObject(oid)
+	if o == nil || o.Kind != KindNotification {
+		return nil
+	}
+	return o
+}
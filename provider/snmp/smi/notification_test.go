@@ -0,0 +1,128 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package smi
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const trapMIB = `TRAP-TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    enterprises FROM RFC1155-SMI
+    ifIndex FROM RFC1213-MIB
+    TRAP-TYPE FROM RFC-1215;
+
+trapTest OBJECT IDENTIFIER ::= { enterprises 99995 }
+
+trapTestFault TRAP-TYPE
+    ENTERPRISE trapTest
+    VARIABLES { ifIndex, trapTest }
+    DESCRIPTION
+        "Something went wrong."
+    ::= 3
+
+trapTestQuiet TRAP-TYPE
+    ENTERPRISE trapTest
+    ::= 4
+END
+`
+
+func TestTrapOID(t *testing.T) {
+	for _, tc := range []struct {
+		enterprise        string
+		generic, specific int
+		oid               string
+	}{
+		{".1.3.6.1.4.1.30065", 0, 0, "1.3.6.1.6.3.1.1.5.1"},
+		{".1.3.6.1.4.1.30065", 2, 0, "1.3.6.1.6.3.1.1.5.3"},
+		{".1.3.6.1.4.1.30065", 6, 7, "1.3.6.1.4.1.30065.0.7"},
+		{"1.3.6.1.2.1.47.2", 6, 1, "1.3.6.1.2.1.47.2.0.1"},
+	} {
+		if oid := TrapOID(tc.enterprise, tc.generic, tc.specific); oid != tc.oid {
+			t.Errorf("Expected OID %s for trap %s/%d/%d, got %s", tc.oid,
+				tc.enterprise, tc.generic, tc.specific, oid)
+		}
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMIBs(t, dir, map[string]string{"TRAP-TEST-MIB": trapMIB})
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		oid         string
+		name        string
+		objects     []string
+		description string
+	}{
+		{
+			oid:     "1.3.6.1.6.3.1.1.5.3",
+			name:    "linkDown",
+			objects: []string{"ifIndex", "ifAdminStatus", "ifOperStatus"},
+		},
+		{
+			oid:     "IF-MIB::linkUp",
+			name:    "linkUp",
+			objects: []string{"ifIndex", "ifAdminStatus", "ifOperStatus"},
+		},
+		{
+			oid:  TrapOID("", 0, 0),
+			name: "coldStart",
+		},
+		{
+			oid:         TrapOID(".1.3.6.1.4.1.99995", 6, 3),
+			name:        "trapTestFault",
+			objects:     []string{"ifIndex", "trapTest"},
+			description: "Something went wrong.",
+		},
+		{
+			oid:  "trapTestQuiet",
+			name: "trapTestQuiet",
+		},
+		{
+			oid: "ifDescr",
+		},
+		{
+			oid: "1.3.6.1.6.3.1.1.5.3.1",
+		},
+	} {
+		t.Run(tc.oid, func(t *testing.T) {
+			o := s.GetNotification(tc.oid)
+			if tc.name == "" {
+				if o != nil {
+					t.Fatalf("Expected no notification, got %v", o)
+				}
+				return
+			}
+			if o == nil || o.Name != tc.name {
+				t.Fatalf("Expected notification %s, got %v", tc.name, o)
+			}
+			if o.Kind != KindNotification {
+				t.Fatalf("Expected kind Notification, got %v", o.Kind)
+			}
+			if !reflect.DeepEqual(o.Objects, tc.objects) {
+				t.Fatalf("Expected objects %v, got %v", tc.objects, o.Objects)
+			}
+			if tc.description != "" && o.Description != tc.description {
+				t.Fatalf("Expected description %q, got %q", tc.description,
+					o.Description)
+			}
+		})
+	}
+	if o := s.GetObject("trapTestQuiet"); o == nil || o.Oid != "1.3.6.1.4.1.99995.0.4" {
+		t.Fatalf("Expected trapTestQuiet OID 1.3.6.1.4.1.99995.0.4, got %v", o)
+	}
+}
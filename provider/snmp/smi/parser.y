@@ -41,18 +41,24 @@ import (
     token Token
     augments string
     description string
+    enums []NamedNumber
     imports []Import
     importIDs []string
+    implied bool
     indexes []string
     modules []*parseModule
+    names []string
     object *parseObject
     objects []*parseObject
     objectMap map[string]*parseObject
     orphans []*parseObject
+    ranges []Range
     status Status
+    syntax *Syntax
     table bool
     val string
     subidentifiers []string
+    types map[string]*Syntax
 }
 
 %start mibFile
@@ -183,6 +189,7 @@ module : moduleName moduleOid definitions COLON_COLON_EQUAL BEGIN exportsClause
                name: $1.val,
                objectTree: []*parseObject{},
                orphans: []*parseObject{},
+               types: $8.types,
            }
            for _, o := range $8.objects {
                m.objectTree = append(m.objectTree, o)
@@ -311,10 +318,12 @@ declarationPart : declarations
 declarations : declaration
              {
                  (&$$).addObject($1.object)
+                 (&$$).addTypes($1.types)
              }
              | declarations declaration
              {
                  (&$$).addObject($2.object)
+                 (&$$).addTypes($2.types)
              }
              ;
 
@@ -406,6 +415,12 @@ valueDeclaration : fuzzyLowercaseIdentifier OBJECT IDENTIFIER COLON_COLON_EQUAL
                  ;
 
 typeDeclaration : typeName COLON_COLON_EQUAL typeDeclarationRHS
+                {
+                    $$.types = nil
+                    if $3.syntax != nil {
+                        $$.types = map[string]*Syntax{$1.token.literal: $3.syntax}
+                    }
+                }
                 ;
 
 typeName : UPPERCASE_IDENTIFIER
@@ -436,14 +451,22 @@ typeSPPIonly : INTEGER64
 typeDeclarationRHS : Syntax
                    {
                        $$.table = $1.table
+                       $$.syntax = $1.syntax
                    }
                    | TEXTUAL_CONVENTION DisplayPart STATUS Status DESCRIPTION Text ReferPart SYNTAX Syntax
                    {
                        $$.table = $9.table
+                       $$.syntax = $9.syntax
+                       if $$.syntax != nil {
+                           $$.syntax.DisplayHint = $2.val
+                       }
                        $$.status = strToStatus($4.val)
                        $$.description = $6.val
                    }
                    | choiceClause
+                   {
+                       $$.syntax = nil
+                   }
                    ;
 
 conceptualTable : SEQUENCE OF row
@@ -467,6 +490,9 @@ sequenceItem : LOWERCASE_IDENTIFIER sequenceSyntax
 
 Syntax : ObjectSyntax
        | BITS '{' NamedBits '}'
+       {
+           $$.syntax = &Syntax{Base: BaseBits, Enums: $3.enums}
+       }
        ;
 
 sequenceSyntax : sequenceObjectSyntax
@@ -475,10 +501,20 @@ sequenceSyntax : sequenceObjectSyntax
                ;
 
 NamedBits : NamedBit
+          {
+              $$.enums = $1.enums
+          }
           | NamedBits ',' NamedBit
+          {
+              $$.enums = append($1.enums, $3.enums...)
+          }
           ;
 
 NamedBit : LOWERCASE_IDENTIFIER '(' NUMBER ')'
+         {
+             $$.enums = []NamedNumber{{Name: $1.token.literal,
+                 Value: rangeValue($3.token.literal)}}
+         }
          ;
 
 objectIdentityClause : LOWERCASE_IDENTIFIER OBJECT_IDENTITY STATUS Status DESCRIPTION Text ReferPart COLON_COLON_EQUAL '{' objectIdentifier '}'
@@ -501,10 +537,12 @@ objectTypeClause : LOWERCASE_IDENTIFIER OBJECT_TYPE SYNTAX Syntax UnitsPart MaxO
                          object: &Object{
                              Access: strToAccess($6.val),
                              Description: $11.val,
+                             Implied: $15.implied,
                              Indexes: $15.indexes,
                              Name: $1.token.literal,
                              Oid: strings.Join($20.subidentifiers, "."),
                              Status: strToStatus($10.val),
+                             Syntax: $4.syntax,
                          },
                          decl: declObjectType,
                          table: $4.table,
@@ -521,21 +559,53 @@ descriptionClause : DESCRIPTION Text
                   ;
 
 trapTypeClause : fuzzyLowercaseIdentifier TRAP_TYPE ENTERPRISE objectIdentifier VarPart DescrPart ReferPart COLON_COLON_EQUAL NUMBER
+               {
+                   // An SMIv1 trap's SNMPv2 notification OID is its
+                   // enterprise followed by 0 and its trap number
+                   // (RFC 3584 section 3.1).
+                   $$.object = &parseObject{
+                       object: &Object{
+                           Description: $6.val,
+                           Name: $1.val,
+                           Objects: $5.names,
+                           Oid: strings.Join($4.subidentifiers, ".") + ".0." + $9.token.literal,
+                       },
+                       decl: declTrapType,
+                   }
+               }
                ;
 
 VarPart : VARIABLES '{' VarTypes '}'
+        {
+            $$.names = $3.names
+        }
         |
+        {
+            $$.names = nil
+        }
         ;
 
 VarTypes : VarType
+         {
+             $$.names = []string{strings.Join($1.subidentifiers, ".")}
+         }
          | VarTypes ',' VarType
+         {
+             $$.names = append($1.names, strings.Join($3.subidentifiers, "."))
+         }
          ;
 
 VarType : ObjectName
         ;
 
 DescrPart : DESCRIPTION Text
+          {
+              $$.val = $2.val
+          }
           |
+          {
+              $$.val = ""
+          }
           ;
 
 MaxOrPIBAccessPart : MaxAccessPart
@@ -595,6 +665,18 @@ MaxAccessPart : MAX_ACCESS Access
               ;
 
 notificationTypeClause : LOWERCASE_IDENTIFIER NOTIFICATION_TYPE NotificationObjectsPart STATUS Status DESCRIPTION Text ReferPart COLON_COLON_EQUAL '{' NotificationName '}'
+                       {
+                           $$.object = &parseObject{
+                               object: &Object{
+                                   Description: $7.val,
+                                   Name: $1.token.literal,
+                                   Objects: $3.names,
+                                   Oid: strings.Join($11.subidentifiers, "."),
+                                   Status: strToStatus($5.val),
+                               },
+                               decl: declNotificationType,
+                           }
+                       }
                        ;
 
 
@@ -627,9 +709,21 @@ CategoryID : LOWERCASE_IDENTIFIER
 
 ObjectSyntax : SimpleSyntax
              | typeTag SimpleSyntax
+             {
+                 $$.syntax = $2.syntax
+             }
              | conceptualTable
+             {
+                 $$.syntax = nil
+             }
              | row
+             {
+                 $$.syntax = &Syntax{TextualConvention: $1.token.literal}
+             }
              | entryType
+             {
+                 $$.syntax = nil
+             }
              | ApplicationSyntax
              ;
 
@@ -645,19 +739,67 @@ valueofObjectSyntax : valueofSimpleSyntax
                     ;
 
 SimpleSyntax : INTEGER
+             {
+                 $$.syntax = &Syntax{Base: BaseInteger}
+             }
              | INTEGER integerSubType
+             {
+                 $$.syntax = &Syntax{Base: BaseInteger, Ranges: $2.ranges}
+             }
              | INTEGER enumSpec
+             {
+                 $$.syntax = &Syntax{Base: BaseInteger, Enums: $2.enums}
+             }
              | INTEGER32
+             {
+                 $$.syntax = &Syntax{Base: BaseInteger}
+             }
              | INTEGER32 integerSubType
+             {
+                 $$.syntax = &Syntax{Base: BaseInteger, Ranges: $2.ranges}
+             }
              | UPPERCASE_IDENTIFIER enumSpec
+             {
+                 $$.syntax = &Syntax{TextualConvention: $1.token.literal,
+                     Enums: $2.enums}
+             }
              | moduleName '.' UPPERCASE_IDENTIFIER enumSpec
+             {
+                 $$.syntax = &Syntax{TextualConvention: $3.token.literal,
+                     Enums: $4.enums}
+             }
              | UPPERCASE_IDENTIFIER integerSubType
+             {
+                 $$.syntax = &Syntax{TextualConvention: $1.token.literal,
+                     Ranges: $2.ranges}
+             }
              | moduleName '.' UPPERCASE_IDENTIFIER integerSubType
+             {
+                 $$.syntax = &Syntax{TextualConvention: $3.token.literal,
+                     Ranges: $4.ranges}
+             }
              | OCTET STRING
+             {
+                 $$.syntax = &Syntax{Base: BaseOctetString}
+             }
              | OCTET STRING octetStringSubType
+             {
+                 $$.syntax = &Syntax{Base: BaseOctetString, Sizes: $3.ranges}
+             }
              | UPPERCASE_IDENTIFIER octetStringSubType
+             {
+                 $$.syntax = &Syntax{TextualConvention: $1.token.literal,
+                     Sizes: $2.ranges}
+             }
              | moduleName '.' UPPERCASE_IDENTIFIER octetStringSubType
+             {
+                 $$.syntax = &Syntax{TextualConvention: $3.token.literal,
+                     Sizes: $4.ranges}
+             }
 	         | OBJECT IDENTIFIER anySubType
+             {
+                 $$.syntax = &Syntax{Base: BaseObjectIdentifier}
+             }
              ;
 
 valueofSimpleSyntax : NUMBER
@@ -678,19 +820,61 @@ sequenceSimpleSyntax : INTEGER anySubType
                      ;
 
 ApplicationSyntax : IPADDRESS anySubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseIPAddress}
+                  }
                   | COUNTER32 anySubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseCounter32}
+                  }
                   | GAUGE32
+                  {
+                      $$.syntax = &Syntax{Base: BaseGauge32}
+                  }
                   | GAUGE32 integerSubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseGauge32, Ranges: $2.ranges}
+                  }
                   | UNSIGNED32
+                  {
+                      $$.syntax = &Syntax{Base: BaseUnsigned32}
+                  }
                   | UNSIGNED32 integerSubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseUnsigned32, Ranges: $2.ranges}
+                  }
                   | TIMETICKS anySubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseTimeTicks}
+                  }
                   | OPAQUE
+                  {
+                      $$.syntax = &Syntax{Base: BaseOpaque}
+                  }
                   | OPAQUE octetStringSubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseOpaque, Sizes: $2.ranges}
+                  }
                   | COUNTER64 anySubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseCounter64}
+                  }
                   | INTEGER64
+                  {
+                      $$.syntax = &Syntax{Base: BaseInteger64}
+                  }
                   | INTEGER64 integerSubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseInteger64, Ranges: $2.ranges}
+                  }
                   | UNSIGNED64
+                  {
+                      $$.syntax = &Syntax{Base: BaseUnsigned64}
+                  }
                   | UNSIGNED64 integerSubType
+                  {
+                      $$.syntax = &Syntax{Base: BaseUnsigned64, Ranges: $2.ranges}
+                  }
                   ;
 
 sequenceApplicationSyntax : IPADDRESS anySubType
@@ -711,17 +895,37 @@ anySubType : integerSubType
            ;
 
 integerSubType : '(' ranges ')'
+               {
+                   $$.ranges = $2.ranges
+               }
                ;
 
 octetStringSubType : '(' SIZE '(' ranges ')' ')'
+                   {
+                       $$.ranges = $4.ranges
+                   }
                    ;
 
 ranges : range
+       {
+           $$.ranges = $1.ranges
+       }
        | ranges '|' range
+       {
+           $$.ranges = append($1.ranges, $3.ranges...)
+       }
        ;
 
 range : value
+      {
+          v := rangeValue($1.token.literal)
+          $$.ranges = []Range{{Min: v, Max: v}}
+      }
       | value DOT_DOT value
+      {
+          $$.ranges = []Range{{Min: rangeValue($1.token.literal),
+              Max: rangeValue($3.token.literal)}}
+      }
       ;
 
 value : NEGATIVE_NUMBER
@@ -733,13 +937,26 @@ value : NEGATIVE_NUMBER
       ;
 
 enumSpec : '{' enumItems '}'
+         {
+             $$.enums = $2.enums
+         }
          ;
 
 enumItems : enumItem
+          {
+              $$.enums = $1.enums
+          }
           | enumItems ',' enumItem
+          {
+              $$.enums = append($1.enums, $3.enums...)
+          }
           ;
 
 enumItem : LOWERCASE_IDENTIFIER '(' enumNumber ')'
+         {
+             $$.enums = []NamedNumber{{Name: $1.token.literal,
+                 Value: rangeValue($3.token.literal)}}
+         }
          ;
 
 enumNumber : NUMBER
@@ -756,7 +973,13 @@ Status_Capabilities : LOWERCASE_IDENTIFIER
                     ;
 
 DisplayPart : DISPLAY_HINT Text
+            {
+                $$.val = $2.val
+            }
             |
+            {
+                $$.val = ""
+            }
             ;
 
 UnitsPart : UNITS Text
@@ -787,10 +1010,12 @@ IndexPart : PIB_INDEX '{' Entry '}'
 MibIndex : INDEX '{' IndexTypes '}'
          {
              $$.indexes = $3.indexes
+             $$.implied = $3.implied
          }
          |
          {
              $$.indexes = nil
+             $$.implied = false
          }
          ;
 
@@ -805,13 +1030,19 @@ IndexTypes : IndexType
                if $3.val != "" {
                    $$.indexes = append($1.indexes, $3.val)
                }
+               $$.implied = $3.implied
            }
            ;
 
 IndexType : IMPLIED Index
+          {
+              $$.val = strings.Join($2.subidentifiers, " ")
+              $$.implied = true
+          }
           | Index
           {
               $$.val = strings.Join($1.subidentifiers, " ")
+              $$.implied = false
           }
           ;
 
@@ -859,14 +1090,26 @@ Revision : REVISION ExtUTCTime DESCRIPTION Text
          ;
 
 NotificationObjectsPart : OBJECTS '{' Objects '}'
+                        {
+                            $$.names = $3.names
+                        }
                         |
+                        {
+                            $$.names = nil
+                        }
                         ;
 
 ObjectGroupObjectsPart : OBJECTS '{' Objects '}'
                        ;
 
 Objects : Object
+        {
+            $$.names = []string{strings.Join($1.subidentifiers, ".")}
+        }
         | Objects ',' Object
+        {
+            $$.names = append($1.names, strings.Join($3.subidentifiers, "."))
+        }
         ;
 
 Object : ObjectName
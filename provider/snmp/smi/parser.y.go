@@ -17,18 +17,24 @@ type yySymType struct {
 	token          Token
 	augments       string
 	description    string
+	enums          []NamedNumber
 	imports        []Import
 	importIDs      []string
+	implied        bool
 	indexes        []string
 	modules        []*parseModule
+	names          []string
 	object         *parseObject
 	objects        []*parseObject
 	objectMap      map[string]*parseObject
 	orphans        []*parseObject
+	ranges         []Range
 	status         Status
+	syntax         *Syntax
 	table          bool
 	val            string
 	subidentifiers []string
+	types          map[string]*Syntax
 }
 
 const ACCESS = 57346
@@ -241,13 +247,14 @@ var yyToknames = [...]string{
 	"'.'",
 	"'|'",
 }
+
 var yyStatenames = [...]string{}
 
 const yyEofCode = 1
 const yyErrCode = 2
 const yyInitialStackSize = 16
 
-//line parser.y:1075
+//line parser.y:1318
 
 //line yacctab:1
 var yyExca = [...]int{
@@ -276,7 +283,6 @@ const yyPrivate = 57344
 const yyLast = 747
 
 var yyAct = [...]int{
-
 	269, 631, 230, 559, 607, 578, 516, 563, 565, 500,
 	556, 522, 270, 544, 511, 489, 271, 480, 377, 386,
 	464, 12, 16, 4, 451, 4, 282, 420, 135, 381,
@@ -353,8 +359,8 @@ var yyAct = [...]int{
 	0, 0, 0, 0, 0, 0, 0, 0, 65, 75,
 	62, 0, 0, 0, 78, 73, 57,
 }
-var yyPact = [...]int{
 
+var yyPact = [...]int{
 	162, -1000, 162, -1000, 135, -1000, -1000, 315, 204, 478,
 	-1000, -1000, 73, 204, -1000, -1000, -51, -1000, 26, -1000,
 	483, -1000, -1000, 355, 302, 404, 25, -31, 380, 41,
@@ -420,8 +426,8 @@ var yyPact = [...]int{
 	446, 44, -1000, -1000, -1000, -43, 415, 204, -1000, 229,
 	-15, -1000, -1000, -1000, -1000, 204, -1000,
 }
-var yyPgo = [...]int{
 
+var yyPgo = [...]int{
 	0, 665, 664, 496, 22, 663, 662, 661, 660, 659,
 	12, 658, 657, 654, 285, 653, 46, 648, 647, 646,
 	645, 412, 642, 641, 638, 634, 627, 626, 625, 623,
@@ -439,8 +445,8 @@ var yyPgo = [...]int{
 	9, 6, 511, 510, 509, 508, 24, 507, 506, 505,
 	11, 503, 10, 501, 500, 495, 494, 1,
 }
-var yyR1 = [...]int{
 
+var yyR1 = [...]int{
 	0, 1, 1, 2, 2, 3, 5, 5, 6, 6,
 	8, 8, 11, 7, 7, 12, 12, 13, 13, 14,
 	15, 15, 16, 16, 16, 17, 17, 17, 17, 17,
@@ -478,8 +484,8 @@ var yyR1 = [...]int{
 	146, 148, 148, 150, 147, 147, 149, 149, 151, 151,
 	152, 153, 153, 155, 154, 154, 156, 156, 157,
 }
-var yyR2 = [...]int{
 
+var yyR2 = [...]int{
 	0, 1, 0, 1, 2, 9, 3, 0, 1, 1,
 	1, 0, 3, 0, 2, 1, 0, 1, 2, 3,
 	1, 3, 1, 1, 1, 1, 1, 1, 1, 1,
@@ -517,8 +523,8 @@ var yyR2 = [...]int{
 	7, 1, 3, 1, 2, 1, 1, 0, 1, 2,
 	9, 2, 0, 1, 4, 0, 1, 3, 1,
 }
-var yyChk = [...]int{
 
+var yyChk = [...]int{
 	-1000, -1, -2, -3, -4, 96, -3, -5, 101, -6,
 	19, 70, -10, -124, -125, -36, -4, 58, 45, 96,
 	12, 102, -125, 109, 105, 8, 45, 58, -7, 26,
@@ -584,8 +590,8 @@ var yyChk = [...]int{
 	-154, 15, -155, 45, 45, 58, -68, 101, 106, 21,
 	-156, -157, -69, -46, 102, 104, -157,
 }
-var yyDef = [...]int{
 
+var yyDef = [...]int{
 	2, -2, 1, 3, 7, 49, 4, 0, 0, 0,
 	8, 9, 0, 291, 292, 294, 0, 296, 79, -2,
 	0, 6, 293, 0, 0, 13, 295, 0, 11, 0,
@@ -651,8 +657,8 @@ var yyDef = [...]int{
 	263, 0, 351, 353, 269, 0, 0, 0, 302, 0,
 	0, 356, 358, 350, 354, 0, 357,
 }
-var yyTok1 = [...]int{
 
+var yyTok1 = [...]int{
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
@@ -667,8 +673,8 @@ var yyTok1 = [...]int{
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 101, 110, 102,
 }
-var yyTok2 = [...]int{
 
+var yyTok2 = [...]int{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
@@ -680,6 +686,7 @@ var yyTok2 = [...]int{
 	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
 	92, 93, 94, 95, 96, 97, 98, 99, 100,
 }
+
 var yyTok3 = [...]int{
 	0,
 }
@@ -1023,7 +1030,7 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:161
+//line parser.y:167
 		{
 			// Add modules to the module map stored in the lexer
 			for _, m := range yyVAL.modules {
@@ -1037,13 +1044,14 @@ yydefault:
 		}
 	case 5:
 		yyDollar = yyS[yypt-9 : yypt+1]
-//line parser.y:180
+//line parser.y:186
 		{
 			m := &parseModule{
 				imports:    yyDollar[7].imports,
 				name:       yyDollar[1].val,
 				objectTree: []*parseObject{},
 				orphans:    []*parseObject{},
+				types:      yyDollar[8].types,
 			}
 			for _, o := range yyDollar[8].objects {
 				m.objectTree = append(m.objectTree, o)
@@ -1056,49 +1064,49 @@ yydefault:
 		}
 	case 10:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:207
+//line parser.y:214
 		{
 			yyVAL.imports = yyDollar[1].imports
 		}
 	case 11:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line parser.y:211
+//line parser.y:218
 		{
 			yyVAL.imports = nil
 		}
 	case 12:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:217
+//line parser.y:224
 		{
 			yyVAL.imports = yyDollar[2].imports
 		}
 	case 15:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:227
+//line parser.y:234
 		{
 			yyVAL.imports = yyDollar[1].imports
 		}
 	case 16:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line parser.y:231
+//line parser.y:238
 		{
 			yyVAL.imports = nil
 		}
 	case 17:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:237
+//line parser.y:244
 		{
 			yyVAL.imports = yyDollar[1].imports
 		}
 	case 18:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:241
+//line parser.y:248
 		{
 			yyVAL.imports = append(yyDollar[1].imports, yyDollar[2].imports...)
 		}
 	case 19:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:247
+//line parser.y:254
 		{
 			yyVAL.imports = []Import{}
 			for _, id := range yyDollar[1].importIDs {
@@ -1108,115 +1116,117 @@ yydefault:
 		}
 	case 20:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:257
+//line parser.y:264
 		{
 			yyVAL.importIDs = []string{yyDollar[1].token.literal}
 		}
 	case 21:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:261
+//line parser.y:268
 		{
 			yyVAL.importIDs = append(yyDollar[1].importIDs, yyDollar[3].token.literal)
 		}
 	case 49:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:302
+//line parser.y:309
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
 	case 52:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:312
+//line parser.y:319
 		{
 			(&yyVAL).addObject(yyDollar[1].object)
+			(&yyVAL).addTypes(yyDollar[1].types)
 		}
 	case 53:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:316
+//line parser.y:324
 		{
 			(&yyVAL).addObject(yyDollar[2].object)
+			(&yyVAL).addTypes(yyDollar[2].types)
 		}
 	case 54:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:322
+//line parser.y:331
 		{
 			(&yyVAL).setDecl(declTypeAssignment)
 		}
 	case 55:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:326
+//line parser.y:335
 		{
 			(&yyVAL).setDecl(declValueAssignment)
 		}
 	case 56:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:330
+//line parser.y:339
 		{
 			(&yyVAL).setDecl(declIdentity)
 		}
 	case 57:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:334
+//line parser.y:343
 		{
 			(&yyVAL).setDecl(declObjectType)
 		}
 	case 58:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:338
+//line parser.y:347
 		{
 			(&yyVAL).setDecl(declTrapType)
 		}
 	case 59:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:342
+//line parser.y:351
 		{
 			(&yyVAL).setDecl(declNotificationType)
 		}
 	case 60:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:346
+//line parser.y:355
 		{
 			(&yyVAL).setDecl(declModuleIdentity)
 		}
 	case 61:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:350
+//line parser.y:359
 		{
 			(&yyVAL).setDecl(declModuleCompliance)
 		}
 	case 62:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:354
+//line parser.y:363
 		{
 			(&yyVAL).setDecl(declObjectGroup)
 		}
 	case 63:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:358
+//line parser.y:367
 		{
 			(&yyVAL).setDecl(declNotificationGroup)
 		}
 	case 64:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:362
+//line parser.y:371
 		{
 			(&yyVAL).setDecl(declAgentCapabilities)
 		}
 	case 79:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:388
+//line parser.y:397
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
 	case 80:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:392
+//line parser.y:401
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
 	case 81:
 		yyDollar = yyS[yypt-7 : yypt+1]
-//line parser.y:398
+//line parser.y:407
 		{
 			yyVAL.object = &parseObject{
 				object: &Object{
@@ -1225,29 +1235,74 @@ yydefault:
 				},
 			}
 		}
+	case 82:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:418
+		{
+			yyVAL.types = nil
+			if yyDollar[3].syntax != nil {
+				yyVAL.types = map[string]*Syntax{yyDollar[1].token.literal: yyDollar[3].syntax}
+			}
+		}
 	case 98:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:437
+//line parser.y:452
 		{
 			yyVAL.table = yyDollar[1].table
+			yyVAL.syntax = yyDollar[1].syntax
 		}
 	case 99:
 		yyDollar = yyS[yypt-9 : yypt+1]
-//line parser.y:441
+//line parser.y:457
 		{
 			yyVAL.table = yyDollar[9].table
+			yyVAL.syntax = yyDollar[9].syntax
+			if yyVAL.syntax != nil {
+				yyVAL.syntax.DisplayHint = yyDollar[2].val
+			}
 			yyVAL.status = strToStatus(yyDollar[4].val)
 			yyVAL.description = yyDollar[6].val
 		}
+	case 100:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:467
+		{
+			yyVAL.syntax = nil
+		}
 	case 101:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:450
+//line parser.y:473
 		{
 			yyVAL.table = true
 		}
+	case 108:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:493
+		{
+			yyVAL.syntax = &Syntax{Base: BaseBits, Enums: yyDollar[3].enums}
+		}
+	case 112:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:504
+		{
+			yyVAL.enums = yyDollar[1].enums
+		}
+	case 113:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:508
+		{
+			yyVAL.enums = append(yyDollar[1].enums, yyDollar[3].enums...)
+		}
+	case 114:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:514
+		{
+			yyVAL.enums = []NamedNumber{{Name: yyDollar[1].token.literal,
+				Value: rangeValue(yyDollar[3].token.literal)}}
+		}
 	case 115:
 		yyDollar = yyS[yypt-11 : yypt+1]
-//line parser.y:485
+//line parser.y:521
 		{
 			yyVAL.object = &parseObject{
 				object: &Object{
@@ -1261,16 +1316,18 @@ yydefault:
 		}
 	case 116:
 		yyDollar = yyS[yypt-21 : yypt+1]
-//line parser.y:499
+//line parser.y:535
 		{
 			yyVAL.object = &parseObject{
 				object: &Object{
 					Access:      strToAccess(yyDollar[6].val),
 					Description: yyDollar[11].val,
+					Implied:     yyDollar[15].implied,
 					Indexes:     yyDollar[15].indexes,
 					Name:        yyDollar[1].token.literal,
 					Oid:         strings.Join(yyDollar[20].subidentifiers, "."),
 					Status:      strToStatus(yyDollar[10].val),
+					Syntax:      yyDollar[4].syntax,
 				},
 				decl:     declObjectType,
 				table:    yyDollar[4].table,
@@ -1279,25 +1336,93 @@ yydefault:
 		}
 	case 117:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:517
+//line parser.y:555
+		{
+			yyVAL.val = yyDollar[2].val
+		}
+	case 119:
+		yyDollar = yyS[yypt-9 : yypt+1]
+//line parser.y:562
+		{
+			// An SMIv1 trap's SNMPv2 notification OID is its
+			// enterprise followed by 0 and its trap number
+			// (RFC 3584 section 3.1).
+			yyVAL.object = &parseObject{
+				object: &Object{
+					Description: yyDollar[6].val,
+					Name:        yyDollar[1].val,
+					Objects:     yyDollar[5].names,
+					Oid:         strings.Join(yyDollar[4].subidentifiers, ".") + ".0." + yyDollar[9].token.literal,
+				},
+				decl: declTrapType,
+			}
+		}
+	case 120:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:579
+		{
+			yyVAL.names = yyDollar[3].names
+		}
+	case 121:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.y:583
+		{
+			yyVAL.names = nil
+		}
+	case 122:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:589
+		{
+			yyVAL.names = []string{strings.Join(yyDollar[1].subidentifiers, ".")}
+		}
+	case 123:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:593
+		{
+			yyVAL.names = append(yyDollar[1].names, strings.Join(yyDollar[3].subidentifiers, "."))
+		}
+	case 125:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:602
 		{
 			yyVAL.val = yyDollar[2].val
 		}
+	case 126:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.y:606
+		{
+			yyVAL.val = ""
+		}
 	case 149:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:588
+//line parser.y:658
 		{
 			yyVAL.val = yyDollar[2].token.literal
 		}
 	case 150:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:592
+//line parser.y:662
 		{
 			yyVAL.val = yyDollar[2].token.literal
 		}
+	case 151:
+		yyDollar = yyS[yypt-12 : yypt+1]
+//line parser.y:668
+		{
+			yyVAL.object = &parseObject{
+				object: &Object{
+					Description: yyDollar[7].val,
+					Name:        yyDollar[1].token.literal,
+					Objects:     yyDollar[3].names,
+					Oid:         strings.Join(yyDollar[11].subidentifiers, "."),
+					Status:      strToStatus(yyDollar[5].val),
+				},
+				decl: declNotificationType,
+			}
+		}
 	case 152:
 		yyDollar = yyS[yypt-16 : yypt+1]
-//line parser.y:602
+//line parser.y:684
 		{
 			yyVAL.object = &parseObject{
 				object: &Object{
@@ -1307,51 +1432,326 @@ yydefault:
 				},
 			}
 		}
-	case 243:
+	case 161:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:712
+		{
+			yyVAL.syntax = yyDollar[2].syntax
+		}
+	case 162:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:716
+		{
+			yyVAL.syntax = nil
+		}
+	case 163:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:720
+		{
+			yyVAL.syntax = &Syntax{TextualConvention: yyDollar[1].token.literal}
+		}
+	case 164:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:724
+		{
+			yyVAL.syntax = nil
+		}
+	case 171:
 		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:742
+		{
+			yyVAL.syntax = &Syntax{Base: BaseInteger}
+		}
+	case 172:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:746
+		{
+			yyVAL.syntax = &Syntax{Base: BaseInteger, Ranges: yyDollar[2].ranges}
+		}
+	case 173:
+		yyDollar = yyS[yypt-2 : yypt+1]
 //line parser.y:750
+		{
+			yyVAL.syntax = &Syntax{Base: BaseInteger, Enums: yyDollar[2].enums}
+		}
+	case 174:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:754
+		{
+			yyVAL.syntax = &Syntax{Base: BaseInteger}
+		}
+	case 175:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:758
+		{
+			yyVAL.syntax = &Syntax{Base: BaseInteger, Ranges: yyDollar[2].ranges}
+		}
+	case 176:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:762
+		{
+			yyVAL.syntax = &Syntax{TextualConvention: yyDollar[1].token.literal,
+				Enums: yyDollar[2].enums}
+		}
+	case 177:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:767
+		{
+			yyVAL.syntax = &Syntax{TextualConvention: yyDollar[3].token.literal,
+				Enums: yyDollar[4].enums}
+		}
+	case 178:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:772
+		{
+			yyVAL.syntax = &Syntax{TextualConvention: yyDollar[1].token.literal,
+				Ranges: yyDollar[2].ranges}
+		}
+	case 179:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:777
+		{
+			yyVAL.syntax = &Syntax{TextualConvention: yyDollar[3].token.literal,
+				Ranges: yyDollar[4].ranges}
+		}
+	case 180:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:782
+		{
+			yyVAL.syntax = &Syntax{Base: BaseOctetString}
+		}
+	case 181:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:786
+		{
+			yyVAL.syntax = &Syntax{Base: BaseOctetString, Sizes: yyDollar[3].ranges}
+		}
+	case 182:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:790
+		{
+			yyVAL.syntax = &Syntax{TextualConvention: yyDollar[1].token.literal,
+				Sizes: yyDollar[2].ranges}
+		}
+	case 183:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:795
+		{
+			yyVAL.syntax = &Syntax{TextualConvention: yyDollar[3].token.literal,
+				Sizes: yyDollar[4].ranges}
+		}
+	case 184:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:800
+		{
+			yyVAL.syntax = &Syntax{Base: BaseObjectIdentifier}
+		}
+	case 198:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:823
+		{
+			yyVAL.syntax = &Syntax{Base: BaseIPAddress}
+		}
+	case 199:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:827
+		{
+			yyVAL.syntax = &Syntax{Base: BaseCounter32}
+		}
+	case 200:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:831
+		{
+			yyVAL.syntax = &Syntax{Base: BaseGauge32}
+		}
+	case 201:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:835
+		{
+			yyVAL.syntax = &Syntax{Base: BaseGauge32, Ranges: yyDollar[2].ranges}
+		}
+	case 202:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:839
+		{
+			yyVAL.syntax = &Syntax{Base: BaseUnsigned32}
+		}
+	case 203:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:843
+		{
+			yyVAL.syntax = &Syntax{Base: BaseUnsigned32, Ranges: yyDollar[2].ranges}
+		}
+	case 204:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:847
+		{
+			yyVAL.syntax = &Syntax{Base: BaseTimeTicks}
+		}
+	case 205:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:851
+		{
+			yyVAL.syntax = &Syntax{Base: BaseOpaque}
+		}
+	case 206:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:855
+		{
+			yyVAL.syntax = &Syntax{Base: BaseOpaque, Sizes: yyDollar[2].ranges}
+		}
+	case 207:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:859
+		{
+			yyVAL.syntax = &Syntax{Base: BaseCounter64}
+		}
+	case 208:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:863
+		{
+			yyVAL.syntax = &Syntax{Base: BaseInteger64}
+		}
+	case 209:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:867
+		{
+			yyVAL.syntax = &Syntax{Base: BaseInteger64, Ranges: yyDollar[2].ranges}
+		}
+	case 210:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:871
+		{
+			yyVAL.syntax = &Syntax{Base: BaseUnsigned64}
+		}
+	case 211:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:875
+		{
+			yyVAL.syntax = &Syntax{Base: BaseUnsigned64, Ranges: yyDollar[2].ranges}
+		}
+	case 225:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:898
+		{
+			yyVAL.ranges = yyDollar[2].ranges
+		}
+	case 226:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.y:904
+		{
+			yyVAL.ranges = yyDollar[4].ranges
+		}
+	case 227:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:910
+		{
+			yyVAL.ranges = yyDollar[1].ranges
+		}
+	case 228:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:914
+		{
+			yyVAL.ranges = append(yyDollar[1].ranges, yyDollar[3].ranges...)
+		}
+	case 229:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:920
+		{
+			v := rangeValue(yyDollar[1].token.literal)
+			yyVAL.ranges = []Range{{Min: v, Max: v}}
+		}
+	case 230:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:925
+		{
+			yyVAL.ranges = []Range{{Min: rangeValue(yyDollar[1].token.literal),
+				Max: rangeValue(yyDollar[3].token.literal)}}
+		}
+	case 237:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:940
+		{
+			yyVAL.enums = yyDollar[2].enums
+		}
+	case 238:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:946
+		{
+			yyVAL.enums = yyDollar[1].enums
+		}
+	case 239:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:950
+		{
+			yyVAL.enums = append(yyDollar[1].enums, yyDollar[3].enums...)
+		}
+	case 240:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:956
+		{
+			yyVAL.enums = []NamedNumber{{Name: yyDollar[1].token.literal,
+				Value: rangeValue(yyDollar[3].token.literal)}}
+		}
+	case 243:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:967
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
+	case 245:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:976
+		{
+			yyVAL.val = yyDollar[2].val
+		}
+	case 246:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.y:980
+		{
+			yyVAL.val = ""
+		}
 	case 250:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line parser.y:770
+//line parser.y:993
 		{
 			yyVAL.augments = ""
 		}
 	case 251:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line parser.y:774
+//line parser.y:997
 		{
 			yyVAL.augments = yyDollar[3].subidentifiers[0]
 		}
 	case 252:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line parser.y:778
+//line parser.y:1001
 		{
 			yyVAL.augments = ""
 		}
 	case 253:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line parser.y:782
+//line parser.y:1005
 		{
 			yyVAL.augments = ""
 		}
 	case 254:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line parser.y:788
+//line parser.y:1011
 		{
 			yyVAL.indexes = yyDollar[3].indexes
+			yyVAL.implied = yyDollar[3].implied
 		}
 	case 255:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line parser.y:792
+//line parser.y:1016
 		{
 			yyVAL.indexes = nil
+			yyVAL.implied = false
 		}
 	case 256:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:798
+//line parser.y:1023
 		{
 			if yyDollar[1].val != "" {
 				yyVAL.indexes = []string{yyDollar[1].val}
@@ -1359,87 +1759,120 @@ yydefault:
 		}
 	case 257:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:804
+//line parser.y:1029
 		{
 			if yyDollar[3].val != "" {
 				yyVAL.indexes = append(yyDollar[1].indexes, yyDollar[3].val)
 			}
+			yyVAL.implied = yyDollar[3].implied
+		}
+	case 258:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.y:1038
+		{
+			yyVAL.val = strings.Join(yyDollar[2].subidentifiers, " ")
+			yyVAL.implied = true
 		}
 	case 259:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:813
+//line parser.y:1043
 		{
 			yyVAL.val = strings.Join(yyDollar[1].subidentifiers, " ")
+			yyVAL.implied = false
+		}
+	case 279:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.y:1093
+		{
+			yyVAL.names = yyDollar[3].names
+		}
+	case 280:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.y:1097
+		{
+			yyVAL.names = nil
+		}
+	case 282:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.y:1106
+		{
+			yyVAL.names = []string{strings.Join(yyDollar[1].subidentifiers, ".")}
+		}
+	case 283:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.y:1110
+		{
+			yyVAL.names = append(yyDollar[1].names, strings.Join(yyDollar[3].subidentifiers, "."))
 		}
 	case 289:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:886
+//line parser.y:1129
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
 	case 292:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:898
+//line parser.y:1141
 		{
 			yyVAL.subidentifiers = []string{yyDollar[1].val}
 		}
 	case 293:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line parser.y:902
+//line parser.y:1145
 		{
 			yyVAL.subidentifiers = append(yyDollar[1].subidentifiers, yyDollar[2].val)
 		}
 	case 294:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:908
+//line parser.y:1151
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
 	case 295:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line parser.y:912
+//line parser.y:1155
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
 	case 296:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line parser.y:916
+//line parser.y:1159
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
 	case 297:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line parser.y:920
+//line parser.y:1163
 		{
 			yyVAL.val = yyDollar[3].token.literal
 		}
 	case 298:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line parser.y:924
+//line parser.y:1167
 		{
 			yyVAL.val = yyDollar[1].token.literal
 		}
 	case 304:
 		yyDollar = yyS[yypt-12 : yypt+1]
-//line parser.y:941
+//line parser.y:1184
 		{
 			// XXX TODO
 		}
 	case 305:
 		yyDollar = yyS[yypt-12 : yypt+1]
-//line parser.y:947
+//line parser.y:1190
 		{
 			// XXX TODO
 		}
 	case 306:
 		yyDollar = yyS[yypt-12 : yypt+1]
-//line parser.y:953
+//line parser.y:1196
 		{
 			/// XXX TODO
 		}
 	case 335:
 		yyDollar = yyS[yypt-14 : yypt+1]
-//line parser.y:1019
+//line parser.y:1262
 		{
 			// XXX TODO
 		}
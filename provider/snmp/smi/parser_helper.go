@@ -7,9 +7,13 @@ package smi
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi/mibs"
 )
 
 func (po *parseObject) setKind() {
@@ -116,6 +120,41 @@ func (yys *yySymType) addModule(module *parseModule) {
 	yys.modules = append(yys.modules, module)
 }
 
+func (yys *yySymType) addTypes(types map[string]*Syntax) {
+	if yys.types == nil {
+		yys.types = make(map[string]*Syntax)
+	}
+	for name, syntax := range types {
+		yys.types[name] = syntax
+	}
+}
+
+// rangeValue returns the value of a range or size bound, which may be
+// a decimal, hex, or binary literal. Values that don't fit in an
+// int64 are clamped.
+func rangeValue(s string) int64 {
+	base := 10
+	if n := len(s); n >= 3 && s[0] == '\'' && s[n-2] == '\'' {
+		switch s[n-1] {
+		case 'h', 'H':
+			base = 16
+		case 'b', 'B':
+			base = 2
+		}
+		s = s[1 : n-2]
+		if s == "" {
+			return 0
+		}
+	}
+	if v, err := strconv.ParseInt(s, base, 64); err == nil {
+		return v
+	}
+	if strings.HasPrefix(s, "-") {
+		return math.MinInt64
+	}
+	return math.MaxInt64
+}
+
 func (yys *yySymType) setDecl(d decl) {
 	if yys.object != nil {
 		yys.object.decl = d
@@ -229,12 +268,16 @@ func moduleUpgrade(module, object string) string {
 }
 
 func parseFile(filename string) (map[string]*parseModule, error) {
-	yyErrorVerbose = true
 	b, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	lx, err := newLexer(string(b))
+	return parseString(string(b))
+}
+
+func parseString(text string) (map[string]*parseModule, error) {
+	yyErrorVerbose = true
+	lx, err := newLexer(text)
 	if err != nil {
 		return nil, err
 	}
@@ -244,8 +287,9 @@ func parseFile(filename string) (map[string]*parseModule, error) {
 	return lx.modules, nil
 }
 
-func parseFiles(files ...string) (map[string]*parseModule, error) {
-	modules := make(map[string]*parseModule)
+// walkMIBFiles calls fn for each MIB file in the specified files and
+// directories.
+func walkMIBFiles(files []string, fn func(path string, info os.FileInfo) error) error {
 	for _, f := range files {
 		err := filepath.Walk(f,
 			func(path string, info os.FileInfo, err error) error {
@@ -257,20 +301,60 @@ func parseFiles(files ...string) (map[string]*parseModule, error) {
 				}
 				// Don't try to parse files with extensions other than .mib/.MIB
 				ext := filepath.Ext(path)
-				if ext != "" && strings.ToLower(ext) != "mib" {
+				if ext != "" && strings.ToLower(ext) != ".mib" {
 					return nil
 				}
-				m, err := parseFile(path)
-				if err != nil {
-					return err
-				}
-				for k, v := range m {
-					modules[k] = v
-				}
-				return nil
+				return fn(path, info)
 			})
 		if err != nil {
-			return nil, err
+			return err
+		}
+	}
+	return nil
+}
+
+// parseBundled parses the MIB files built in by the mibs package.
+func parseBundled() (map[string]*parseModule, error) {
+	modules := make(map[string]*parseModule)
+	for _, name := range mibs.Names() {
+		text, _ := mibs.File(name)
+		m, err := parseString(text)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing bundled MIB %s: %v", name, err)
+		}
+		for k, v := range m {
+			modules[k] = v
+		}
+	}
+	return modules, nil
+}
+
+// parseFiles parses the bundled MIBs and then the specified files and
+// directories. A module replaces any module of the same name parsed
+// before it, so the specified files take precedence over the bundled
+// MIBs, and later files over earlier ones. Each file is parsed on its
+// own, so that a lenient Store can skip those it can't read or parse.
+func (s *store) parseFiles(files []string) (map[string]*parseModule, error) {
+	modules, err := parseBundled()
+	if err != nil {
+		return nil, err
+	}
+	parse := func(path string, info os.FileInfo) error {
+		m, err := parseFile(path)
+		if err != nil {
+			return s.report(path, "", err)
+		}
+		for k, v := range m {
+			v.file = path
+			modules[k] = v
+		}
+		return nil
+	}
+	for _, f := range files {
+		if err := walkMIBFiles([]string{f}, parse); err != nil {
+			if err := s.report(f, "", err); err != nil {
+				return nil, err
+			}
 		}
 	}
 	return modules, nil
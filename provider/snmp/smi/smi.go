@@ -6,21 +6,23 @@ package smi
 
 import (
 	"fmt"
-
-	// Ensure mibs package doesn't get pruned by dependency management systems
-	_ "github.com/aristanetworks/cloudvision-go/provider/snmp/smi/mibs"
 )
 
-// Object describes an SMI object.
+// Object describes an SMI object. For a notification, Objects lists
+// the objects in its OBJECTS or VARIABLES clause, whose values its
+// varbinds carry.
 type Object struct {
 	Access      Access
 	Description string
 	Indexes     []string
+	Implied     bool
 	Kind        Kind
 	Module      string
 	Name        string
 	Oid         string
 	Status      Status
+	Syntax      *Syntax
+	Objects     []string
 	Parent      *Object
 	Children    []*Object
 }
@@ -34,9 +36,123 @@ func (o *Object) String() string {
 		s += fmt.Sprintf(", Indexes: %v", o.Indexes)
 	}
 	s += fmt.Sprintf(", Kind: %s", o.Kind)
+	if len(o.Objects) > 0 {
+		s += fmt.Sprintf(", Objects: %v", o.Objects)
+	}
+	if o.Syntax != nil {
+		s += fmt.Sprintf(", Syntax: %s", o.Syntax)
+	}
 	return s + "}"
 }
 
+// Syntax describes the SYNTAX of an SMI object or textual convention.
+// Base is the underlying SMI type, once any textual conventions
+// have been resolved, and TextualConvention is the name of the type
+// the object was declared with, if it isn't a base type. Sizes holds
+// the permitted lengths of a string type and Ranges the permitted
+// values of an integer type. Enums holds the named numbers of an
+// enumerated INTEGER or the named bits of BITS. Restrictions not
+// given by the object itself, and the DISPLAY-HINT, are inherited
+// from its textual convention.
+type Syntax struct {
+	Base              BaseType
+	TextualConvention string
+	DisplayHint       string
+	Sizes             []Range
+	Ranges            []Range
+	Enums             []NamedNumber
+}
+
+func (s *Syntax) String() string {
+	str := s.Base.String()
+	if s.TextualConvention != "" {
+		str = s.TextualConvention + " (" + str + ")"
+	}
+	if len(s.Sizes) > 0 {
+		str += fmt.Sprintf(" SIZE%v", s.Sizes)
+	}
+	if len(s.Ranges) > 0 {
+		str += fmt.Sprintf(" %v", s.Ranges)
+	}
+	return str
+}
+
+// FixedSize returns the length of a string type if it only permits
+// one length, and whether it does.
+func (s *Syntax) FixedSize() (int, bool) {
+	if len(s.Sizes) != 1 || s.Sizes[0].Min != s.Sizes[0].Max {
+		return 0, false
+	}
+	return int(s.Sizes[0].Min), true
+}
+
+// Range is a range of permitted values or sizes.
+type Range struct {
+	Min int64
+	Max int64
+}
+
+func (r Range) String() string {
+	if r.Min == r.Max {
+		return fmt.Sprintf("%d", r.Min)
+	}
+	return fmt.Sprintf("%d..%d", r.Min, r.Max)
+}
+
+// NamedNumber is a named value of an enumerated INTEGER, or a named
+// bit of BITS.
+type NamedNumber struct {
+	Name  string
+	Value int64
+}
+
+func (n NamedNumber) String() string {
+	return fmt.Sprintf("%s(%d)", n.Name, n.Value)
+}
+
+// BaseType is one of the SMI base types.
+type BaseType int
+
+const (
+	BaseUnknown BaseType = iota
+	BaseInteger
+	BaseOctetString
+	BaseObjectIdentifier
+	BaseBits
+	BaseIPAddress
+	BaseCounter32
+	BaseGauge32
+	BaseUnsigned32
+	BaseTimeTicks
+	BaseOpaque
+	BaseCounter64
+	BaseInteger64
+	BaseUnsigned64
+)
+
+func (b BaseType) String() string {
+	m := map[BaseType]string{
+		BaseUnknown:          "Unknown",
+		BaseInteger:          "INTEGER",
+		BaseOctetString:      "OCTET STRING",
+		BaseObjectIdentifier: "OBJECT IDENTIFIER",
+		BaseBits:             "BITS",
+		BaseIPAddress:        "IpAddress",
+		BaseCounter32:        "Counter32",
+		BaseGauge32:          "Gauge32",
+		BaseUnsigned32:       "Unsigned32",
+		BaseTimeTicks:        "TimeTicks",
+		BaseOpaque:           "Opaque",
+		BaseCounter64:        "Counter64",
+		BaseInteger64:        "Integer64",
+		BaseUnsigned64:       "Unsigned64",
+	}
+	if p, ok := m[b]; ok {
+		return p
+	}
+	return "Unknown"
+}
+
 // Import describes an imported object and the module it's imported from.
 type Import struct {
 	Object string
@@ -191,9 +307,11 @@ type parseObject struct {
 
 type parseModule struct {
 	name       string
+	file       string
 	objectTree []*parseObject
 	orphans    []*parseObject
 	imports    []Import
+	types      map[string]*Syntax
 }
 
 type decl int
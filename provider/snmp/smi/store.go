@@ -6,6 +6,7 @@ package smi
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,44 +16,116 @@ import (
 // for objects.
 type Store interface {
 	GetObject(oid string) *Object
+	// GetNotification returns the NOTIFICATION-TYPE or TRAP-TYPE
+	// with the specified OID. Use TrapOID to get the OID of an
+	// SMIv1 trap.
+	GetNotification(oid string) *Object
+	// Diagnostics returns the problems found loading a lenient
+	// Store's MIBs.
+	Diagnostics() []Diagnostic
 }
 
 // store implements the Store interface.
 type store struct {
-	lock    *sync.RWMutex
-	modules map[string]*Module
-	oids    map[string]*Object
-	names   map[string]*Object
-	known   map[string]*Object
-}
+	lock        *sync.RWMutex
+	modules     map[string]*Module
+	oids        map[string]*Object
+	names       map[string]*Object
+	known       map[string]*Object
+	types       map[string]map[string]*Syntax
+	lenient     bool
+	diagnostics []Diagnostic
 
-// NewStore returns a Store.
-func NewStore(files ...string) (Store, error) {
-	parseModules, err := parseFiles(files...)
-	if err != nil {
-		return nil, err
-	}
+	// shared is set for a Store shared by all users of its MIBs,
+	// which remembers the objects looked up by name or OID but not
+	// by the OIDs of their instances.
+	shared bool
+}
 
-	store := &store{
+func newStore() *store {
+	return &store{
 		lock:    &sync.RWMutex{},
 		modules: make(map[string]*Module),
 		oids:    make(map[string]*Object),
 		names:   make(map[string]*Object),
 		known:   make(map[string]*Object),
+		types:   make(map[string]map[string]*Syntax),
+	}
+}
+
+// NewStore returns a Store of the MIBs bundled with this package and
+// the modules in the specified MIB files and directories. A module in
+// the specified files replaces any bundled module of the same name,
+// and if several of the files define the same module, the last one
+// parsed wins. With no files, the Store holds just the bundled MIBs.
+func NewStore(files ...string) (Store, error) {
+	store := newStore()
+	if err := store.load(files); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// NewLenientStore returns a Store like NewStore does, except that
+// rather than failing on the first problem with the MIBs, it skips
+// files that can't be read or parsed and resolves as much of each
+// module as it can, recording each problem in the Store's
+// Diagnostics.
+func NewLenientStore(files ...string) (Store, error) {
+	store := newStore()
+	store.lenient = true
+	if err := store.load(files); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *store) load(files []string) error {
+	parseModules, err := s.parseFiles(files)
+	if err != nil {
+		return err
 	}
 
 	// After initially building the parse tree, there are certain
 	// fixes we have to make that are easier to do once the store
 	// already exists, such as resolving OIDs and certain indexes.
+	names := make([]string, 0, len(parseModules))
+	for name := range parseModules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 	resolvedModules := map[string]bool{}
-	for moduleName, pm := range parseModules {
-		if err := resolveModule(moduleName, store, parseModules,
+	for _, moduleName := range names {
+		if err := resolveModule(moduleName, s, parseModules,
 			resolvedModules); err != nil {
-			return nil, err
+			return err
 		}
-		store.modules[moduleName] = createModule(pm)
+		s.modules[moduleName] = createModule(parseModules[moduleName])
 	}
-	return store, nil
+	return nil
+}
+
+// report handles a problem with a MIB file or module. A lenient Store
+// records it in its diagnostics and carries on, while a strict one
+// fails with it.
+func (s *store) report(file, module string, err error) error {
+	if !s.lenient {
+		if module != "" {
+			return fmt.Errorf("Error in module '%s': %v", module, err)
+		}
+		return err
+	}
+	s.diagnostics = append(s.diagnostics,
+		Diagnostic{File: file, Module: module, Message: err.Error()})
+	return nil
+}
+
+// Diagnostics returns the problems found loading a lenient Store's
+// MIBs. A strict Store has none, since it fails on the first.
+func (s *store) Diagnostics() []Diagnostic {
+	d := make([]Diagnostic, len(s.diagnostics))
+	copy(d, s.diagnostics)
+	return d
 }
 
 func (s *store) checkKnown(oid string) *Object {
@@ -114,7 +187,9 @@ func (s *store) GetObject(oid string) *Object {
 						return nil
 					}
 				}
-				s.updateKnown(origOid, o)
+				if i == len(ss) || !s.shared {
+					s.updateKnown(origOid, o)
+				}
 				return o
 			}
 		}
@@ -147,8 +222,8 @@ func resolveOID(po *parseObject, store *store) error {
 			} else {
 				p, ok := store.names[subid]
 				if !ok {
-					return fmt.Errorf("Could not find OID for '%s', module '%s'",
-						subid, po.object.Module)
+					return fmt.Errorf("Could not find OID for '%s' in OID of %s",
+						subid, po.object.Name)
 				}
 				newOid = append(newOid, p.Oid)
 			}
@@ -174,20 +249,101 @@ func resolveIndexes(po *parseObject, store *store) error {
 	}
 	po.object.Indexes = make([]string, len(ao.Indexes))
 	copy(po.object.Indexes, ao.Indexes)
+	po.object.Implied = ao.Implied
 	return nil
 }
 
-func resolveTree(po *parseObject, store *store, keepgoing bool) error {
-	if err := resolveOID(po, store); err != nil && !keepgoing {
-		return err
+// maxTypeDepth limits how many textual conventions we'll follow to
+// get to an object's base type.
+const maxTypeDepth = 16
+
+// lookupType returns the type of the specified name in a module's
+// scope: the module's own type of that name, or else the one it
+// imports. Failing that, it falls back to any module's type of that
+// name, since some MIBs use types without importing them.
+func lookupType(name string, pm *parseModule, store *store) (*Syntax, bool) {
+	if t, ok := pm.types[name]; ok {
+		return t, true
 	}
-	if err := resolveIndexes(po, store); err != nil && !keepgoing {
-		return err
+	for _, imp := range pm.imports {
+		if imp.Object == name {
+			if t, ok := store.types[moduleUpgrade(imp.Module, name)][name]; ok {
+				return t, true
+			}
+		}
+	}
+	modules := make([]string, 0, len(store.types))
+	for m := range store.types {
+		modules = append(modules, m)
+	}
+	sort.Strings(modules)
+	for _, m := range modules {
+		if t, ok := store.types[m][name]; ok {
+			return t, true
+		}
 	}
+	return nil, false
+}
+
+// resolveType follows the chain of types a syntax refers to until it
+// reaches a base type, inheriting any restrictions and display hint
+// it doesn't specify itself.
+func resolveType(syntax *Syntax, pm *parseModule, store *store) {
+	name := syntax.TextualConvention
+	for i := 0; syntax.Base == BaseUnknown && name != "" && i < maxTypeDepth; i++ {
+		t, ok := lookupType(name, pm, store)
+		if !ok {
+			return
+		}
+		if syntax.Sizes == nil {
+			syntax.Sizes = t.Sizes
+		}
+		if syntax.Ranges == nil {
+			syntax.Ranges = t.Ranges
+		}
+		if syntax.Enums == nil {
+			syntax.Enums = t.Enums
+		}
+		if syntax.DisplayHint == "" {
+			syntax.DisplayHint = t.DisplayHint
+		}
+		syntax.Base = t.Base
+		name = t.TextualConvention
+	}
+}
+
+// resolveSyntax fills in the base type, and any restrictions not
+// specified by the object itself, of an object whose syntax is a
+// textual convention.
+func resolveSyntax(po *parseObject, pm *parseModule, store *store) {
+	// A row's syntax names its SEQUENCE type, which isn't a
+	// textual convention.
+	if po.object.Kind == KindRow {
+		po.object.Syntax = nil
+	}
+	if po.object.Syntax != nil {
+		resolveType(po.object.Syntax, pm, store)
+	}
+}
+
+// resolveTree resolves an object and its descendants, passing any
+// problems to report and stopping if it returns an error.
+func resolveTree(po *parseObject, pm *parseModule, store *store,
+	report func(error) error) error {
+	if err := resolveOID(po, store); err != nil {
+		if err := report(err); err != nil {
+			return err
+		}
+	}
+	if err := resolveIndexes(po, store); err != nil {
+		if err := report(err); err != nil {
+			return err
+		}
+	}
+	resolveSyntax(po, pm, store)
 
 	for _, child := range po.children {
-		if err := resolveTree(child, store, keepgoing); err != nil &&
-			!keepgoing {
+		if err := resolveTree(child, pm, store, report); err != nil {
 			return err
 		}
 	}
@@ -209,15 +365,38 @@ func resolveModule(moduleName string, store *store,
 	if !ok {
 		return fmt.Errorf("Can't resolve unparsed module '%s'", moduleName)
 	}
+	// Mark the module resolved up front, so that modules importing
+	// each other don't recurse forever.
+	resolvedModules[moduleName] = true
 
-	// Resolve any modules this module imports.
+	// Resolve any modules this module imports. A lenient Store skips
+	// imports from modules it doesn't have, leaving the objects that
+	// depend on them unresolved.
+	missing := map[string][]string{}
 	for _, imp := range pm.imports {
 		mr := moduleUpgrade(imp.Module, imp.Object)
+		if _, ok := parseModules[mr]; !ok && store.lenient {
+			missing[mr] = append(missing[mr], imp.Object)
+			continue
+		}
 		if err := resolveModule(mr, store, parseModules,
 			resolvedModules); err != nil {
 			return err
 		}
 	}
+	for _, mr := range sortedKeys(missing) {
+		_ = store.report(pm.file, moduleName,
+			fmt.Errorf("Import from unknown module %s: %s", mr,
+				strings.Join(missing[mr], ", ")))
+	}
+
+	// Resolve this module's types in its own scope, since another
+	// module may declare a different type of the same name, and make
+	// them available to its objects and to the modules importing it.
+	for _, syntax := range pm.types {
+		resolveType(syntax, pm, store)
+	}
+	store.types[moduleName] = pm.types
 
 	// Link orphans to parent objects.
 	for _, orphan := range pm.orphans {
@@ -231,18 +410,21 @@ func resolveModule(moduleName string, store *store,
 	}
 
 	// Try twice to resolve each OID, in case they're declared out of
-	// order in the MIB.
-	for pass := 1; pass <= 2; pass++ {
-		for _, obj := range pm.objectTree {
-			if err := resolveTree(obj, store, pass != 2); err != nil &&
-				pass == 2 {
-				return err
-			}
+	// order in the MIB, reporting only what's still unresolved the
+	// second time.
+	ignore := func(error) error { return nil }
+	report := func(err error) error {
+		return store.report(pm.file, moduleName, err)
+	}
+	for _, obj := range pm.objectTree {
+		_ = resolveTree(obj, pm, store, ignore)
+	}
+	for _, obj := range pm.objectTree {
+		if err := resolveTree(obj, pm, store, report); err != nil {
+			return err
 		}
 	}
 
-	resolvedModules[moduleName] = true
-
 	return nil
 }
 
@@ -5,8 +5,14 @@
 package smi
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi/mibs"
 )
 
 type parserTestCase struct {
@@ -419,3 +425,233 @@ func TestParser(t *testing.T) {
 		})
 	}
 }
+
+func TestSyntax(t *testing.T) {
+	store, err := NewStore("mibs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		oid     string
+		syntax  *Syntax
+		implied bool
+	}{
+		{
+			oid:    "sysUpTime",
+			syntax: &Syntax{Base: BaseTimeTicks},
+		},
+		{
+			oid: "ifDescr",
+			syntax: &Syntax{Base: BaseOctetString,
+				TextualConvention: "DisplayString",
+				DisplayHint:       "255a",
+				Sizes:             []Range{{Min: 0, Max: 255}}},
+		},
+		{
+			oid: "ifIndex",
+			syntax: &Syntax{Base: BaseInteger,
+				TextualConvention: "InterfaceIndex",
+				DisplayHint:       "d",
+				Ranges:            []Range{{Min: 1, Max: 2147483647}}},
+		},
+		{
+			oid: "ifOperStatus",
+			syntax: &Syntax{Base: BaseInteger,
+				Enums: []NamedNumber{{"up", 1}, {"down", 2}, {"testing", 3},
+					{"unknown", 4}, {"dormant", 5}, {"notPresent", 6},
+					{"lowerLayerDown", 7}}},
+		},
+		{
+			oid: "ifPhysAddress",
+			syntax: &Syntax{Base: BaseOctetString,
+				TextualConvention: "PhysAddress",
+				DisplayHint:       "1x:"},
+		},
+		{
+			oid: "lldpLocSysCapSupported",
+			syntax: &Syntax{Base: BaseBits,
+				TextualConvention: "LldpSystemCapabilitiesMap",
+				Enums: []NamedNumber{{"other", 0}, {"repeater", 1},
+					{"bridge", 2}, {"wlanAccessPoint", 3}, {"router", 4},
+					{"telephone", 5}, {"docsisCableDevice", 6},
+					{"stationOnly", 7}}},
+		},
+		{
+			oid:    "ipAdEntAddr",
+			syntax: &Syntax{Base: BaseIPAddress},
+		},
+		{
+			// A size restriction on the object overrides its TC's.
+			oid: "lldpRemManAddr",
+			syntax: &Syntax{Base: BaseOctetString,
+				TextualConvention: "LldpManAddress",
+				Sizes:             []Range{{Min: 1, Max: 31}}},
+		},
+		{
+			oid: "hrSWInstalledDate",
+			syntax: &Syntax{Base: BaseOctetString,
+				TextualConvention: "DateAndTime",
+				DisplayHint:       "2d-1d-1d,1d:1d:1d.1d,1a1d:1d",
+				Sizes:             []Range{{Min: 8, Max: 8}, {Min: 11, Max: 11}}},
+		},
+		{
+			oid:    "inetCidrRoutePolicy",
+			syntax: &Syntax{Base: BaseObjectIdentifier},
+		},
+		{
+			oid: "snmpTargetAddrEntry",
+			// The only index of snmpTargetAddrEntry is IMPLIED.
+			implied: true,
+		},
+		{
+			oid: "ipAddressEntry",
+		},
+	} {
+		t.Run(tc.oid, func(t *testing.T) {
+			o := store.GetObject(tc.oid)
+			if o == nil {
+				t.Fatalf("No object for %s", tc.oid)
+			}
+			if !reflect.DeepEqual(o.Syntax, tc.syntax) {
+				t.Fatalf("Expected syntax %v, got %v", tc.syntax, o.Syntax)
+			}
+			if o.Implied != tc.implied {
+				t.Fatalf("Expected Implied %v, got %v", tc.implied, o.Implied)
+			}
+		})
+	}
+}
+
+func TestBundledMIBs(t *testing.T) {
+	bundled, err := NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"ifDescr", "lldpRemManAddr",
+		"sysUpTimeInstance", "hrSWInstalledDate"} {
+		if bundled.GetObject(name) == nil {
+			t.Fatalf("No bundled object for %s", name)
+		}
+	}
+
+	// A module in the specified files replaces the bundled module of
+	// the same name, and can still import from the other bundled
+	// modules.
+	dir, err := ioutil.TempDir("", "smi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	text, ok := mibs.File("IF-MIB")
+	if !ok {
+		t.Fatal("No bundled IF-MIB")
+	}
+	const orig = "A textual string containing information about the"
+	if !strings.Contains(text, orig) {
+		t.Fatalf("Bundled IF-MIB has no %q", orig)
+	}
+	text = strings.Replace(text, orig, "Overridden. "+orig, 1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "IF-MIB"), []byte(text),
+		0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := s.GetObject("ifDescr"); o == nil ||
+		!strings.HasPrefix(o.Description, "Overridden. ") {
+		t.Fatalf("Expected overridden ifDescr, got %v", o)
+	}
+	if o := bundled.GetObject("ifDescr"); strings.HasPrefix(o.Description,
+		"Overridden. ") {
+		t.Fatalf("Expected bundled ifDescr, got %v", o)
+	}
+	if o := s.GetObject("ifPhysAddress"); o == nil || o.Syntax == nil ||
+		o.Syntax.DisplayHint != "1x:" {
+		t.Fatalf("Expected PhysAddress syntax for ifPhysAddress, got %v", o)
+	}
+}
+
+const cycleAMIB = `CYCLE-A-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    enterprises FROM SNMPv2-SMI
+    cycleB FROM CYCLE-B-MIB;
+
+cycleA OBJECT IDENTIFIER ::= { enterprises 99997 }
+END
+`
+
+const cycleBMIB = `CYCLE-B-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    enterprises FROM SNMPv2-SMI
+    cycleA FROM CYCLE-A-MIB;
+
+cycleB OBJECT IDENTIFIER ::= { enterprises 99996 }
+END
+`
+
+func TestLenientStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMIBs(t, dir, map[string]string{
+		"GOOD-MIB.mib": goodMIB,
+		"MISSING-MIB":  missingMIB,
+		"BROKEN-MIB":   "BROKEN-MIB DEFINITIONS ::= BEGIN\nthis isn't SMI\n",
+		"CYCLE-A-MIB":  cycleAMIB,
+		"CYCLE-B-MIB":  cycleBMIB,
+	})
+	nonexistent := filepath.Join(dir, "nonexistent")
+
+	if _, err := NewStore(dir); err == nil {
+		t.Fatal("Expected NewStore to fail on BROKEN-MIB")
+	}
+	s, err := NewLenientStore(nonexistent, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, oid := range map[string]string{
+		"goodObject":  "1.3.6.1.4.1.99999.1",
+		"missingRoot": "1.3.6.1.4.1.99998",
+		"cycleA":      "1.3.6.1.4.1.99997",
+		"cycleB":      "1.3.6.1.4.1.99996",
+		"ifDescr":     "1.3.6.1.2.1.2.2.1.2",
+	} {
+		if o := s.GetObject(name); o == nil || o.Oid != oid {
+			t.Fatalf("Expected %s OID %s, got %v", name, oid, o)
+		}
+	}
+	if o := s.GetObject("fromFoo"); o != nil {
+		t.Fatalf("Expected no fromFoo, got %v", o)
+	}
+
+	diags := s.Diagnostics()
+	files := []string{}
+	for _, d := range diags {
+		files = append(files, filepath.Base(d.File))
+	}
+	expected := []string{"nonexistent", "BROKEN-MIB", "MISSING-MIB",
+		"MISSING-MIB", "MISSING-MIB"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("Expected diagnostics for %v, got %v", expected, diags)
+	}
+
+	// Diagnostics survive the on-disk cache.
+	cacheFile := filepath.Join(dir, "cache", "store.gob")
+	if err := saveStore(cacheFile, "fp", s.(*store)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadStore(cacheFile, "fp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Diagnostics(), diags) {
+		t.Fatalf("Expected cached diagnostics %v, got %v", diags,
+			loaded.Diagnostics())
+	}
+}
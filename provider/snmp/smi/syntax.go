@@ -0,0 +1,223 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package smi
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// EnumName returns the name of the named number with value v, if
+// there is one.
+func (s *Syntax) EnumName(v int64) (string, bool) {
+	for _, e := range s.Enums {
+		if e.Value == v {
+			return e.Name, true
+		}
+	}
+	return "", false
+}
+
+// Format returns the text form of a value of this syntax, as gosnmp
+// would type it. Enumerated integers are given by name, named bits
+// by a space-separated list of the names of the bits set, and other
+// values are formatted according to the DISPLAY-HINT, if any.
+func (s *Syntax) Format(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		if s.Base == BaseBits && len(s.Enums) > 0 {
+			return s.formatBits(b)
+		}
+		if str, ok := formatOctets(s.DisplayHint, b); ok {
+			return str
+		}
+		return string(b)
+	}
+	if i, ok := toInt64(v); ok {
+		if name, ok := s.EnumName(i); ok {
+			return name
+		}
+		if str, ok := formatInteger(s.DisplayHint, i); ok {
+			return str
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch t := v.(type) {
+	case int:
+		return int64(t), true
+	case int8:
+		return int64(t), true
+	case int16:
+		return int64(t), true
+	case int32:
+		return int64(t), true
+	case int64:
+		return t, true
+	case uint:
+		return int64(t), true
+	case uint8:
+		return int64(t), true
+	case uint16:
+		return int64(t), true
+	case uint32:
+		return int64(t), true
+	case uint64:
+		return int64(t), true
+	}
+	return 0, false
+}
+
+// formatBits returns the names of the bits set in a BITS value. Bit 0
+// is the most significant bit of the first octet.
+func (s *Syntax) formatBits(b []byte) string {
+	names := []string{}
+	for _, e := range s.Enums {
+		i, bit := e.Value/8, uint(7-e.Value%8)
+		if e.Value >= 0 && i < int64(len(b)) && b[i]&(1<<bit) != 0 {
+			names = append(names, e.Name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// formatInteger formats an integer according to an INTEGER
+// DISPLAY-HINT, as described in RFC 2579 section 3.1: "d-n" for a
+// decimal with n implied decimal places, or "x", "o", or "b" for
+// hex, octal, or binary.
+func formatInteger(hint string, v int64) (string, bool) {
+	switch {
+	case hint == "x":
+		return strconv.FormatInt(v, 16), true
+	case hint == "o":
+		return strconv.FormatInt(v, 8), true
+	case hint == "b":
+		return strconv.FormatInt(v, 2), true
+	case hint == "d":
+		return strconv.FormatInt(v, 10), true
+	case strings.HasPrefix(hint, "d-"):
+		n, err := strconv.Atoi(hint[2:])
+		if err != nil || n < 0 {
+			return "", false
+		}
+		sign := ""
+		if v < 0 {
+			sign, v = "-", -v
+		}
+		digits := strconv.FormatInt(v, 10)
+		if n == 0 {
+			return sign + digits, true
+		}
+		if len(digits) <= n {
+			digits = strings.Repeat("0", n-len(digits)+1) + digits
+		}
+		return sign + digits[:len(digits)-n] + "." + digits[len(digits)-n:], true
+	}
+	return "", false
+}
+
+// octetSpec is one octet-format specification of an OCTET STRING
+// DISPLAY-HINT.
+type octetSpec struct {
+	repeat     bool
+	length     int
+	format     byte
+	separator  byte
+	terminator byte
+}
+
+func parseOctetHint(hint string) ([]octetSpec, bool) {
+	specs := []octetSpec{}
+	isDelimiter := func(i int) bool {
+		return i < len(hint) && hint[i] != '*' &&
+			(hint[i] < '0' || hint[i] > '9')
+	}
+	for i := 0; i < len(hint); {
+		spec := octetSpec{}
+		if hint[i] == '*' {
+			spec.repeat = true
+			i++
+		}
+		start := i
+		for i < len(hint) && hint[i] >= '0' && hint[i] <= '9' {
+			i++
+		}
+		length, err := strconv.Atoi(hint[start:i])
+		if err != nil || length == 0 || i >= len(hint) ||
+			!strings.ContainsRune("xdoat", rune(hint[i])) {
+			return nil, false
+		}
+		spec.length, spec.format = length, hint[i]
+		i++
+		if isDelimiter(i) {
+			spec.separator = hint[i]
+			i++
+			if spec.repeat && isDelimiter(i) {
+				spec.terminator = hint[i]
+				i++
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, len(specs) > 0
+}
+
+// formatOctets formats an OCTET STRING according to its DISPLAY-HINT,
+// as described in RFC 2579 section 3.1. The last octet-format
+// specification applies to any octets left over once the others have
+// been used.
+func formatOctets(hint string, b []byte) (string, bool) {
+	specs, ok := parseOctetHint(hint)
+	if !ok {
+		return "", false
+	}
+	var sb strings.Builder
+	for i := 0; len(b) > 0; {
+		spec := specs[i]
+		count := 1
+		if spec.repeat {
+			count = int(b[0])
+			b = b[1:]
+		}
+		for c := 0; c < count && len(b) > 0; c++ {
+			n := spec.length
+			if n > len(b) {
+				n = len(b)
+			}
+			sb.WriteString(formatChunk(spec.format, b[:n]))
+			b = b[n:]
+			if len(b) == 0 {
+				break
+			}
+			if c == count-1 && spec.terminator != 0 {
+				sb.WriteByte(spec.terminator)
+			} else if spec.separator != 0 {
+				sb.WriteByte(spec.separator)
+			}
+		}
+		if i < len(specs)-1 {
+			i++
+		}
+	}
+	return sb.String(), true
+}
+
+// formatChunk formats the octets covered by one application of an
+// octet-format specification. Octets displayed as a number are taken
+// as a big-endian unsigned integer.
+func formatChunk(format byte, b []byte) string {
+	switch format {
+	case 'a', 't':
+		return string(b)
+	case 'x':
+		return fmt.Sprintf("%0*x", 2*len(b), new(big.Int).SetBytes(b))
+	case 'o':
+		return new(big.Int).SetBytes(b).Text(8)
+	}
+	return new(big.Int).SetBytes(b).Text(10)
+}
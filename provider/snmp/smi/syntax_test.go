@@ -0,0 +1,112 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package smi
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	store, err := NewStore("mibs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		oid    string
+		syntax *Syntax
+		value  interface{}
+		s      string
+	}{
+		{
+			name:  "DisplayString",
+			oid:   "ifDescr",
+			value: []byte("Ethernet1"),
+			s:     "Ethernet1",
+		},
+		{
+			name:  "PhysAddress",
+			oid:   "ifPhysAddress",
+			value: []byte{0x00, 0x1c, 0x73, 0x0a, 0xbc, 0xde},
+			s:     "00:1c:73:0a:bc:de",
+		},
+		{
+			name:  "DateAndTime",
+			oid:   "hrSWInstalledDate",
+			value: []byte{0x07, 0xe4, 3, 14, 15, 9, 26, 5, '-', 7, 0},
+			s:     "2020-3-14,15:9:26.5,-7:0",
+		},
+		{
+			name:  "enum",
+			oid:   "ifOperStatus",
+			value: 7,
+			s:     "lowerLayerDown",
+		},
+		{
+			name:  "unnamed enum value",
+			oid:   "ifOperStatus",
+			value: 8,
+			s:     "8",
+		},
+		{
+			name:  "enum from textual convention",
+			oid:   "ifType",
+			value: 6,
+			s:     "ethernetCsmacd",
+		},
+		{
+			name:  "BITS",
+			oid:   "lldpLocSysCapSupported",
+			value: []byte{0x28},
+			s:     "bridge router",
+		},
+		{
+			name:  "integer hint with decimal places",
+			value: -1234,
+			syntax: &Syntax{Base: BaseInteger,
+				DisplayHint: "d-2"},
+			s: "-12.34",
+		},
+		{
+			name: "integer hint with leading zeros",
+			syntax: &Syntax{Base: BaseInteger,
+				DisplayHint: "d-3"},
+			value: uint(5),
+			s:     "0.005",
+		},
+		{
+			name:   "hex integer hint",
+			syntax: &Syntax{Base: BaseInteger, DisplayHint: "x"},
+			value:  uint32(255),
+			s:      "ff",
+		},
+		{
+			name: "repeated octet hint with terminator",
+			syntax: &Syntax{Base: BaseOctetString,
+				DisplayHint: "*1d.;1a"},
+			value: []byte{2, 10, 20, 'x', 'y'},
+			s:     "10.20;xy",
+		},
+		{
+			name:   "bad hint",
+			syntax: &Syntax{Base: BaseOctetString, DisplayHint: "1q"},
+			value:  []byte("abc"),
+			s:      "abc",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			syntax := tc.syntax
+			if syntax == nil {
+				o := store.GetObject(tc.oid)
+				if o == nil || o.Syntax == nil {
+					t.Fatalf("No syntax for %s", tc.oid)
+				}
+				syntax = o.Syntax
+			}
+			if s := syntax.Format(tc.value); s != tc.s {
+				t.Fatalf("Expected %q, got %q", tc.s, s)
+			}
+		})
+	}
+}
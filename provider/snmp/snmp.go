@@ -9,18 +9,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/aristanetworks/cloudvision-go/log"
 	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
 	"github.com/aristanetworks/cloudvision-go/provider/openconfig"
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/snmpoc"
 
+	"github.com/gosnmp/gosnmp"
 	"github.com/openconfig/gnmi/proto/gnmi"
-	"github.com/soniah/gosnmp"
 )
 
 const (
@@ -46,13 +48,12 @@ func oidSplitEnd(oid string) (string, string, error) {
 type Snmp struct {
 	client gnmi.GNMIClient
 
-	gsnmp      *gosnmp.GoSNMP // gosnmp object for Snmp's use
-	tgsnmp     *gosnmp.GoSNMP // gosnmp object for translator's use
+	gsnmp      *gosnmp.GoSNMP // gosnmp parameters for the device
+	sessions   *sessionPool   // sessions shared by Snmp and translator
 	mock       bool           // if true, don't do any network init
 	translator *snmpoc.Translator
 
-	// gosnmp can't handle parallel gets, so we also need to lock
-	// access to its connection object.
+	// connectionLock protects network initialization.
 	connectionLock sync.Mutex
 
 	pollInterval time.Duration
@@ -60,9 +61,35 @@ type Snmp struct {
 	initialized  bool
 	deviceID     string
 
-	// List of files or directories to search for supported MIBs.
-	mibs     []string
-	mibStore smi.Store
+	// Poll intervals of particular models, overriding pollInterval.
+	pollIntervals map[string]time.Duration
+
+	// List of files or directories to search for supported MIBs, and
+	// the directory in which to cache the parsed MIBs, if any.
+	mibs        []string
+	mibCacheDir string
+	mibStore    smi.Store
+
+	// User-defined models and mappings to add to the translator's.
+	mappingConfig *snmpoc.MappingConfig
+
+	// Counter handling options passed on to the translator.
+	extendCounters bool
+	counterRates   bool
+
+	// rateClient is the client of the provider returned by
+	// RateProvider, through which counter rates are sent.
+	rateClient gnmi.GNMIClient
+	rateLock   sync.Mutex
+
+	// done is closed when Run first returns.
+	done     chan struct{}
+	doneOnce sync.Once
+
+	// Options for the session pool.
+	sessionCount   int
+	maxRequestRate float64
+	walkStrategy   WalkStrategy
 
 	// Alternative Walk() and Get() for mock testing.
 	getter func([]string) (*gosnmp.SnmpPacket, error)
@@ -70,6 +97,12 @@ type Snmp struct {
 
 	// Alternative time.Now() for mock testing.
 	now func() time.Time
+
+	// Trap/inform reception. trapParams is a copy of the device's
+	// gosnmp parameters for decoding its notifications.
+	trapReceiver *TrapReceiver
+	trapParams   *gosnmp.GoSNMP
+	trapc        chan *gosnmp.SnmpPacket
 }
 
 func (s *Snmp) snmpNetworkInit() error {
@@ -79,13 +112,7 @@ func (s *Snmp) snmpNetworkInit() error {
 
 	s.connectionLock.Lock()
 	defer s.connectionLock.Unlock()
-	err := s.tgsnmp.Connect()
-	if err != nil {
-		return err
-	}
-
-	err = s.gsnmp.Connect()
-
+	err := s.sessions.connect()
 	s.initialized = err == nil
 	return err
 }
@@ -96,8 +123,6 @@ func (s *Snmp) get(oid string) (*gosnmp.SnmpPacket, error) {
 		return nil, errors.New("SNMP getter not set")
 	}
 
-	s.connectionLock.Lock()
-	defer s.connectionLock.Unlock()
 	pkt, err := s.getter([]string{oid})
 	log.Log(s).Debugf("get complete (OID = %s): pkt = %v, err = %v", oid, pkt, err)
 	if err != nil {
@@ -156,8 +181,6 @@ func (s *Snmp) walk(rootOid string, walkFn gosnmp.WalkFunc) error {
 		return errors.New("SNMP walker not set")
 	}
 
-	s.connectionLock.Lock()
-	defer s.connectionLock.Unlock()
 	err := s.walker(rootOid, walkFn)
 	if err != nil {
 		return err
@@ -307,8 +330,7 @@ func (s *Snmp) Alive() (bool, error) {
 
 func (s *Snmp) stop() {
 	if !s.mock {
-		s.tgsnmp.Conn.Close()
-		s.gsnmp.Conn.Close()
+		s.sessions.close()
 	}
 }
 
@@ -335,8 +357,200 @@ func (s *Snmp) OpenConfig() bool {
 	return true
 }
 
-func (s *Snmp) sendUpdates(ctx context.Context) error {
-	return s.translator.Poll(ctx, s.client, []string{".*"})
+// rateProvider gets the client through which an Snmp provider's
+// counter rates are sent. The rates aren't OpenConfig, so they can't
+// go through the Snmp provider's own client.
+type rateProvider struct {
+	s *Snmp
+}
+
+func (r *rateProvider) InitGNMI(client gnmi.GNMIClient) {
+	r.s.rateLock.Lock()
+	defer r.s.rateLock.Unlock()
+	r.s.rateClient = client
+}
+
+func (r *rateProvider) OpenConfig() bool {
+	return false
+}
+
+// Run waits for the Snmp provider, which does the work, to return.
+func (r *rateProvider) Run(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+	case <-r.s.done:
+	}
+	return nil
+}
+
+// RateProvider returns a provider that must run alongside the Snmp
+// provider if counter rates are enabled, to carry the rates.
+func (s *Snmp) RateProvider() provider.GNMIProvider {
+	return &rateProvider{s: s}
+}
+
+// setRates sends counter rates through the rate provider's client,
+// dropping them if it hasn't been initialized.
+func (s *Snmp) setRates(ctx context.Context,
+	req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	s.rateLock.Lock()
+	client := s.rateClient
+	s.rateLock.Unlock()
+	if client == nil {
+		return &gnmi.SetResponse{}, nil
+	}
+	return client.Set(ctx, req)
+}
+
+func (s *Snmp) sendUpdates(ctx context.Context, paths []string) error {
+	return s.translator.Poll(ctx, s.client, paths)
+}
+
+// pollSchedule is a set of paths to poll at an interval.
+type pollSchedule struct {
+	interval time.Duration
+	paths    []string
+}
+
+// pollSchedules returns the provider's poll schedules. Models in the
+// same mapping group depend on each other's data, so a group is polled
+// at the shortest interval of any of its models, and groups with no
+// configured interval are polled at the default poll interval.
+func (s *Snmp) pollSchedules() ([]*pollSchedule, error) {
+	if len(s.pollIntervals) == 0 {
+		return []*pollSchedule{{interval: s.pollInterval, paths: []string{".*"}}}, nil
+	}
+
+	groupIntervals := make(map[string]time.Duration)
+	for model, interval := range s.pollIntervals {
+		mg, err := s.translator.MappingGroup(model)
+		if err != nil {
+			return nil, err
+		}
+		if cur, ok := groupIntervals[mg]; !ok || interval < cur {
+			groupIntervals[mg] = interval
+		}
+	}
+
+	schedules := make(map[time.Duration]*pollSchedule)
+	for mg, paths := range s.translator.MappingGroupPaths() {
+		interval, ok := groupIntervals[mg]
+		if !ok {
+			interval = s.pollInterval
+		}
+		if _, ok := schedules[interval]; !ok {
+			schedules[interval] = &pollSchedule{interval: interval}
+		}
+		schedules[interval].paths = append(schedules[interval].paths, paths...)
+	}
+
+	ps := make([]*pollSchedule, 0, len(schedules))
+	for _, sched := range schedules {
+		ps = append(ps, sched)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].interval < ps[j].interval })
+	return ps, nil
+}
+
+// runSchedule polls a schedule's paths at its interval, so that a
+// slow poll of one schedule doesn't hold up the others.
+func (s *Snmp) runSchedule(ctx context.Context, sched *pollSchedule) {
+	tick := time.NewTicker(sched.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			if err := s.sendUpdates(ctx, sched.paths); err != nil && !ignoredError(err) {
+				log.Log(s).Infof("Error in sendUpdates: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SetTrapReceiver arranges for the provider to receive the device's
+// traps and informs from the specified TrapReceiver once it's running.
+func (s *Snmp) SetTrapReceiver(r *TrapReceiver) {
+	s.trapReceiver = r
+}
+
+// SetMappingConfig arranges for the provider to translate the
+// specified user-defined mappings along with the default ones.
+func (s *Snmp) SetMappingConfig(cfg *snmpoc.MappingConfig) {
+	s.mappingConfig = cfg
+}
+
+// SetMIBCacheDir sets the directory in which to save the parsed MIBs
+// so that they needn't be parsed again the next time the provider
+// starts. The MIBs aren't saved if it's empty.
+func (s *Snmp) SetMIBCacheDir(dir string) {
+	s.mibCacheDir = dir
+}
+
+// SetCounterOptions sets whether the provider sends 32-bit counters
+// as 64-bit values that survive counter wraps, and whether it sends
+// per-second counter rates through the provider returned by
+// RateProvider.
+func (s *Snmp) SetCounterOptions(extendCounters, counterRates bool) {
+	s.extendCounters = extendCounters
+	s.counterRates = counterRates
+}
+
+// SetPollIntervals sets the poll intervals of particular models,
+// keyed by model name. Other models are polled at the provider's
+// default poll interval.
+func (s *Snmp) SetPollIntervals(intervals map[string]time.Duration) {
+	s.pollIntervals = intervals
+}
+
+// SetSessionOptions sets the number of SNMP sessions to open to the
+// device, which bounds the number of requests in flight at once, and
+// the maximum number of requests per second to send it over all
+// sessions, where zero means no limit.
+func (s *Snmp) SetSessionOptions(sessions int, maxRequestRate float64) {
+	s.sessionCount = sessions
+	s.maxRequestRate = maxRequestRate
+	s.resetSessions()
+}
+
+// SetRequestOptions sets the number of times to retry an SNMP request,
+// the time to wait for a response, whether that time doubles with
+// each retry, and the max-repetitions value of GETBULK requests.
+func (s *Snmp) SetRequestOptions(retries int, timeout time.Duration,
+	exponentialTimeout bool, maxRepetitions uint32) {
+	s.gsnmp.Retries = retries
+	s.gsnmp.Timeout = timeout
+	s.gsnmp.ExponentialTimeout = exponentialTimeout
+	s.gsnmp.MaxRepetitions = maxRepetitions
+	s.resetSessions()
+}
+
+// SetWalkStrategy sets how the provider walks the device's tables.
+func (s *Snmp) SetWalkStrategy(strategy WalkStrategy) {
+	s.walkStrategy = strategy
+	s.resetSessions()
+}
+
+// resetSessions replaces the provider's sessions with new ones that
+// reflect its current options.
+func (s *Snmp) resetSessions() {
+	s.sessions = newSessionPool(s.gsnmp, s.sessionCount, s.maxRequestRate,
+		s.walkStrategy)
+	s.getter = s.sessions.get
+	s.walker = s.sessions.walk
+}
+
+// handleTrap repolls the paths affected by a notification.
+func (s *Snmp) handleTrap(ctx context.Context, pkt *gosnmp.SnmpPacket) error {
+	oid := trapOID(pkt)
+	paths, ok := trapPaths[oid]
+	if !ok {
+		log.Log(s).Debugf("Ignoring notification %s", oid)
+		return nil
+	}
+	log.Log(s).Debugf("Received notification %s; polling %v", oid, paths)
+	return s.translator.Poll(ctx, s.client, paths)
 }
 
 func ignoredError(err error) bool {
@@ -348,6 +562,7 @@ func ignoredError(err error) bool {
 
 // Run sets the Snmp provider running and returns only on error.
 func (s *Snmp) Run(ctx context.Context) error {
+	defer s.doneOnce.Do(func() { close(s.done) })
 	if s.client == nil {
 		return errors.New("Run called before InitGNMI")
 	}
@@ -358,13 +573,18 @@ func (s *Snmp) Run(ctx context.Context) error {
 	}
 	log.Log(s).Debugf("gosnmp.Connect complete")
 
-	mibStore, err := smi.NewStore(s.mibs...)
+	// Devices with the same MIBs share a MIB store.
+	mibStore, err := smi.SharedStore(s.mibCacheDir, s.mibs...)
 	if err != nil {
 		return fmt.Errorf("Error creating MIB store: %s", err)
 	}
+	// Problems with the MIBs only affect the objects they define.
+	for _, d := range mibStore.Diagnostics() {
+		log.Log(s).Warnf("Problem loading MIBs: %s", d)
+	}
 	s.mibStore = mibStore
 
-	translator, err := snmpoc.NewTranslator(mibStore, s.tgsnmp)
+	translator, err := snmpoc.NewTranslator(mibStore, nil)
 	if err != nil {
 		return fmt.Errorf("Failed creating Translator: %v", err)
 	}
@@ -374,18 +594,48 @@ func (s *Snmp) Run(ctx context.Context) error {
 	s.translator.Walker = s.walker
 	s.translator.Getter = s.getter
 	s.translator.Logger = log.Log(s)
+	s.translator.ExtendCounters = s.extendCounters
+	s.translator.CounterRates = s.counterRates
+	s.translator.RateClient = pgnmi.NewSimpleGNMIClient(s.setRates)
+	if s.mappingConfig != nil {
+		if err := s.translator.AddMappings(s.mappingConfig); err != nil {
+			return fmt.Errorf("Error adding mappings: %v", err)
+		}
+	}
+
+	if s.trapReceiver != nil {
+		if err := s.trapReceiver.register(s.gsnmp.Target, s.trapParams,
+			s.trapc); err != nil {
+			return err
+		}
+		defer s.trapReceiver.deregister(s.trapc)
+	}
 
-	// Do periodic state updates forever.
-	if err := s.sendUpdates(ctx); err != nil && !ignoredError(err) {
+	schedules, err := s.pollSchedules()
+	if err != nil {
+		return fmt.Errorf("Error setting poll intervals: %v", err)
+	}
+
+	// Do periodic state updates forever, and targeted updates
+	// whenever the device tells us something has changed.
+	if err := s.sendUpdates(ctx, []string{".*"}); err != nil && !ignoredError(err) {
 		log.Log(s).Infof("Error in sendUpdates: %s", err)
 	}
 
-	tick := time.NewTicker(s.pollInterval)
+	pollCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, sched := range schedules {
+		wg.Add(1)
+		go func(sched *pollSchedule) {
+			defer wg.Done()
+			s.runSchedule(pollCtx, sched)
+		}(sched)
+	}
 	for {
 		select {
-		case <-tick.C:
-			if err := s.sendUpdates(ctx); err != nil && !ignoredError(err) {
-				log.Log(s).Infof("Error in sendUpdates: %s", err)
+		case pkt := <-s.trapc:
+			if err := s.handleTrap(ctx, pkt); err != nil && !ignoredError(err) {
+				log.Log(s).Infof("Error in handleTrap: %s", err)
 			}
 		case <-ctx.Done():
 			goto finish
@@ -393,15 +643,42 @@ func (s *Snmp) Run(ctx context.Context) error {
 	}
 
 finish:
+	cancel()
+	wg.Wait()
 	s.stop()
 	return nil
 }
 
 // V3Params contains options related to SNMPv3.
 type V3Params struct {
-	SecurityModel gosnmp.SnmpV3SecurityModel
-	Level         gosnmp.SnmpV3MsgFlags
-	UsmParams     *gosnmp.UsmSecurityParameters
+	SecurityModel   gosnmp.SnmpV3SecurityModel
+	Level           gosnmp.SnmpV3MsgFlags
+	UsmParams       *gosnmp.UsmSecurityParameters
+	ContextName     string
+	ContextEngineID string
+}
+
+// AuthProtocols maps the lower-case names of SNMPv3 authentication
+// protocols to the protocols.
+var AuthProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"md5":    gosnmp.MD5,
+	"sha":    gosnmp.SHA,
+	"sha224": gosnmp.SHA224,
+	"sha256": gosnmp.SHA256,
+	"sha384": gosnmp.SHA384,
+	"sha512": gosnmp.SHA512,
+}
+
+// PrivacyProtocols maps the lower-case names of SNMPv3 privacy
+// protocols to the protocols. The aes192c and aes256c protocols use
+// the Cisco (Reeder) key extension.
+var PrivacyProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"des":     gosnmp.DES,
+	"aes":     gosnmp.AES,
+	"aes192":  gosnmp.AES192,
+	"aes256":  gosnmp.AES256,
+	"aes192c": gosnmp.AES192C,
+	"aes256c": gosnmp.AES256C,
 }
 
 // NewSNMPProvider returns a new SNMP provider for the device at 'address'
@@ -419,26 +696,32 @@ func NewSNMPProvider(address string, port uint16, community string,
 		Target:             address,
 		Community:          community,
 		Timeout:            time.Duration(2) * time.Second,
-		Logger:             nil,
 		MaxRepetitions:     12,
 	}
 	if v3Params != nil {
 		gsnmp.MsgFlags = v3Params.Level
 		gsnmp.SecurityModel = v3Params.SecurityModel
 		gsnmp.SecurityParameters = v3Params.UsmParams
+		gsnmp.ContextName = v3Params.ContextName
+		gsnmp.ContextEngineID = v3Params.ContextEngineID
+	}
+	trapGoSNMP := gsnmp
+	if v3Params != nil {
+		trapGoSNMP.SecurityParameters = v3Params.UsmParams.Copy()
 	}
-	translatorGoSNMP := gsnmp
 
 	s := &Snmp{
-		tgsnmp:       &translatorGoSNMP,
 		gsnmp:        &gsnmp,
 		pollInterval: pollInt,
+		sessionCount: 1,
 		mibs:         mibs,
 		mock:         mock,
-		getter:       gsnmp.Get,
-		walker:       gsnmp.BulkWalk,
 		now:          time.Now,
+		trapParams:   &trapGoSNMP,
+		trapc:        make(chan *gosnmp.SnmpPacket, 16),
+		done:         make(chan struct{}),
 	}
+	s.resetSessions()
 
 	return s
 }
@@ -9,6 +9,7 @@ import (
 	"compress/gzip"
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"strings"
 	"sync"
@@ -17,9 +18,11 @@ import (
 
 	"github.com/aristanetworks/cloudvision-go/provider"
 	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/agent"
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/snmpoc"
+	"github.com/gosnmp/gosnmp"
 	"github.com/openconfig/gnmi/proto/gnmi"
-	"github.com/soniah/gosnmp"
 	"google.golang.org/grpc"
 )
 
@@ -60,21 +63,34 @@ func walkMapsFromDump(filename string) ([]walkMap, error) {
 
 	scanner := bufio.NewScanner(gz)
 	firstLine := ""
+	marked := false
 	for scanner.Scan() {
 		line := scanner.Text()
 
+		// If this is the start of a new poll, store the existing walkMap
+		// and start another. Dumps without poll markers start a poll
+		// each time their first line recurs.
+		if snmpoc.IsPollString(line) {
+			if marked {
+				walkMaps = append(walkMaps, wm)
+				wm = make(walkMap)
+			}
+			marked = true
+			continue
+		}
+
 		pdu := pduFromString(scanner.Text())
 		if pdu == nil {
 			continue
 		}
 
-		// If this is the start of a new poll, store the existing walkMap
-		// and start another.
-		if firstLine == "" {
-			firstLine = line
-		} else if line == firstLine {
-			walkMaps = append(walkMaps, wm)
-			wm = make(walkMap)
+		if !marked {
+			if firstLine == "" {
+				firstLine = line
+			} else if line == firstLine {
+				walkMaps = append(walkMaps, wm)
+				wm = make(walkMap)
+			}
 		}
 
 		// Store a pointer to this PDU for each of the valid prefixes
@@ -161,12 +177,17 @@ func (m *testGNMIClient) Set(ctx context.Context, in *gnmi.SetRequest,
 	// we want to see for each poll, mark that path as found. Once all
 	// paths are found we can mark the poll complete.
 	for _, update := range in.Replace {
+		if m.pollsRemaining == 0 {
+			// Mapping groups may still be sending updates after
+			// the final poll has been counted.
+			break
+		}
 		for i, expectedPath := range m.pathsRemaining {
 			if pathMatches(update.Path, expectedPath) {
 				m.pathsRemaining = append(m.pathsRemaining[:i], m.pathsRemaining[(i+1):]...)
 				if len(m.pathsRemaining) == 0 {
 					m.pollsRemaining--
-					m.pathsRemaining = m.paths
+					m.pathsRemaining = append([]*gnmi.Path{}, m.paths...)
 				}
 				if m.pollsRemaining == 0 {
 					m.cancel()
@@ -243,7 +264,7 @@ func newTestGNMIClient(cancel context.CancelFunc,
 		lock:   &sync.Mutex{},
 	}
 	client.pollsRemaining = client.polls
-	client.pathsRemaining = client.paths
+	client.pathsRemaining = append([]*gnmi.Path{}, client.paths...)
 	return client
 }
 
@@ -342,3 +363,66 @@ func TestDevices(t *testing.T) {
 		})
 	}
 }
+
+// Run the SNMP provider over UDP against a simulated agent serving
+// a device dump, with SNMPv2c and SNMPv3.
+func TestDevicesOverUDP(t *testing.T) {
+	polls, err := agent.LoadDump("dumps/Arista_DCS-7150S-24_4.21.3F-2GB-INT_20190301.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	usm := &gosnmp.UsmSecurityParameters{
+		UserName:                 "user",
+		AuthenticationProtocol:   gosnmp.SHA256,
+		AuthenticationPassphrase: "authpass",
+		PrivacyProtocol:          gosnmp.AES,
+		PrivacyPassphrase:        "privpass",
+	}
+	a, err := agent.New("127.0.0.1:0", polls, agent.Config{
+		Community:      "public",
+		Users:          []*gosnmp.UsmSecurityParameters{usm},
+		RotateInterval: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	go a.Serve()
+	port := uint16(a.Addr().(*net.UDPAddr).Port)
+
+	for name, tc := range map[string]struct {
+		version  gosnmp.SnmpVersion
+		v3Params *V3Params
+	}{
+		"v2c": {version: gosnmp.Version2c},
+		"v3": {
+			version: gosnmp.Version3,
+			v3Params: &V3Params{
+				SecurityModel: gosnmp.UserSecurityModel,
+				Level:         gosnmp.AuthPriv,
+				UsmParams:     usm.Copy().(*gosnmp.UsmSecurityParameters),
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			client := newTestGNMIClient(cancel, deviceTestCase{
+				expectedPaths: basicPaths(),
+				polls:         2,
+			})
+			prov := NewSNMPProvider("127.0.0.1", port, "public", 10*time.Millisecond,
+				tc.version, tc.v3Params, []string{"smi/mibs"}, false)
+			prov.InitGNMI(client)
+			if err := prov.Run(ctx); err != nil {
+				t.Fatalf("Error in provider.Run: %v", err)
+			}
+
+			client.lock.Lock()
+			defer client.lock.Unlock()
+			if client.pollsRemaining != 0 {
+				t.Fatal("Provider did not finish polling")
+			}
+		})
+	}
+}
@@ -5,11 +5,16 @@
 package snmp
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
-	"github.com/soniah/gosnmp"
+	"github.com/gosnmp/gosnmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
 )
 
 // deviceIDTestCase describes a test of the SNMP DeviceID method: the
@@ -267,3 +272,200 @@ func TestDeviceID(t *testing.T) {
 		})
 	}
 }
+
+// Models in the interfaces-lldp mapping group should be polled at the
+// interval of any one of them, and other models at the default
+// interval.
+func TestPollIntervals(t *testing.T) {
+	walkMaps, err := walkMapsFromDump(
+		"dumps/Arista_DCS-7150S-24_4.21.3F-2GB-INT_20190301.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewSNMPProvider("127.0.0.1", 161, "public", time.Hour,
+		gosnmp.Version2c, nil, []string{"smi/mibs"}, true).(*Snmp)
+	p.getter = func(oids []string) (*gosnmp.SnmpPacket, error) {
+		return testget(oids, p.mibStore, walkMaps[0])
+	}
+	p.walker = func(oid string, walker gosnmp.WalkFunc) error {
+		return testwalk(oid, walker, p.mibStore, walkMaps[0])
+	}
+	p.SetPollIntervals(map[string]time.Duration{"lldp": 100 * time.Millisecond})
+	client := &recordingGNMIClient{deletes: make(map[string]int)}
+	p.InitGNMI(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		errc <- p.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for client.count("interfaces") < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for interfaces polls")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := client.count("system"); n != 1 {
+		t.Fatalf("Expected system to be polled once, got %d", n)
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	p.SetPollIntervals(map[string]time.Duration{"bogus": time.Second})
+	if err := p.Run(context.Background()); err == nil {
+		t.Fatal("Expected error for poll interval of unknown model")
+	}
+}
+
+// originClient records the origins of the paths it's sent.
+type originClient struct {
+	gnmi.GNMIClient
+	lock    sync.Mutex
+	origins map[string]int
+}
+
+func (c *originClient) Set(ctx context.Context, in *gnmi.SetRequest,
+	opts ...grpc.CallOption) (*gnmi.SetResponse, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, d := range in.Delete {
+		c.origins[d.Origin]++
+	}
+	for _, u := range in.Replace {
+		c.origins[u.Path.Origin]++
+	}
+	return &gnmi.SetResponse{}, nil
+}
+
+func (c *originClient) count(origin string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.origins[origin]
+}
+
+// Counter rates should go only through the rate provider's client,
+// which isn't OpenConfig, and the rate provider should return when
+// the Snmp provider does.
+func TestCounterRateProvider(t *testing.T) {
+	walkMaps, err := walkMapsFromDump(
+		"dumps/Arista_DCS-7150S-24_4.21.3F-2GB-INT_20190301.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewSNMPProvider("127.0.0.1", 161, "public", time.Hour,
+		gosnmp.Version2c, nil, []string{"smi/mibs"}, true).(*Snmp)
+	p.getter = func(oids []string) (*gosnmp.SnmpPacket, error) {
+		return testget(oids, p.mibStore, walkMaps[0])
+	}
+	p.walker = func(oid string, walker gosnmp.WalkFunc) error {
+		return testwalk(oid, walker, p.mibStore, walkMaps[0])
+	}
+	p.SetCounterOptions(false, true)
+	client := &originClient{origins: make(map[string]int)}
+	p.InitGNMI(client)
+	rp := p.RateProvider()
+	if rp.OpenConfig() {
+		t.Fatal("Expected rate provider not to be OpenConfig")
+	}
+	rateClient := &originClient{origins: make(map[string]int)}
+	rp.InitGNMI(rateClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		errc <- p.Run(ctx)
+	}()
+	rateErrc := make(chan error)
+	go func() {
+		rateErrc <- rp.Run(context.Background())
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for rateClient.count("counter-rates") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for counter rates")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-rateErrc; err != nil {
+		t.Fatal(err)
+	}
+	if n := client.count("counter-rates"); n != 0 {
+		t.Fatalf("Expected no counter rates through the OpenConfig client, got %d", n)
+	}
+	if n := rateClient.count(""); n != 0 {
+		t.Fatalf("Expected only counter rates through the rate client, got %d "+
+			"other paths", n)
+	}
+}
+
+// A slow poll of one mapping group shouldn't hold up the polls of
+// the others.
+func TestPollGroupsIndependently(t *testing.T) {
+	walkMaps, err := walkMapsFromDump(
+		"dumps/Arista_DCS-7150S-24_4.21.3F-2GB-INT_20190301.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewSNMPProvider("127.0.0.1", 161, "public", time.Hour,
+		gosnmp.Version2c, nil, []string{"smi/mibs"}, true).(*Snmp)
+	p.getter = func(oids []string) (*gosnmp.SnmpPacket, error) {
+		return testget(oids, p.mibStore, walkMaps[0])
+	}
+
+	// Let the first platform poll through, and hold up the rest
+	// until the end of the test.
+	var lock sync.Mutex
+	platformPolls := 0
+	release := make(chan struct{})
+	p.walker = func(oid string, walker gosnmp.WalkFunc) error {
+		if strings.TrimPrefix(oid, ".") == "1.3.6.1.2.1.47.1.1.1.1" {
+			lock.Lock()
+			platformPolls++
+			blocked := platformPolls > 1
+			lock.Unlock()
+			if blocked {
+				<-release
+			}
+		}
+		return testwalk(oid, walker, p.mibStore, walkMaps[0])
+	}
+	p.SetPollIntervals(map[string]time.Duration{
+		"interfaces": 50 * time.Millisecond,
+		"platform":   20 * time.Millisecond,
+	})
+	client := &recordingGNMIClient{deletes: make(map[string]int)}
+	p.InitGNMI(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		errc <- p.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for client.count("interfaces") < 4 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for interfaces polls")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := client.count("components"); n != 1 {
+		t.Fatalf("Expected platform to be polled once, got %d", n)
+	}
+
+	close(release)
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
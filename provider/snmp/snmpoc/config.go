@@ -0,0 +1,432 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmpoc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/pdu"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// MappingConfig is a set of user-defined models and SNMP-to-OpenConfig
+// mappings, as read from a YAML file such as the following:
+//
+//	Models:
+//	  - Name: system
+//	    Get: [hrMemorySize.0]
+//	Mappings:
+//	  - Path: /system/memory/state/physical
+//	    OID: hrMemorySize
+//	    Type: uint
+//	    Scale: 1024
+//	  - Path: /interfaces/interface[name={ifName}]/state/description
+//	    OID: ifAlias
+//	  - Path: /interfaces/interface[name={ifName}]/state/oper-status
+//	    OID: ifOperStatus
+//	    Type: enum
+//	    Enum: {1: UP, 2: DOWN, 3: TESTING}
+type MappingConfig struct {
+	Models   []*ModelConfig `yaml:"Models,omitempty"`
+	Mappings []*PathMapping `yaml:"Mappings,omitempty"`
+}
+
+// ModelConfig defines a new model, or extends the model of the same
+// name, with the OIDs to walk and get when polling it. OIDs may be
+// numeric or textual; scalars need a ".0" suffix.
+type ModelConfig struct {
+	Name     string   `yaml:"Name"`
+	RootPath string   `yaml:"RootPath,omitempty"`
+	Walk     []string `yaml:"Walk,omitempty"`
+	Get      []string `yaml:"Get,omitempty"`
+}
+
+// PathMapping maps a scalar or column OID to an OpenConfig path.
+//
+// The path of a column mapping is a template with one path per row.
+// It may refer to "{index}", the row's full index; "{ifName}", the
+// interface name for an ifIndex-indexed row; and "{<name>}", the
+// value of the row index called name.
+//
+// Type specifies how to transform the value: "string", "int",
+// "uint", "float", "mac", "ip" or "enum". If no Type is given, the
+// value is sent as a string or integer according to its SNMP type.
+// Numeric values are multiplied by Scale if it's nonzero, and enum
+// values are looked up in Enum.
+type PathMapping struct {
+	Path  string         `yaml:"Path"`
+	OID   string         `yaml:"OID"`
+	Type  string         `yaml:"Type,omitempty"`
+	Scale float64        `yaml:"Scale,omitempty"`
+	Enum  map[int]string `yaml:"Enum,omitempty"`
+}
+
+var mappingTypes = map[string]bool{
+	"": true, "string": true, "int": true, "uint": true, "float": true,
+	"mac": true, "ip": true, "enum": true,
+}
+
+// ParseMappingConfig parses and validates a YAML MappingConfig.
+func ParseMappingConfig(data []byte) (*MappingConfig, error) {
+	cfg := &MappingConfig{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, err
+	}
+	for _, m := range cfg.Models {
+		if m.Name == "" {
+			return nil, errors.New("Model with no name")
+		}
+	}
+	for _, pm := range cfg.Mappings {
+		if pm.Path == "" || pm.OID == "" {
+			return nil, fmt.Errorf("Mapping needs both a path and an OID: %+v", *pm)
+		}
+		if !mappingTypes[pm.Type] {
+			return nil, fmt.Errorf("Unknown type '%s' for path %s", pm.Type, pm.Path)
+		}
+		if pm.Type == "enum" && len(pm.Enum) == 0 {
+			return nil, fmt.Errorf("No enum values for path %s", pm.Path)
+		}
+	}
+	return cfg, nil
+}
+
+// ReadMappingConfig reads a MappingConfig from the specified YAML file.
+func ReadMappingConfig(filename string) (*MappingConfig, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ParseMappingConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("Error in mapping config %s: %v", filename, err)
+	}
+	return cfg, nil
+}
+
+func toFloat64(x interface{}) (float64, error) {
+	switch v := x.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case uint, uint8, uint16, uint32, uint64:
+		u, err := provider.ToUint64(x)
+		return float64(u), err
+	}
+	i, err := provider.ToInt64(x)
+	return float64(i), err
+}
+
+func isUnsigned(x interface{}) bool {
+	switch x.(type) {
+	case uint, uint8, uint16, uint32, uint64:
+		return true
+	}
+	return false
+}
+
+// valueProcessor returns the ValueProcessor for a PathMapping.
+func (pm *PathMapping) valueProcessor() ValueProcessor {
+	scale := pm.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	scaled := func(x interface{}) (float64, bool) {
+		f, err := toFloat64(x)
+		return f * scale, err == nil
+	}
+
+	switch pm.Type {
+	case "string":
+		return strval
+	case "int":
+		return func(x interface{}) *gnmi.TypedValue {
+			if f, ok := scaled(x); ok {
+				return pgnmi.Intval(int64(f))
+			}
+			return nil
+		}
+	case "uint":
+		return func(x interface{}) *gnmi.TypedValue {
+			if f, ok := scaled(x); ok && f >= 0 {
+				return pgnmi.Uintval(uint64(f))
+			}
+			return nil
+		}
+	case "float":
+		return func(x interface{}) *gnmi.TypedValue {
+			if f, ok := scaled(x); ok {
+				return pgnmi.Floatval(f)
+			}
+			return nil
+		}
+	case "mac":
+		return func(x interface{}) *gnmi.TypedValue {
+			if b, ok := x.([]byte); ok && len(b) > 0 {
+				return pgnmi.Strval(MacFromBytes(b))
+			}
+			return nil
+		}
+	case "ip":
+		return func(x interface{}) *gnmi.TypedValue {
+			switch v := x.(type) {
+			case string:
+				// gosnmp decodes IpAddress values as strings.
+				if v != "" {
+					return pgnmi.Strval(v)
+				}
+			case []byte:
+				if len(v) > 0 {
+					return pgnmi.Strval(IPFromBytes(v))
+				}
+			}
+			return nil
+		}
+	case "enum":
+		return func(x interface{}) *gnmi.TypedValue {
+			i, err := provider.ToInt(x)
+			if err != nil {
+				return nil
+			}
+			if s, ok := pm.Enum[i]; ok {
+				return pgnmi.Strval(s)
+			}
+			return nil
+		}
+	}
+
+	// Infer the type from the value.
+	return func(x interface{}) *gnmi.TypedValue {
+		switch x.(type) {
+		case string, []byte:
+			return strval(x)
+		}
+		if pm.Scale != 0 {
+			if f, ok := scaled(x); ok {
+				return pgnmi.Floatval(f)
+			}
+			return nil
+		}
+		if isUnsigned(x) {
+			return uintval(x)
+		}
+		return intval(x)
+	}
+}
+
+var (
+	placeholderRegex = regexp.MustCompile(`\{([^{}]+)\}`)
+	pathKeyRegex     = regexp.MustCompile(`\[([^=\]]+)=[^\]]*\]`)
+)
+
+// mappingKey returns the key in Translator.Mappings for a path,
+// which has each list key value replaced by the key's name.
+func mappingKey(path string) string {
+	return pathKeyRegex.ReplaceAllString(path, "[$1=$1]")
+}
+
+// expandPath fills in the placeholders in a path template with the
+// index values of the specified PDU.
+func expandPath(ss smi.Store, ps pdu.Store, mapperData *sync.Map,
+	path string, p *gosnmp.SnmpPDU) (string, error) {
+	var err error
+	expanded := placeholderRegex.ReplaceAllStringFunc(path, func(m string) string {
+		name := m[1 : len(m)-1]
+		var val string
+		var e error
+		switch name {
+		case "index":
+			var values []string
+			values, e = pdu.IndexValues(ss, p)
+			val = strings.Join(values, ".")
+		case "ifName":
+			if val, e = firstIndex(ss, p); e == nil {
+				val, e = intfNameForIndex(ss, ps, mapperData, val)
+			}
+		default:
+			val, e = pdu.IndexValueByName(ss, p, name)
+		}
+		if e != nil && err == nil {
+			err = e
+		}
+		return val
+	})
+	return expanded, err
+}
+
+func columnMapperFn(path, oid string, vp ValueProcessor) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		pdus, err := getTabular(ps, oid)
+		if err != nil || pdus == nil {
+			return nil, err
+		}
+		updates := []*gnmi.Update{}
+		for _, p := range pdus {
+			val := vp(p.Value)
+			if val == nil {
+				continue
+			}
+			if _, err := firstIndex(ss, p); err != nil {
+				logger.Debugf("Skipping %s: %v", p.Name, err)
+				continue
+			}
+			fullPath, err := expandPath(ss, ps, mapperData, path, p)
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, update(pgnmi.PathFromString(fullPath), val))
+		}
+		return updates, nil
+	}
+}
+
+// mapper returns a Mapper for a PathMapping.
+func (pm *PathMapping) mapper(ss smi.Store) (Mapper, error) {
+	o := ss.GetObject(pm.OID)
+	if o == nil {
+		return nil, fmt.Errorf("No MIB object for OID %s", pm.OID)
+	}
+	vp := pm.valueProcessor()
+	if o.Kind == smi.KindColumn {
+		return columnMapperFn(pm.Path, pm.OID, vp), nil
+	}
+	if placeholderRegex.MatchString(pm.Path) {
+		return nil, fmt.Errorf("Path %s for scalar OID %s has index placeholders",
+			pm.Path, pm.OID)
+	}
+	return scalarMapperFn(pm.Path, pm.OID, vp), nil
+}
+
+// pathContains reports whether path is root or lies beneath it.
+func pathContains(root, path string) bool {
+	return path == root || strings.HasPrefix(path, root+"/")
+}
+
+// overlappingGroup returns the mapping group of any model whose root
+// path contains, or lies beneath, the specified root path. A model
+// overlapping another must be polled with it, or the deletion of
+// each one's root path would wipe out the other's data.
+func (t *Translator) overlappingGroup(rootPath string) (*mappingGroup, error) {
+	var group *mappingGroup
+	for _, mg := range t.mappingGroups {
+		for _, m := range mg.models {
+			if m.paths != nil {
+				continue
+			}
+			if !pathContains(m.rootPath, rootPath) &&
+				!pathContains(rootPath, m.rootPath) {
+				continue
+			}
+			if group != nil && group != mg {
+				return nil, fmt.Errorf("Root path %s overlaps models in "+
+					"mapping groups %s and %s", rootPath, group.name, mg.name)
+			}
+			group = mg
+		}
+	}
+	return group, nil
+}
+
+// checkOIDs checks that each of a model's OIDs is in the MIB store.
+func (t *Translator) checkOIDs(mc *ModelConfig) error {
+	for _, oids := range [][]string{mc.Get, mc.Walk} {
+		for _, oid := range oids {
+			if t.mibStore.GetObject(oid) == nil {
+				return fmt.Errorf("Unknown OID '%s' in model %s", oid, mc.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// AddMappings adds the models and mappings of a MappingConfig to the
+// Translator. A mapping takes precedence over any existing mappings
+// for the same path. A new model whose root path overlaps an existing
+// model's joins that model's mapping group.
+func (t *Translator) AddMappings(cfg *MappingConfig) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, mc := range cfg.Models {
+		if err := t.checkOIDs(mc); err != nil {
+			return err
+		}
+		if m, ok := t.models[mc.Name]; ok {
+			if mc.RootPath != "" && mc.RootPath != m.rootPath {
+				return fmt.Errorf("Model %s has root path %s, not %s",
+					mc.Name, m.rootPath, mc.RootPath)
+			}
+			m.snmpWalkOIDs = append(m.snmpWalkOIDs, mc.Walk...)
+			m.snmpGetOIDs = append(m.snmpGetOIDs, mc.Get...)
+			continue
+		}
+		if mc.RootPath == "" {
+			return fmt.Errorf("New model %s has no root path", mc.Name)
+		}
+		if _, ok := t.mappingGroups[mc.Name]; ok {
+			return fmt.Errorf("Model %s has the name of a mapping group", mc.Name)
+		}
+		m := &model{
+			name:         mc.Name,
+			rootPath:     strings.TrimSuffix(mc.RootPath, "/"),
+			snmpGetOIDs:  mc.Get,
+			snmpWalkOIDs: mc.Walk,
+		}
+		mg, err := t.overlappingGroup(m.rootPath)
+		if err != nil {
+			return err
+		}
+		t.models[m.name] = m
+		if mg != nil {
+			mg.models[m.name] = m
+			continue
+		}
+		t.mappingGroups[m.name] = &mappingGroup{
+			name:   m.name,
+			models: map[string]*model{m.name: m},
+		}
+	}
+
+	for _, pm := range cfg.Mappings {
+		if !t.hasModelFor(pm.Path) {
+			return fmt.Errorf("No model for path %s", pm.Path)
+		}
+		mapper, err := pm.mapper(t.mibStore)
+		if err != nil {
+			return err
+		}
+		key := mappingKey(pm.Path)
+		t.Mappings[key] = append([]Mapper{mapper}, t.Mappings[key]...)
+	}
+
+	// Forget anything computed from the old mappings. No polls are
+	// running, so cacheLock needn't be held.
+	t.pathsMappingGroups = make(map[string]map[string]*mappingGroup)
+	t.successfulMappingsLock.Lock()
+	t.successfulMappings = make(map[string]Mapper)
+	t.successfulMappingsLock.Unlock()
+	return nil
+}
+
+func (t *Translator) hasModelFor(path string) bool {
+	for _, m := range t.models {
+		if strings.HasPrefix(path, m.rootPath+"/") {
+			return true
+		}
+	}
+	return false
+}
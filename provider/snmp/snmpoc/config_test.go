@@ -0,0 +1,296 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmpoc
+
+import (
+	"testing"
+
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+var ifAliasResponse = `
+.1.3.6.1.2.1.31.1.1.1.18.3001 = STRING: uplink
+.1.3.6.1.2.1.31.1.1.1.18.3002 = STRING:
+`
+
+var hrDeviceStatusResponse = `
+.1.3.6.1.2.1.25.3.2.1.5.1 = INTEGER: 2
+.1.3.6.1.2.1.25.3.2.1.5.2 = INTEGER: 5
+`
+
+func TestMappingConfig(t *testing.T) {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error in smi.NewStore: %s", err)
+	}
+
+	for _, tc := range []translatorTestCase{
+		{
+			name: "columnWithIntfName",
+			mappingConfig: `
+Mappings:
+  - Path: /interfaces/interface[name={ifName}]/state/description
+    OID: ifAlias
+`,
+			updatePaths: []string{"/interfaces/interface[name=name]/state/description"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"ifTable":  PDUsFromString(ifTable64BitResponse),
+				"ifXTable": PDUsFromString(ifAliasResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Delete: []*gnmi.Path{pgnmi.Path("interfaces")},
+					Replace: []*gnmi.Update{
+						update(pgnmi.IntfStatePath("Ethernet3/1", "description"),
+							strval("uplink")),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
+		{
+			name: "scalarWithScale",
+			mappingConfig: `
+Models:
+  - Name: memory
+    Get: [hrMemorySize.0]
+Mappings:
+  - Path: /system/memory/state/physical
+    OID: hrMemorySize
+    Type: uint
+    Scale: 1024
+`,
+			updatePaths: []string{"/system/memory/state/physical"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"hrMemorySize": []*gosnmp.SnmpPDU{
+					PDU("hrMemorySize", gosnmp.Integer, 8000000),
+				},
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Delete: []*gnmi.Path{pgnmi.Path("system", "memory")},
+					Replace: []*gnmi.Update{
+						update(pgnmi.Path("system", "memory", "state", "physical"),
+							uintval(8192000000)),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
+		{
+			name: "newModelWithEnum",
+			mappingConfig: `
+Models:
+  - Name: devices
+    RootPath: /devices
+    Walk: [hrDeviceTable]
+Mappings:
+  - Path: /devices/device[index={hrDeviceIndex}]/state/status
+    OID: hrDeviceStatus
+    Type: enum
+    Enum: {2: RUNNING, 5: DOWN}
+`,
+			updatePaths: []string{"^/devices/"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"hrDeviceTable": PDUsFromString(hrDeviceStatusResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Delete: []*gnmi.Path{pgnmi.Path("devices")},
+					Replace: []*gnmi.Update{
+						update(pgnmi.Path("devices", pgnmi.ListWithKey("device", "index", "1"),
+							"state", "status"), strval("RUNNING")),
+						update(pgnmi.Path("devices", pgnmi.ListWithKey("device", "index", "2"),
+							"state", "status"), strval("DOWN")),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
+		{
+			name: "ipAddressValue",
+			mappingConfig: `
+Models:
+  - Name: addresses
+    RootPath: /addresses
+    Walk: [ipAddrTable]
+Mappings:
+  - Path: /addresses/address[ip={ipAdEntAddr}]/state/netmask
+    OID: ipAdEntNetMask
+    Type: ip
+`,
+			updatePaths: []string{"^/addresses/"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"ipAddrTable": PDUsFromString(ipAddrTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Delete: []*gnmi.Path{pgnmi.Path("addresses")},
+					Replace: []*gnmi.Update{
+						update(pgnmi.Path("addresses",
+							pgnmi.ListWithKey("address", "ip", "172.30.174.29"),
+							"state", "netmask"), strval("255.255.255.128")),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			runTranslatorTest(t, mibStore, tc)
+		})
+	}
+}
+
+func TestMappingConfigErrors(t *testing.T) {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error in smi.NewStore: %s", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		config   string
+		parseErr bool
+	}{
+		{
+			name: "unknownField",
+			config: `
+Mappings:
+  - Path: /system/state/hostname
+    Oid: sysName
+`,
+			parseErr: true,
+		},
+		{
+			name: "unknownType",
+			config: `
+Mappings:
+  - Path: /system/state/hostname
+    OID: sysName
+    Type: bool
+`,
+			parseErr: true,
+		},
+		{
+			name: "enumWithoutValues",
+			config: `
+Mappings:
+  - Path: /system/state/hostname
+    OID: sysName
+    Type: enum
+`,
+			parseErr: true,
+		},
+		{
+			name: "noModel",
+			config: `
+Mappings:
+  - Path: /devices/device[index={index}]/state/status
+    OID: hrDeviceStatus
+`,
+		},
+		{
+			name: "unknownOID",
+			config: `
+Mappings:
+  - Path: /system/state/hostname
+    OID: noSuchObject
+`,
+		},
+		{
+			name: "scalarWithPlaceholder",
+			config: `
+Mappings:
+  - Path: /interfaces/interface[name={ifName}]/state/description
+    OID: sysName
+`,
+		},
+		{
+			name: "newModelWithoutRoot",
+			config: `
+Models:
+  - Name: devices
+    Walk: [hrDeviceTable]
+`,
+		},
+		{
+			name: "unknownModelOID",
+			config: `
+Models:
+  - Name: devices
+    RootPath: /devices
+    Get: ["x"]
+`,
+		},
+		{
+			name: "modelOverlappingGroups",
+			config: `
+Models:
+  - Name: everything
+    RootPath: /
+    Walk: [hrDeviceTable]
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := ParseMappingConfig([]byte(tc.config))
+			if tc.parseErr {
+				if err == nil {
+					t.Fatal("Expected parse error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tr, err := NewTranslator(mibStore, &gosnmp.GoSNMP{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tr.AddMappings(cfg); err == nil {
+				t.Fatal("Expected error adding mappings")
+			}
+		})
+	}
+}
+
+func TestMappingConfigGroups(t *testing.T) {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error in smi.NewStore: %s", err)
+	}
+	cfg, err := ParseMappingConfig([]byte(`
+Models:
+  - Name: devices
+    RootPath: /devices
+    Walk: [hrDeviceTable]
+  - Name: system-devices
+    RootPath: /system/devices
+    Walk: [hrDeviceTable]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTranslator(mibStore, &gosnmp.GoSNMP{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.AddMappings(cfg); err != nil {
+		t.Fatal(err)
+	}
+	for model, expected := range map[string]string{
+		"devices":        "devices",
+		"system-devices": "system",
+	} {
+		if mg, err := tr.MappingGroup(model); err != nil || mg != expected {
+			t.Errorf("Expected model %s in mapping group %s, got %s (%v)",
+				model, expected, mg, err)
+		}
+	}
+}
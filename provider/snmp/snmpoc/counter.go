@@ -0,0 +1,258 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmpoc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/pdu"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// counterSample is the last value seen for a counter.
+type counterSample struct {
+	raw       uint64    // value as reported by the device
+	value     uint64    // value as last sent, which may be extended
+	uptime    uint32    // sysUpTime of the sample in centiseconds, if known
+	hasUptime bool      // whether uptime is known
+	time      time.Time // time of the sample
+
+	// discontinuity is the ifCounterDiscontinuityTime of the
+	// counter's interface, or 0 if there's been none or it's unknown.
+	discontinuity uint32
+}
+
+// counterStore keeps the previous sample of each counter across
+// polls, so that counter wraps and device restarts can be told apart
+// and rates computed.
+type counterStore struct {
+	lock    sync.Mutex
+	samples map[string]*counterSample
+
+	// extend32 synthesizes monotonically increasing 64-bit values
+	// from 32-bit counters.
+	extend32 bool
+
+	// rates enables per-second rate updates.
+	rates bool
+}
+
+func newCounterStore() *counterStore {
+	return &counterStore{samples: make(map[string]*counterSample)}
+}
+
+func (cs *counterStore) setOptions(extend32, rates bool) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	cs.extend32 = extend32
+	cs.rates = rates
+}
+
+// sample records a new value of the counter identified by key, which
+// is a 32- or 64-bit counter according to bits, and returns the value
+// to send. If rates are enabled and one can be computed from the
+// previous sample, it's returned along with true.
+func (cs *counterStore) sample(key string, raw uint64, bits int,
+	uptime uint32, hasUptime bool, discontinuity uint32,
+	logger Logger) (uint64, float64, bool) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	cur := &counterSample{
+		raw:       raw,
+		value:     raw,
+		uptime:    uptime,
+		hasUptime: hasUptime,
+		time:      now(),
+
+		discontinuity: discontinuity,
+	}
+	prev, ok := cs.samples[key]
+	cs.samples[key] = cur
+	if !ok {
+		return cur.value, 0, false
+	}
+
+	// A device whose sysUpTime went backwards has restarted, and
+	// its counters have restarted with it.
+	if hasUptime && prev.hasUptime && uptime < prev.uptime {
+		logger.Debugf("Counter %s discontinuity: sysUpTime went from %d to %d",
+			key, prev.uptime, uptime)
+		return cur.value, 0, false
+	}
+
+	// A counter cleared without a restart looks like a wrap, unless
+	// the device tells us when it was cleared.
+	if discontinuity != prev.discontinuity {
+		logger.Debugf("Counter %s discontinuity: ifCounterDiscontinuityTime "+
+			"went from %d to %d", key, prev.discontinuity, discontinuity)
+		return cur.value, 0, false
+	}
+
+	var delta uint64
+	if raw >= prev.raw {
+		delta = raw - prev.raw
+	} else if bits == 32 && hasUptime && prev.hasUptime && uptime > prev.uptime {
+		delta = raw + (1 << 32) - prev.raw
+	} else {
+		// A 64-bit counter won't wrap in practice, and a 32-bit one
+		// can't be told to have wrapped rather than been reset
+		// unless the device has been up all along.
+		logger.Debugf("Counter %s discontinuity: went from %d to %d",
+			key, prev.raw, raw)
+		return cur.value, 0, false
+	}
+
+	if bits == 32 && cs.extend32 {
+		cur.value = prev.value + delta
+	}
+
+	var elapsed float64
+	if hasUptime && prev.hasUptime {
+		elapsed = float64(uptime-prev.uptime) / 100
+	} else {
+		elapsed = cur.time.Sub(prev.time).Seconds()
+	}
+	if elapsed <= 0 || !cs.rates {
+		return cur.value, 0, false
+	}
+	return cur.value, float64(delta) / elapsed, true
+}
+
+// counterRateOrigin is the origin of counter rates. They aren't part
+// of OpenConfig, so they're kept apart from the counters themselves.
+const counterRateOrigin = "counter-rates"
+
+// splitCounterRates moves the counter rate paths of a SetRequest into
+// a SetRequest of their own, which is nil if there are none.
+func splitCounterRates(sr *gnmi.SetRequest) (*gnmi.SetRequest, *gnmi.SetRequest) {
+	oc, rates := new(gnmi.SetRequest), new(gnmi.SetRequest)
+	for _, p := range sr.Delete {
+		if p.Origin == counterRateOrigin {
+			rates.Delete = append(rates.Delete, p)
+		} else {
+			oc.Delete = append(oc.Delete, p)
+		}
+	}
+	for _, u := range sr.Replace {
+		if u.Path.Origin == counterRateOrigin {
+			rates.Replace = append(rates.Replace, u)
+		} else {
+			oc.Replace = append(oc.Replace, u)
+		}
+	}
+	for _, u := range sr.Update {
+		if u.Path.Origin == counterRateOrigin {
+			rates.Update = append(rates.Update, u)
+		} else {
+			oc.Update = append(oc.Update, u)
+		}
+	}
+	if rates.Delete == nil && rates.Replace == nil && rates.Update == nil {
+		return sr, nil
+	}
+	return oc, rates
+}
+
+// counterRatePath returns the path of the rate of the counter at the
+// specified path, which is the counter's own path in the
+// counterRateOrigin origin.
+func counterRatePath(path string) *gnmi.Path {
+	p := pgnmi.PathFromString(path)
+	p.Origin = counterRateOrigin
+	return p
+}
+
+// sysUpTime returns the sysUpTime of the current poll, if known.
+func sysUpTime(ps pdu.Store) (uint32, bool) {
+	p, err := ps.GetScalar("sysUpTimeInstance")
+	if err != nil || p == nil {
+		return 0, false
+	}
+	u, err := provider.ToUint64(p.Value)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(u), true
+}
+
+// ifCounterDiscontinuities returns the ifCounterDiscontinuityTime of
+// each interface that reports one, keyed by ifIndex.
+func ifCounterDiscontinuities(ss smi.Store, ps pdu.Store,
+	mapperData *sync.Map, logger Logger) (map[string]int, error) {
+	if v, ok := mapperData.Load("ifCounterDiscontinuityTime"); ok {
+		return v.(map[string]int), nil
+	}
+	m, err := tabularInts(ss, ps, logger, "ifCounterDiscontinuityTime")
+	if err != nil {
+		return nil, err
+	}
+	mapperData.Store("ifCounterDiscontinuityTime", m)
+	return m, nil
+}
+
+// ifCounterMapperFn returns a Mapper for an ifTable or ifXTable
+// counter column. If the translator's counterStore is available in
+// mapperData, values are run through it.
+func ifCounterMapperFn(path, oid string) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		pdus, err := getTabular(ps, oid)
+		if err != nil || pdus == nil {
+			return nil, err
+		}
+		var cs *counterStore
+		if v, ok := mapperData.Load("counters"); ok {
+			cs = v.(*counterStore)
+		}
+		uptime, hasUptime := sysUpTime(ps)
+		discontinuities, err := ifCounterDiscontinuities(ss, ps, mapperData, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		updates := []*gnmi.Update{}
+		for _, p := range pdus {
+			ifIndex, err := firstIndex(ss, p)
+			if err != nil {
+				logger.Debugf("Skipping %s: %v", p.Name, err)
+				continue
+			}
+			intfName, err := intfNameForIndex(ss, ps, mapperData, ifIndex)
+			if err != nil {
+				return nil, err
+			}
+			fullPath := fmt.Sprintf(path, intfName)
+			raw, err := provider.ToUint64(p.Value)
+			if err != nil {
+				logger.Debugf("Skipping counter %s: %v", fullPath, err)
+				continue
+			}
+			if cs == nil {
+				updates = append(updates, update(pgnmi.PathFromString(fullPath),
+					uintval(raw)))
+				continue
+			}
+			bits := 32
+			if p.Type == gosnmp.Counter64 {
+				bits = 64
+			}
+			val, rate, ok := cs.sample(fullPath, raw, bits, uptime, hasUptime,
+				uint32(discontinuities[ifIndex]), logger)
+			updates = append(updates, update(pgnmi.PathFromString(fullPath), uintval(val)))
+			if ok {
+				updates = append(updates,
+					update(counterRatePath(fullPath), pgnmi.Floatval(rate)))
+			}
+		}
+		return updates, nil
+	}
+}
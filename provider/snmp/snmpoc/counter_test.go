@@ -0,0 +1,244 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmpoc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestCounterSample(t *testing.T) {
+	now = func() time.Time {
+		return time.Unix(1554954972, 0)
+	}
+	for _, tc := range []struct {
+		name     string
+		extend32 bool
+		bits     int
+		samples  []uint64
+		uptimes  []uint32 // nil if unknown
+		discont  []uint32 // nil if unknown
+		values   []uint64
+		rates    []float64 // -1 if none
+	}{
+		{
+			name:    "noWrap",
+			bits:    32,
+			samples: []uint64{100, 300},
+			uptimes: []uint32{1000, 1200},
+			values:  []uint64{100, 300},
+			rates:   []float64{-1, 100},
+		},
+		{
+			name:    "wrap32",
+			bits:    32,
+			samples: []uint64{4294967000, 704},
+			uptimes: []uint32{1000, 1100},
+			values:  []uint64{4294967000, 704},
+			rates:   []float64{-1, 1000},
+		},
+		{
+			name:     "wrap32Extended",
+			extend32: true,
+			bits:     32,
+			samples:  []uint64{4294967000, 704, 1704},
+			uptimes:  []uint32{1000, 1100, 1200},
+			values:   []uint64{4294967000, 4294968000, 4294969000},
+			rates:    []float64{-1, 1000, 1000},
+		},
+		{
+			name:     "restart",
+			extend32: true,
+			bits:     32,
+			samples:  []uint64{4294967000, 704, 50},
+			uptimes:  []uint32{1000, 1100, 500},
+			values:   []uint64{4294967000, 4294968000, 50},
+			rates:    []float64{-1, 1000, -1},
+		},
+		{
+			name:    "cleared32",
+			bits:    32,
+			samples: []uint64{5000, 20},
+			uptimes: []uint32{1000, 1100},
+			discont: []uint32{0, 1050},
+			values:  []uint64{5000, 20},
+			rates:   []float64{-1, -1},
+		},
+		{
+			name:    "reset32",
+			bits:    32,
+			samples: []uint64{5000, 20},
+			uptimes: []uint32{1000, 1000},
+			values:  []uint64{5000, 20},
+			rates:   []float64{-1, -1},
+		},
+		{
+			name:     "decrease32WithoutUptime",
+			extend32: true,
+			bits:     32,
+			samples:  []uint64{4294967000, 704},
+			values:   []uint64{4294967000, 704},
+			rates:    []float64{-1, -1},
+		},
+		{
+			name:    "reset64",
+			bits:    64,
+			samples: []uint64{5000, 20},
+			uptimes: []uint32{1000, 1100},
+			values:  []uint64{5000, 20},
+			rates:   []float64{-1, -1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := newCounterStore()
+			cs.setOptions(tc.extend32, true)
+			for i, raw := range tc.samples {
+				var uptime, discont uint32
+				if tc.uptimes != nil {
+					uptime = tc.uptimes[i]
+				}
+				if tc.discont != nil {
+					discont = tc.discont[i]
+				}
+				val, rate, ok := cs.sample("c", raw, tc.bits, uptime, tc.uptimes != nil,
+					discont, &nonlogger{})
+				if val != tc.values[i] {
+					t.Errorf("sample %d: expected value %d, got %d", i, tc.values[i], val)
+				}
+				if ok != (tc.rates[i] >= 0) || (ok && rate != tc.rates[i]) {
+					t.Errorf("sample %d: expected rate %v, got %v (%v)",
+						i, tc.rates[i], rate, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestCounterPolls(t *testing.T) {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error in smi.NewStore: %s", err)
+	}
+	trans, err := NewTranslator(mibStore, &gosnmp.GoSNMP{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans.Mock = true
+	trans.ExtendCounters = true
+	trans.CounterRates = true
+
+	var responses map[string][]*gosnmp.SnmpPDU
+	trans.Getter = func(oids []string) (*gosnmp.SnmpPacket, error) {
+		return mockget(oids, responses, mibStore)
+	}
+	trans.Walker = func(oid string, walker gosnmp.WalkFunc) error {
+		return mockwalk(oid, walker, responses, mibStore)
+	}
+	now = func() time.Time {
+		return time.Unix(1554954972, 0)
+	}
+
+	counterPath := pgnmi.Path("interfaces",
+		pgnmi.ListWithKey("interface", "name", "Ethernet1"), "state", "counters",
+		"in-octets")
+	ratePath := pgnmi.Path("interfaces",
+		pgnmi.ListWithKey("interface", "name", "Ethernet1"), "state", "counters",
+		"in-octets")
+	ratePath.Origin = counterRateOrigin
+	rateRoot := pgnmi.Path("interfaces")
+	rateRoot.Origin = counterRateOrigin
+
+	for i, poll := range []struct {
+		uptime  uint32
+		octets  uint
+		discont uint32
+		updates []*gnmi.Update
+		rates   []*gnmi.Update
+	}{
+		{
+			uptime: 1000,
+			octets: 4294967000,
+			updates: []*gnmi.Update{
+				update(counterPath, uintval(4294967000)),
+			},
+		},
+		{
+			uptime: 1100,
+			octets: 704,
+			updates: []*gnmi.Update{
+				update(counterPath, uintval(4294968000)),
+			},
+			rates: []*gnmi.Update{
+				update(ratePath, pgnmi.Floatval(1000)),
+			},
+		},
+		{
+			uptime: 500,
+			octets: 50,
+			updates: []*gnmi.Update{
+				update(counterPath, uintval(50)),
+			},
+		},
+		{
+			uptime:  600,
+			octets:  20,
+			discont: 550,
+			updates: []*gnmi.Update{
+				update(counterPath, uintval(20)),
+			},
+		},
+	} {
+		responses = map[string][]*gosnmp.SnmpPDU{
+			"sysUpTimeInstance": []*gosnmp.SnmpPDU{
+				PDU("sysUpTimeInstance", timeticks, poll.uptime),
+			},
+			"ifTable": []*gosnmp.SnmpPDU{
+				PDU(".1.3.6.1.2.1.2.2.1.2.1", octstr, []byte("Ethernet1")),
+				PDU(".1.3.6.1.2.1.2.2.1.10.1", counter, poll.octets),
+			},
+			"ifXTable": []*gosnmp.SnmpPDU{
+				PDU(".1.3.6.1.2.1.31.1.1.1.19.1", timeticks, poll.discont),
+			},
+		}
+		// Rates go through their own client, as they aren't
+		// OpenConfig.
+		setReqs, rateReqs := []*gnmi.SetRequest{}, []*gnmi.SetRequest{}
+		client := pgnmi.NewSimpleGNMIClient(func(ctx context.Context,
+			req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+			setReqs = append(setReqs, req)
+			return nil, nil
+		})
+		trans.RateClient = pgnmi.NewSimpleGNMIClient(func(ctx context.Context,
+			req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+			rateReqs = append(rateReqs, req)
+			return nil, nil
+		})
+		err := trans.Poll(context.Background(), client,
+			[]string{"^/interfaces/interface\\[name=name\\]/state/in-octets$"})
+		if err != nil {
+			t.Fatalf("Failure in translator.Poll: %v", err)
+		}
+		expected := &gnmi.SetRequest{
+			Delete:  []*gnmi.Path{pgnmi.Path("interfaces")},
+			Replace: poll.updates,
+		}
+		if len(setReqs) != 1 || !setRequestsEqual(setReqs[0], expected) {
+			t.Fatalf("poll %d: expected %v, got %v", i, expected, setReqs)
+		}
+		expected = &gnmi.SetRequest{
+			Delete:  []*gnmi.Path{rateRoot},
+			Replace: poll.rates,
+		}
+		if len(rateReqs) != 1 || !setRequestsEqual(rateReqs[0], expected) {
+			t.Fatalf("poll %d: expected rates %v, got %v", i, expected, rateReqs)
+		}
+	}
+}
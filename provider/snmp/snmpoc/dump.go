@@ -6,10 +6,11 @@ package snmpoc
 
 import (
 	"encoding/hex"
+	"fmt"
 	"strconv"
 	"strings"
 
-	"github.com/soniah/gosnmp"
+	"github.com/gosnmp/gosnmp"
 )
 
 // This file contains functionality useful for producing a dump from
@@ -30,6 +31,11 @@ import (
 // For example:
 //
 // WALK: .1.3.6.1.2.1.47.1.1.1.1
+//
+// The responses to a request follow it. A dump of several polls marks
+// the start of each with a line of the format:
+//
+// POLL: <number>
 
 // SNMP PDU types of interest.
 const (
@@ -37,17 +43,35 @@ const (
 	counter             = gosnmp.Counter32
 	counter64           = gosnmp.Counter64
 	integer             = gosnmp.Integer
-	timeticks           = gosnmp.TimeTicks // nolint: deadcode
+	timeticks           = gosnmp.TimeTicks
+	gauge               = gosnmp.Gauge32
+	ipaddr              = gosnmp.IPAddress
+	objectid            = gosnmp.ObjectIdentifier
 	octstrTypeString    = "STRING"
 	hexstrTypeString    = "Hex-STRING"
 	integerTypeString   = "INTEGER"
 	counterTypeString   = "Counter32"
 	counter64TypeString = "Counter64"
-	getString           = "GET"       // nolint: deadcode
-	walkString          = "WALK"      // nolint: deadcode
-	timeticksString     = "Timeticks" // nolint: deadcode
+	gaugeTypeString     = "Gauge32"
+	ipaddrTypeString    = "IpAddress"
+	oidTypeString       = "OID"
+	getString           = "GET"
+	walkString          = "WALK"
+	pollString          = "POLL"
+	timeticksString     = "Timeticks"
+
+	// snmpwalk prints empty strings with no type.
+	emptyTypeString = `""`
 )
 
+// snmpwalk prints these exceptions in place of a type and value.
+var exceptionStrings = map[gosnmp.Asn1BER]string{
+	gosnmp.NoSuchObject:   "No Such Object available on this agent at this OID",
+	gosnmp.NoSuchInstance: "No Such Instance currently exists at this OID",
+	gosnmp.EndOfMibView: "No more variables left in this MIB View " +
+		"(It is past the end of the MIB tree)",
+}
+
 // PDU creation wrapper.
 func PDU(name string, t gosnmp.Asn1BER, val interface{}) *gosnmp.SnmpPDU {
 	return &gosnmp.SnmpPDU{
@@ -110,6 +134,23 @@ func pduFromString(s string) *gosnmp.SnmpPDU {
 		pduType = counter64
 		v, _ := strconv.ParseUint(val, 10, 64)
 		value = v
+	case gaugeTypeString:
+		pduType = gauge
+		v, _ := strconv.ParseUint(val, 10, 32)
+		value = uint(v)
+	case ipaddrTypeString:
+		pduType = ipaddr
+		value = val
+	case oidTypeString:
+		pduType = objectid
+		value = val
+	case timeticksString:
+		pduType = timeticks
+		v, _ := strconv.ParseUint(val, 10, 32)
+		value = uint32(v)
+	case emptyTypeString:
+		pduType = octstr
+		value = []byte{}
 	default:
 		return nil
 	}
@@ -128,3 +169,94 @@ func PDUsFromString(s string) []*gosnmp.SnmpPDU {
 	}
 	return pdus
 }
+
+// GetString returns the string representation of a GET request for
+// the specified OID.
+func GetString(oid string) string {
+	return getString + ": " + oid
+}
+
+// WalkString returns the string representation of a walk of the
+// specified OID.
+func WalkString(oid string) string {
+	return walkString + ": " + oid
+}
+
+// PollString returns the marker of the start of the specified poll.
+func PollString(poll int) string {
+	return pollString + ": " + strconv.Itoa(poll)
+}
+
+// IsPollString returns whether a line of a dump marks the start of a
+// poll.
+func IsPollString(s string) bool {
+	return strings.HasPrefix(s, pollString+": ")
+}
+
+// plainString returns whether an octet string can be dumped as a
+// STRING that pduFromString will read back unchanged.
+func plainString(b []byte) bool {
+	for _, c := range b {
+		if c < ' ' || c > '~' || c == '"' || c == '(' || c == ')' {
+			return false
+		}
+	}
+	s := string(b)
+	return !strings.Contains(s, ": ") && !strings.Contains(s, " = ")
+}
+
+// formatTimeticks formats hundredths of a second the way snmpwalk does.
+func formatTimeticks(ticks uint64) string {
+	days := ticks / 8640000
+	s := fmt.Sprintf("%d:%02d:%02d.%02d", ticks/360000%24, ticks/6000%60,
+		ticks/100%60, ticks%100)
+	switch {
+	case days == 1:
+		s = "1 day, " + s
+	case days > 1:
+		s = fmt.Sprintf("%d days, %s", days, s)
+	}
+	return s
+}
+
+// PDUToString returns the string representation of a PDU, as snmpwalk
+// -O ne prints it. Octet strings that wouldn't read back unchanged
+// are written in hex. It returns an empty string for PDUs of types
+// the dump format doesn't support.
+func PDUToString(pdu gosnmp.SnmpPDU) string {
+	if e, ok := exceptionStrings[pdu.Type]; ok {
+		return pdu.Name + " = " + e
+	}
+	var typeString, value string
+	switch pdu.Type {
+	case integer:
+		typeString, value = integerTypeString, gosnmp.ToBigInt(pdu.Value).String()
+	case octstr:
+		b, _ := pdu.Value.([]byte)
+		if len(b) == 0 {
+			return pdu.Name + " = " + emptyTypeString
+		}
+		if plainString(b) {
+			typeString, value = octstrTypeString, `"`+string(b)+`"`
+		} else {
+			typeString, value = hexstrTypeString, strings.ToUpper(fmt.Sprintf("% x", b))
+		}
+	case counter:
+		typeString, value = counterTypeString, gosnmp.ToBigInt(pdu.Value).String()
+	case counter64:
+		typeString, value = counter64TypeString, gosnmp.ToBigInt(pdu.Value).String()
+	case gauge:
+		typeString, value = gaugeTypeString, gosnmp.ToBigInt(pdu.Value).String()
+	case timeticks:
+		ticks := gosnmp.ToBigInt(pdu.Value).Uint64()
+		typeString = timeticksString
+		value = fmt.Sprintf("(%d) %s", ticks, formatTimeticks(ticks))
+	case ipaddr:
+		typeString, value = ipaddrTypeString, fmt.Sprint(pdu.Value)
+	case objectid:
+		typeString, value = oidTypeString, fmt.Sprint(pdu.Value)
+	default:
+		return ""
+	}
+	return pdu.Name + " = " + typeString + ": " + value
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmpoc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestPDUToString(t *testing.T) {
+	for _, tc := range []struct {
+		pdu *gosnmp.SnmpPDU
+		s   string
+	}{
+		{
+			pdu: PDU(".1.3.6.1.2.1.1.5.0", octstr, []byte("switch1")),
+			s:   `.1.3.6.1.2.1.1.5.0 = STRING: "switch1"`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.1.1.0", octstr, []byte("EOS (4.21): x")),
+			s:   `.1.3.6.1.2.1.1.1.0 = Hex-STRING: 45 4F 53 20 28 34 2E 32 31 29 3A 20 78`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.2.2.1.6.1", octstr, []byte{0, 0x1c, 0x73, 0xff}),
+			s:   `.1.3.6.1.2.1.2.2.1.6.1 = Hex-STRING: 00 1C 73 FF`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.1.4.0", octstr, []byte{}),
+			s:   `.1.3.6.1.2.1.1.4.0 = ""`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.2.2.1.8.1", integer, -2),
+			s:   `.1.3.6.1.2.1.2.2.1.8.1 = INTEGER: -2`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.2.2.1.10.1", counter, uint(4294967295)),
+			s:   `.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 4294967295`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.31.1.1.1.6.1", counter64, uint64(1<<40)),
+			s:   `.1.3.6.1.2.1.31.1.1.1.6.1 = Counter64: 1099511627776`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.2.2.1.5.1", gauge, uint(1000000000)),
+			s:   `.1.3.6.1.2.1.2.2.1.5.1 = Gauge32: 1000000000`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.1.3.0", timeticks, uint32(18000001)),
+			s:   `.1.3.6.1.2.1.1.3.0 = Timeticks: (18000001) 2 days, 2:00:00.01`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.4.20.1.1.10.0.0.1", ipaddr, "10.0.0.1"),
+			s:   `.1.3.6.1.2.1.4.20.1.1.10.0.0.1 = IpAddress: 10.0.0.1`,
+		},
+		{
+			pdu: PDU(".1.3.6.1.2.1.1.2.0", objectid, ".1.3.6.1.4.1.30065.1"),
+			s:   `.1.3.6.1.2.1.1.2.0 = OID: .1.3.6.1.4.1.30065.1`,
+		},
+	} {
+		s := PDUToString(*tc.pdu)
+		if s != tc.s {
+			t.Errorf("Expected %q, got %q", tc.s, s)
+			continue
+		}
+		pdus := PDUsFromString(s)
+		if len(pdus) != 1 || !reflect.DeepEqual(pdus[0], tc.pdu) {
+			t.Errorf("PDU %v did not read back unchanged from %q: %v", tc.pdu, s, pdus)
+		}
+	}
+
+	// Exceptions are dumped, but not read back.
+	s := PDUToString(gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.1.9.0", Type: gosnmp.NoSuchObject})
+	if s != ".1.3.6.1.2.1.1.9.0 = No Such Object available on this agent at this OID" {
+		t.Errorf("Unexpected noSuchObject string %q", s)
+	}
+	if pdus := PDUsFromString(s); len(pdus) != 0 {
+		t.Errorf("Expected no PDUs from %q, got %v", s, pdus)
+	}
+}
+
+func TestRequestStrings(t *testing.T) {
+	for _, s := range []string{
+		GetString(".1.3.6.1.2.1.1.5.0"),
+		WalkString(".1.3.6.1.2.1.2.2"),
+		PollString(2),
+	} {
+		if pdus := PDUsFromString(s); len(pdus) != 0 {
+			t.Errorf("Expected no PDUs from %q, got %v", s, pdus)
+		}
+	}
+	if !IsPollString(PollString(1)) || IsPollString(WalkString(".1")) {
+		t.Error("IsPollString doesn't recognize poll markers")
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmpoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/pdu"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// hrStorageRam is the hrStorageType of RAM, as defined in
+// HOST-RESOURCES-TYPES.
+const hrStorageRam = "1.3.6.1.2.1.25.2.1.2"
+
+// processorLoad is an hrProcessorTable row. Processors are keyed by
+// their hrDeviceIndex, which stays the same as other processors come
+// and go.
+type processorLoad struct {
+	index int
+	load  int
+}
+
+func processorLoads(ss smi.Store, ps pdu.Store,
+	mapperData *sync.Map, logger Logger) ([]*processorLoad, error) {
+	if v, ok := mapperData.Load("hrProcessorLoad"); ok {
+		return v.([]*processorLoad), nil
+	}
+
+	pdus, err := getTabular(ps, "hrProcessorLoad")
+	if err != nil {
+		return nil, err
+	}
+	loads := []*processorLoad{}
+	for _, p := range pdus {
+		// The index is hrDeviceIndex.
+		index, err := pdu.DecodeIndexes(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		device, ok := index[0].Value.(int)
+		if !ok {
+			logger.Debugf("Skipping %s: bad hrDeviceIndex", p.Name)
+			continue
+		}
+		load, err := provider.ToInt(p.Value)
+		if err != nil {
+			logger.Debugf("Skipping processor %d: %v", device, err)
+			continue
+		}
+		loads = append(loads, &processorLoad{index: device, load: load})
+	}
+	sort.Slice(loads, func(i, j int) bool {
+		return loads[i].index < loads[j].index
+	})
+
+	mapperData.Store("hrProcessorLoad", loads)
+	return loads, nil
+}
+
+func cpuMapperFn(leaf string, vp func(*processorLoad) *gnmi.TypedValue) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		loads, err := processorLoads(ss, ps, mapperData, logger)
+		if err != nil {
+			return nil, err
+		}
+		updates := []*gnmi.Update{}
+		for _, l := range loads {
+			path := fmt.Sprintf(cpuPath, l.index) + leaf
+			updates = append(updates, update(pgnmi.PathFromString(path), vp(l)))
+		}
+		return updates, nil
+	}
+}
+
+// memoryUsage is the total size and usage, in bytes, of the RAM
+// entries in hrStorageTable.
+type memoryUsage struct {
+	physical uint64
+	used     uint64
+}
+
+func ramUsage(ss smi.Store, ps pdu.Store,
+	mapperData *sync.Map, logger Logger) (*memoryUsage, error) {
+	if v, ok := mapperData.Load("hrStorageTable"); ok {
+		return v.(*memoryUsage), nil
+	}
+
+	units, err := tabularInts(ss, ps, logger, "hrStorageAllocationUnits")
+	if err != nil {
+		return nil, err
+	}
+	sizes, err := tabularInts(ss, ps, logger, "hrStorageSize")
+	if err != nil {
+		return nil, err
+	}
+	used, err := tabularInts(ss, ps, logger, "hrStorageUsed")
+	if err != nil {
+		return nil, err
+	}
+	pdus, err := getTabular(ps, "hrStorageType")
+	if err != nil {
+		return nil, err
+	}
+
+	var mu *memoryUsage
+	for _, p := range pdus {
+		t, ok := p.Value.(string)
+		if !ok || strings.TrimPrefix(t, ".") != hrStorageRam {
+			continue
+		}
+		key, err := instanceKey(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		if units[key] <= 0 || sizes[key] < 0 || used[key] < 0 {
+			logger.Debugf("Skipping hrStorageTable entry %s with bad size", key)
+			continue
+		}
+		if mu == nil {
+			mu = &memoryUsage{}
+		}
+		mu.physical += uint64(sizes[key]) * uint64(units[key])
+		mu.used += uint64(used[key]) * uint64(units[key])
+	}
+
+	mapperData.Store("hrStorageTable", mu)
+	return mu, nil
+}
+
+func memoryMapperFn(leaf string, vp func(*memoryUsage) *gnmi.TypedValue) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		mu, err := ramUsage(ss, ps, mapperData, logger)
+		if err != nil || mu == nil {
+			return nil, err
+		}
+		return []*gnmi.Update{
+			update(pgnmi.PathFromString(memoryStatePath+leaf), vp(mu)),
+		}, nil
+	}
+}
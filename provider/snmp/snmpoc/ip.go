@@ -0,0 +1,589 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmpoc
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/openconfig"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/pdu"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// indexKey returns the index portion of a columnar PDU's OID from
+// its decoded indexes.
+func indexKey(index []pdu.IndexValue) string {
+	ss := make([]string, len(index))
+	for i, v := range index {
+		ss[i] = v.Instance
+	}
+	return strings.Join(ss, ".")
+}
+
+// instanceKey returns the index portion of a columnar PDU's OID. It
+// fails if the OID doesn't encode its object's indexes.
+func instanceKey(ss smi.Store, p *gosnmp.SnmpPDU) (string, error) {
+	index, err := pdu.DecodeIndexes(ss, p)
+	if err != nil {
+		return "", err
+	}
+	return indexKey(index), nil
+}
+
+// inetAddress returns the address family ("ipv4" or "ipv6") and the
+// address of an InetAddressType and InetAddress index pair, or an
+// empty family for address types we don't translate.
+func inetAddress(addrType, addr pdu.IndexValue) (string, string) {
+	t, _ := addrType.Value.(int)
+	b, _ := addr.Value.([]byte)
+	switch {
+	case t == 1 && len(b) == net.IPv4len:
+		return "ipv4", net.IP(b).String()
+	case t == 2 && len(b) == net.IPv6len:
+		return "ipv6", net.IP(b).String()
+	}
+	return "", ""
+}
+
+// tabularInts returns the integer values of a column keyed by
+// instance index, skipping PDUs with bad indexes.
+func tabularInts(ss smi.Store, ps pdu.Store, logger Logger,
+	oid string) (map[string]int, error) {
+	pdus, err := getTabular(ps, oid)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]int)
+	for _, p := range pdus {
+		key, err := instanceKey(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		if v, err := provider.ToInt(p.Value); err == nil {
+			m[key] = v
+		}
+	}
+	return m, nil
+}
+
+// sortedPDUs returns the PDUs of a column in a stable order.
+func sortedPDUs(ps pdu.Store, oid string) ([]*gosnmp.SnmpPDU, error) {
+	pdus, err := getTabular(ps, oid)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pdus, func(i, j int) bool {
+		return pdus[i].Name < pdus[j].Name
+	})
+	return pdus, nil
+}
+
+// ipAddress is an interface address from ipAddressTable or ipAddrTable.
+type ipAddress struct {
+	intfName  string
+	family    string
+	ip        string
+	prefixLen int // -1 if unknown
+}
+
+type ipAddressSource func(smi.Store, pdu.Store, *sync.Map, Logger) ([]*ipAddress, error)
+
+// prefixLengthFromPointer returns the prefix length from an
+// ipAddressPrefix value, which points to an ipAddressPrefixTable row
+// whose last index is the prefix length.
+func prefixLengthFromPointer(v interface{}) int {
+	s, ok := v.(string)
+	if !ok {
+		return -1
+	}
+	s = strings.TrimPrefix(s, ".")
+	if s == "0.0" {
+		return -1
+	}
+	i, err := strconv.Atoi(s[strings.LastIndex(s, ".")+1:])
+	if err != nil {
+		return -1
+	}
+	return i
+}
+
+func ipAddressesFromIPAddressTable(ss smi.Store, ps pdu.Store,
+	mapperData *sync.Map, logger Logger) ([]*ipAddress, error) {
+	if v, ok := mapperData.Load("ipAddressTable"); ok {
+		return v.([]*ipAddress), nil
+	}
+
+	prefixes := make(map[string]int)
+	pdus, err := getTabular(ps, "ipAddressPrefix")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pdus {
+		key, err := instanceKey(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		prefixes[key] = prefixLengthFromPointer(p.Value)
+	}
+	addrTypes, err := tabularInts(ss, ps, logger, "ipAddressType")
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := []*ipAddress{}
+	pdus, err = getTabular(ps, "ipAddressIfIndex")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pdus {
+		index, err := pdu.DecodeIndexes(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		key := indexKey(index)
+		family, ip := inetAddress(index[0], index[1])
+		// Skip unsupported address types and broadcast(3) addresses.
+		if family == "" || addrTypes[key] == 3 {
+			continue
+		}
+		intfName, err := intfNameForIndex(ss, ps, mapperData,
+			fmt.Sprintf("%v", p.Value))
+		if err != nil {
+			logger.Debugf("Skipping address %s: %v", ip, err)
+			continue
+		}
+		prefixLen, ok := prefixes[key]
+		if !ok {
+			prefixLen = -1
+		}
+		addrs = append(addrs, &ipAddress{
+			intfName:  intfName,
+			family:    family,
+			ip:        ip,
+			prefixLen: prefixLen,
+		})
+	}
+
+	mapperData.Store("ipAddressTable", addrs)
+	return addrs, nil
+}
+
+func ipAddressesFromIPAddrTable(ss smi.Store, ps pdu.Store,
+	mapperData *sync.Map, logger Logger) ([]*ipAddress, error) {
+	if v, ok := mapperData.Load("ipAddrTable"); ok {
+		return v.([]*ipAddress), nil
+	}
+
+	masks := make(map[string]int)
+	pdus, err := getTabular(ps, "ipAdEntNetMask")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pdus {
+		key, err := instanceKey(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		s, _ := p.Value.(string)
+		if ip := net.ParseIP(s).To4(); ip != nil {
+			if ones, bits := net.IPMask(ip).Size(); bits != 0 {
+				masks[key] = ones
+			}
+		}
+	}
+
+	addrs := []*ipAddress{}
+	pdus, err = getTabular(ps, "ipAdEntIfIndex")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pdus {
+		key, err := instanceKey(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		ip := net.ParseIP(key)
+		if ip == nil {
+			logger.Debugf("Skipping %s: bad ipAdEntAddr '%s'", p.Name, key)
+			continue
+		}
+		intfName, err := intfNameForIndex(ss, ps, mapperData,
+			fmt.Sprintf("%v", p.Value))
+		if err != nil {
+			logger.Debugf("Skipping address %s: %v", ip, err)
+			continue
+		}
+		prefixLen, ok := masks[key]
+		if !ok {
+			prefixLen = -1
+		}
+		addrs = append(addrs, &ipAddress{
+			intfName:  intfName,
+			family:    "ipv4",
+			ip:        ip.String(),
+			prefixLen: prefixLen,
+		})
+	}
+
+	mapperData.Store("ipAddrTable", addrs)
+	return addrs, nil
+}
+
+func ipAddressMapperFn(source ipAddressSource, family, leaf string,
+	vp func(*ipAddress) *gnmi.TypedValue) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		addrs, err := source(ss, ps, mapperData, logger)
+		if err != nil {
+			return nil, err
+		}
+		updates := []*gnmi.Update{}
+		for _, a := range addrs {
+			if a.family != family {
+				continue
+			}
+			val := vp(a)
+			if val == nil {
+				continue
+			}
+			path := fmt.Sprintf(ipAddressPath, a.intfName, family, a.ip) + leaf
+			updates = append(updates, update(pgnmi.PathFromString(path), val))
+		}
+		return updates, nil
+	}
+}
+
+// subinterfaceMapperFn produces updates for subinterface 0 of each
+// interface with an address, which is where OpenConfig keeps the
+// addresses and neighbors of an unchannelized interface.
+func subinterfaceMapperFn(source ipAddressSource, leaf string) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		addrs, err := source(ss, ps, mapperData, logger)
+		if err != nil {
+			return nil, err
+		}
+		updates := []*gnmi.Update{}
+		seen := make(map[string]bool)
+		for _, a := range addrs {
+			if seen[a.intfName] {
+				continue
+			}
+			seen[a.intfName] = true
+			path := fmt.Sprintf(subinterfacePath, a.intfName) + leaf
+			updates = append(updates, update(pgnmi.PathFromString(path), uintval(0)))
+		}
+		return updates, nil
+	}
+}
+
+// ipNeighbor is an ARP or neighbor cache entry from
+// ipNetToPhysicalTable.
+type ipNeighbor struct {
+	intfName string
+	family   string
+	ip       string
+	mac      string
+	origin   string
+}
+
+// ipNetToPhysicalType values, other than invalid(2), mapped to
+// OpenConfig neighbor origins.
+var neighborOrigins = map[int]string{
+	1: "OTHER",
+	3: "DYNAMIC",
+	4: "STATIC",
+	5: "OTHER",
+}
+
+func ipNeighbors(ss smi.Store, ps pdu.Store,
+	mapperData *sync.Map, logger Logger) ([]*ipNeighbor, error) {
+	if v, ok := mapperData.Load("ipNetToPhysicalTable"); ok {
+		return v.([]*ipNeighbor), nil
+	}
+
+	types, err := tabularInts(ss, ps, logger, "ipNetToPhysicalType")
+	if err != nil {
+		return nil, err
+	}
+
+	neighbors := []*ipNeighbor{}
+	pdus, err := getTabular(ps, "ipNetToPhysicalPhysAddress")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pdus {
+		index, err := pdu.DecodeIndexes(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		key := indexKey(index)
+		family, ip := inetAddress(index[1], index[2])
+		b, _ := p.Value.([]byte)
+		if family == "" || len(b) == 0 || types[key] == 2 {
+			continue
+		}
+		intfName, err := intfNameForIndex(ss, ps, mapperData, index[0].Instance)
+		if err != nil {
+			logger.Debugf("Skipping neighbor %s: %v", ip, err)
+			continue
+		}
+		neighbors = append(neighbors, &ipNeighbor{
+			intfName: intfName,
+			family:   family,
+			ip:       ip,
+			mac:      MacFromBytes(b),
+			origin:   neighborOrigins[types[key]],
+		})
+	}
+
+	mapperData.Store("ipNetToPhysicalTable", neighbors)
+	return neighbors, nil
+}
+
+func ipNeighborMapperFn(family, leaf string, vp func(*ipNeighbor) *gnmi.TypedValue) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		neighbors, err := ipNeighbors(ss, ps, mapperData, logger)
+		if err != nil {
+			return nil, err
+		}
+		updates := []*gnmi.Update{}
+		for _, n := range neighbors {
+			if n.family != family {
+				continue
+			}
+			val := vp(n)
+			if val == nil {
+				continue
+			}
+			path := fmt.Sprintf(ipNeighborPath, n.intfName, family, n.ip) + leaf
+			updates = append(updates, update(pgnmi.PathFromString(path), val))
+		}
+		return updates, nil
+	}
+}
+
+// aft is the device's routing table from inetCidrRouteTable, in the
+// shape of an OpenConfig abstract forwarding table: each prefix
+// points to a next-hop group, which lists next hops. Next hops and
+// next-hop groups are numbered from 1 in order of appearance.
+type aft struct {
+	entries  []*aftEntry
+	nextHops []*aftNextHop
+	groups   []*aftNextHopGroup
+}
+
+type aftEntry struct {
+	family   string
+	prefix   string
+	protocol string
+	group    uint64
+}
+
+type aftNextHop struct {
+	index    uint64
+	ip       string
+	intfName string
+}
+
+type aftNextHopGroup struct {
+	id       uint64
+	nextHops []uint64
+}
+
+func buildAft(ss smi.Store, ps pdu.Store,
+	mapperData *sync.Map, logger Logger) (*aft, error) {
+	if v, ok := mapperData.Load("inetCidrRouteTable"); ok {
+		return v.(*aft), nil
+	}
+
+	protocols, err := tabularInts(ss, ps, logger, "inetCidrRouteProto")
+	if err != nil {
+		return nil, err
+	}
+	types, err := tabularInts(ss, ps, logger, "inetCidrRouteType")
+	if err != nil {
+		return nil, err
+	}
+	pdus, err := sortedPDUs(ps, "inetCidrRouteIfIndex")
+	if err != nil {
+		return nil, err
+	}
+
+	a := &aft{}
+	entries := make(map[string]*aftEntry)
+	entryNextHops := make(map[string][]uint64)
+	nextHops := make(map[string]uint64)
+	for _, p := range pdus {
+		// The index is the destination, prefix length, route
+		// policy, and next hop.
+		index, err := pdu.DecodeIndexes(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		key := indexKey(index)
+		family, dest := inetAddress(index[0], index[1])
+		prefixLen := index[2].Value
+		_, nextHopIP := inetAddress(index[4], index[5])
+		if family == "" {
+			continue
+		}
+
+		prefix := fmt.Sprintf("%s/%d", dest, prefixLen)
+		entryKey := family + " " + prefix
+		e, ok := entries[entryKey]
+		if !ok {
+			e = &aftEntry{family: family, prefix: prefix}
+			if proto := openconfig.RouteProtocol(protocols[key]); proto != "" {
+				e.protocol = "openconfig-policy-types:" + proto
+			}
+			entries[entryKey] = e
+			a.entries = append(a.entries, e)
+		}
+
+		// reject(2) and blackhole(5) routes have no next hop.
+		if types[key] == 2 || types[key] == 5 {
+			continue
+		}
+		var intfName string
+		if ifIndex, err := provider.ToInt(p.Value); err == nil && ifIndex != 0 {
+			intfName, err = intfNameForIndex(ss, ps, mapperData, strconv.Itoa(ifIndex))
+			if err != nil {
+				logger.Debugf("No interface for route %s: %v", prefix, err)
+			}
+		}
+		if nextHopIP == "" && intfName == "" {
+			continue
+		}
+		nhKey := nextHopIP + " " + intfName
+		nh, ok := nextHops[nhKey]
+		if !ok {
+			nh = uint64(len(a.nextHops) + 1)
+			nextHops[nhKey] = nh
+			a.nextHops = append(a.nextHops, &aftNextHop{
+				index:    nh,
+				ip:       nextHopIP,
+				intfName: intfName,
+			})
+		}
+		entryNextHops[entryKey] = append(entryNextHops[entryKey], nh)
+	}
+
+	groups := make(map[string]uint64)
+	for _, e := range a.entries {
+		nhs := entryNextHops[e.family+" "+e.prefix]
+		if len(nhs) == 0 {
+			continue
+		}
+		sort.Slice(nhs, func(i, j int) bool { return nhs[i] < nhs[j] })
+		groupKey := fmt.Sprint(nhs)
+		id, ok := groups[groupKey]
+		if !ok {
+			id = uint64(len(a.groups) + 1)
+			groups[groupKey] = id
+			a.groups = append(a.groups, &aftNextHopGroup{id: id, nextHops: nhs})
+		}
+		e.group = id
+	}
+
+	mapperData.Store("inetCidrRouteTable", a)
+	return a, nil
+}
+
+func aftMapperFn(updates func(*aft) []*gnmi.Update) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		a, err := buildAft(ss, ps, mapperData, logger)
+		if err != nil {
+			return nil, err
+		}
+		return updates(a), nil
+	}
+}
+
+func networkInstanceMapperFn(leaf string) Mapper {
+	return aftMapperFn(func(a *aft) []*gnmi.Update {
+		if len(a.entries) == 0 {
+			return nil
+		}
+		return []*gnmi.Update{update(pgnmi.PathFromString(networkInstancePath+leaf),
+			strval(defaultNetworkInstance))}
+	})
+}
+
+func aftEntryMapperFn(family, leaf string, vp func(*aftEntry) *gnmi.TypedValue) Mapper {
+	return aftMapperFn(func(a *aft) []*gnmi.Update {
+		updates := []*gnmi.Update{}
+		for _, e := range a.entries {
+			if e.family != family {
+				continue
+			}
+			val := vp(e)
+			if val == nil {
+				continue
+			}
+			path := fmt.Sprintf(aftEntryPath, family, family, e.prefix) + leaf
+			updates = append(updates, update(pgnmi.PathFromString(path), val))
+		}
+		return updates
+	})
+}
+
+func aftNextHopMapperFn(leaf string, vp func(*aftNextHop) *gnmi.TypedValue) Mapper {
+	return aftMapperFn(func(a *aft) []*gnmi.Update {
+		updates := []*gnmi.Update{}
+		for _, nh := range a.nextHops {
+			val := vp(nh)
+			if val == nil {
+				continue
+			}
+			path := fmt.Sprintf(aftNextHopPath, nh.index) + leaf
+			updates = append(updates, update(pgnmi.PathFromString(path), val))
+		}
+		return updates
+	})
+}
+
+func aftNextHopGroupMapperFn(leaf string) Mapper {
+	return aftMapperFn(func(a *aft) []*gnmi.Update {
+		updates := []*gnmi.Update{}
+		for _, g := range a.groups {
+			path := fmt.Sprintf(aftNextHopGroupPath, g.id) + leaf
+			updates = append(updates, update(pgnmi.PathFromString(path), uintval(g.id)))
+		}
+		return updates
+	})
+}
+
+func aftNextHopGroupNextHopMapperFn(leaf string) Mapper {
+	return aftMapperFn(func(a *aft) []*gnmi.Update {
+		updates := []*gnmi.Update{}
+		for _, g := range a.groups {
+			for _, nh := range g.nextHops {
+				path := fmt.Sprintf(aftNextHopGroupPath+"next-hops/next-hop[index=%d]/",
+					g.id, nh) + leaf
+				updates = append(updates, update(pgnmi.PathFromString(path), uintval(nh)))
+			}
+		}
+		return updates
+	})
+}
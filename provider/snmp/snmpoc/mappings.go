@@ -19,8 +19,8 @@ import (
 	"github.com/aristanetworks/cloudvision-go/provider/openconfig"
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/pdu"
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
 	"github.com/openconfig/gnmi/proto/gnmi"
-	"github.com/soniah/gosnmp"
 )
 
 // A Mapper contains some logic for producing gNMI updates based on the
@@ -182,51 +182,43 @@ func processBootTime(x interface{}) *gnmi.TypedValue {
 	return intval(now().Unix() - t/100)
 }
 
-func setIndexStringMappings(ss smi.Store, ps pdu.Store,
-	mapperData *sync.Map, oid, indexName, mappingName string) error {
-	_, ok := mapperData.Load(mappingName)
-	if ok {
-		return nil
-	}
-
-	mp := map[string]string{}
-
-	pdus, err := getTabular(ps, oid)
+// firstIndex returns the value of the first index of a columnar PDU.
+func firstIndex(ss smi.Store, p *gosnmp.SnmpPDU) (string, error) {
+	values, err := pdu.IndexValues(ss, p)
 	if err != nil {
-		return err
+		return "", err
 	}
-
-	for _, p := range pdus {
-		indexVal, err := pdu.IndexValueByName(ss, p, indexName)
-		if err != nil {
-			return err
-		}
-		val := sanitizedString(p.Value)
-		mp[indexVal] = val
-	}
-
-	mapperData.Store(mappingName, mp)
-	return nil
-}
-
-func getMapperStringMapping(mapperData *sync.Map,
-	mappingName, key string) (string, error) {
-	m, ok := mapperData.Load(mappingName)
-	if !ok {
-		return "", fmt.Errorf("No mapping for '%s' in mapperData", mappingName)
+	if len(values) == 0 {
+		return "", fmt.Errorf("No index in OID '%s'", p.Name)
 	}
-	mp := m.(map[string]string)
-	return mp[key], nil
+	return values[0], nil
 }
 
 // interface helpers
-func getIntfName(mapperData *sync.Map, ifIndex string) (string, error) {
-	return getMapperStringMapping(mapperData, "intfName", ifIndex)
+
+// getIntfName returns the ifDescr of the interface with the specified
+// ifIndex, or "" if there's none.
+func getIntfName(ps pdu.Store, ifIndex string) (string, error) {
+	pdus, err := ps.GetTabular("ifDescr",
+		pdu.Index{Name: "ifIndex", Value: ifIndex})
+	if err != nil || len(pdus) == 0 {
+		return "", err
+	}
+	return sanitizedString(pdus[0].Value), nil
 }
 
-func setIntfNames(ss smi.Store, ps pdu.Store, mapperData *sync.Map) error {
-	return setIndexStringMappings(ss, ps, mapperData, "ifDescr",
-		"ifIndex", "intfName")
+// intfNameForIndex returns the interface name for the specified
+// ifIndex.
+func intfNameForIndex(ss smi.Store, ps pdu.Store, mapperData *sync.Map,
+	ifIndex string) (string, error) {
+	ifDescr, err := getIntfName(ps, ifIndex)
+	if err != nil {
+		return "", err
+	}
+	if ifDescr == "" {
+		return "", fmt.Errorf("No ifDescr for ifIndex '%s'", ifIndex)
+	}
+	return ifDescr, nil
 }
 
 // generic mapper for PDUs from ifTable
@@ -240,20 +232,15 @@ func ifTableMapper(ss smi.Store, ps pdu.Store,
 
 	updates := []*gnmi.Update{}
 	for _, p := range pdus {
-		ifIndex := pdu.IndexValues(ss, p)[0]
-		ifDescr, err := getIntfName(mapperData, ifIndex)
-		if err != nil || ifDescr == "" {
-			if err = setIntfNames(ss, ps, mapperData); err != nil {
-				return nil, err
-			}
+		ifIndex, err := firstIndex(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
 		}
-		ifDescr, err = getIntfName(mapperData, ifIndex)
+		ifDescr, err := intfNameForIndex(ss, ps, mapperData, ifIndex)
 		if err != nil {
 			return nil, err
 		}
-		if ifDescr == "" {
-			return nil, fmt.Errorf("No ifDescr for ifIndex '%s'", ifIndex)
-		}
 		fullPath := pgnmi.PathFromString(fmt.Sprintf(path, ifDescr))
 		updates = append(updates, update(fullPath, vp(p.Value)))
 	}
@@ -282,7 +269,7 @@ func alternateIntfName(intfName string) string {
 
 // Build a map from lldpLocPortNum -> ifDescr.
 func buildLldpLocPortNumMap(ss smi.Store, ps pdu.Store,
-	mapperData *sync.Map) error {
+	mapperData *sync.Map, logger Logger) error {
 	m, ok := mapperData.Load("lldpLocPortNum")
 	if !ok {
 		mapperData.Store("lldpLocPortNum", make(map[string]string))
@@ -297,9 +284,9 @@ func buildLldpLocPortNumMap(ss smi.Store, ps pdu.Store,
 	if err != nil {
 		return fmt.Errorf("buildLldpLocPortNumMap: %s", err)
 	}
-	ifDescrMap := make(map[string]bool)
-	for _, p := range ifDescrs {
-		ifDescrMap[string(p.Value.([]byte))] = true
+	isIfDescr := func(intfName string) (bool, error) {
+		pdus, err := ps.GetByValue("ifDescr", intfName)
+		return len(pdus) > 0, err
 	}
 
 	// XXX NOTE: The RFC says lldpLocPortDesc should have the
@@ -315,13 +302,25 @@ func buildLldpLocPortNumMap(ss smi.Store, ps pdu.Store,
 			return fmt.Errorf("buildLldpLocPortNumMap: %s", err)
 		}
 		for _, p := range pdus {
-			portNum := pdu.IndexValues(ss, p)[0]
+			portNum, err := firstIndex(ss, p)
+			if err != nil {
+				logger.Debugf("Skipping %s: %v", p.Name, err)
+				continue
+			}
 			intfName := string(p.Value.([]byte))
-			if _, ok := ifDescrMap[intfName]; !ok {
+			ok, err := isIfDescr(intfName)
+			if err != nil {
+				return fmt.Errorf("buildLldpLocPortNumMap: %s", err)
+			}
+			if !ok {
 				// We've seen some implementations where the lldpLocPortTable interface
 				// name is an abbreviation of the the ifTable name.
 				intfName = alternateIntfName(intfName)
-				if _, ok = ifDescrMap[intfName]; !ok {
+				ok, err = isIfDescr(intfName)
+				if err != nil {
+					return fmt.Errorf("buildLldpLocPortNumMap: %s", err)
+				}
+				if !ok {
 					continue
 				}
 			}
@@ -329,7 +328,7 @@ func buildLldpLocPortNumMap(ss smi.Store, ps pdu.Store,
 		}
 
 		// If we haven't built up the full mapping, keep trying.
-		if len(mp) == len(ifDescrMap) {
+		if len(mp) == len(ifDescrs) {
 			return nil
 		}
 	}
@@ -338,10 +337,10 @@ func buildLldpLocPortNumMap(ss smi.Store, ps pdu.Store,
 }
 
 func getInterfaceFromLldpPortNum(ss smi.Store, ps pdu.Store,
-	mapperData *sync.Map, port string) (string, error) {
+	mapperData *sync.Map, logger Logger, port string) (string, error) {
 	_, ok := mapperData.Load("lldpLocPortNum")
 	if !ok {
-		if err := buildLldpLocPortNumMap(ss, ps, mapperData); err != nil {
+		if err := buildLldpLocPortNumMap(ss, ps, mapperData, logger); err != nil {
 			return "", err
 		}
 	}
@@ -450,9 +449,14 @@ func lldpLocPortTableMapper(ss smi.Store, ps pdu.Store,
 	updates := []*gnmi.Update{}
 
 	for _, p := range pdus {
-		lldpPortNum := pdu.IndexValues(ss, p)[0]
+		lldpPortNum, err := firstIndex(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
 		// Get interface name corresponding to this port number.
-		intfName, err := getInterfaceFromLldpPortNum(ss, ps, mapperData, lldpPortNum)
+		intfName, err := getInterfaceFromLldpPortNum(ss, ps, mapperData,
+			logger, lldpPortNum)
 		if err != nil {
 			return nil, err
 		} else if intfName == "" {
@@ -560,7 +564,8 @@ func lldpRemTableMapper(ss smi.Store, ps pdu.Store,
 		}
 
 		// get the interface name corresponding to this lldpRemLocalPortNum
-		intfName, err := getInterfaceFromLldpPortNum(ss, ps, mapperData, lldpPortNum)
+		intfName, err := getInterfaceFromLldpPortNum(ss, ps, mapperData,
+			logger, lldpPortNum)
 		if err != nil {
 			return nil, err
 		} else if intfName == "" {
@@ -637,7 +642,11 @@ func entPhysicalMapper(ss smi.Store, ps pdu.Store,
 	}
 
 	for _, p := range pdus {
-		epi := pdu.IndexValues(ss, p)[0]
+		epi, err := firstIndex(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
 		var v interface{}
 		namePath := nameRegex.MatchString(path)
 		if oid == "entPhysicalDescr" && namePath {
@@ -703,40 +712,97 @@ var (
 		"ifOperStatus", func(x interface{}) *gnmi.TypedValue {
 			return strval(openconfig.IntfOperStatus(x.(int)))
 		})
-	interfaceInOctets64 = ifTableMapperFn(interfaceCounterPath+"in-octets",
-		"ifHCInOctets", uintval)
-	interfaceInOctets32 = ifTableMapperFn(interfaceCounterPath+"in-octets",
-		"ifInOctets", uintval)
-	interfaceInUnicastPkts64 = ifTableMapperFn(interfaceCounterPath+"in-unicast-pkts",
-		"ifHCInUcastPkts", uintval)
-	interfaceInUnicastPkts32 = ifTableMapperFn(interfaceCounterPath+"in-unicast-pkts",
-		"ifInUcastPkts", uintval)
-	interfaceInMulticastPkts = ifTableMapperFn(interfaceCounterPath+"in-multicast-pkts",
-		"ifHCInMulticastPkts", uintval)
-	interfaceInBroadcastPkts = ifTableMapperFn(interfaceCounterPath+"in-broadcast-pkts",
-		"ifHCInBroadcastPkts", uintval)
-	interfaceOutMulticastPkts = ifTableMapperFn(interfaceCounterPath+"out-multicast-pkts",
-		"ifHCOutMulticastPkts", uintval)
-	interfaceOutBroadcastPkts = ifTableMapperFn(interfaceCounterPath+"out-broadcast-pkts",
-		"ifHCOutBroadcastPkts", uintval)
-	interfaceInDiscards = ifTableMapperFn(interfaceCounterPath+"in-discards",
-		"ifInDiscards", uintval)
-	interfaceInErrors = ifTableMapperFn(interfaceCounterPath+"in-errors",
-		"ifInErrors", uintval)
-	interfaceInUnknownProtos = ifTableMapperFn(interfaceCounterPath+"in-unknown-protos",
-		"ifInUnknownProtos", uintval)
-	interfaceOutOctets64 = ifTableMapperFn(interfaceCounterPath+"out-octets",
-		"ifHCOutOctets", uintval)
-	interfaceOutOctets32 = ifTableMapperFn(interfaceCounterPath+"out-octets",
-		"ifOutOctets", uintval)
-	interfaceOutUnicastPkts64 = ifTableMapperFn(interfaceCounterPath+"out-unicast-pkts",
-		"ifHCOutUcastPkts", uintval)
-	interfaceOutUnicastPkts32 = ifTableMapperFn(interfaceCounterPath+"out-unicast-pkts",
-		"ifOutUcastPkts", uintval)
-	interfaceOutDiscards = ifTableMapperFn(interfaceCounterPath+"out-discards",
-		"ifOutDiscards", uintval)
-	interfaceOutErrors = ifTableMapperFn(interfaceCounterPath+"out-errors",
-		"ifOutErrors", uintval)
+	interfaceInOctets64 = ifCounterMapperFn(interfaceCounterPath+"in-octets",
+		"ifHCInOctets")
+	interfaceInOctets32 = ifCounterMapperFn(interfaceCounterPath+"in-octets",
+		"ifInOctets")
+	interfaceInUnicastPkts64 = ifCounterMapperFn(interfaceCounterPath+"in-unicast-pkts",
+		"ifHCInUcastPkts")
+	interfaceInUnicastPkts32 = ifCounterMapperFn(interfaceCounterPath+"in-unicast-pkts",
+		"ifInUcastPkts")
+	interfaceInMulticastPkts = ifCounterMapperFn(interfaceCounterPath+"in-multicast-pkts",
+		"ifHCInMulticastPkts")
+	interfaceInBroadcastPkts = ifCounterMapperFn(interfaceCounterPath+"in-broadcast-pkts",
+		"ifHCInBroadcastPkts")
+	interfaceOutMulticastPkts = ifCounterMapperFn(interfaceCounterPath+"out-multicast-pkts",
+		"ifHCOutMulticastPkts")
+	interfaceOutBroadcastPkts = ifCounterMapperFn(interfaceCounterPath+"out-broadcast-pkts",
+		"ifHCOutBroadcastPkts")
+	interfaceInDiscards = ifCounterMapperFn(interfaceCounterPath+"in-discards",
+		"ifInDiscards")
+	interfaceInErrors = ifCounterMapperFn(interfaceCounterPath+"in-errors",
+		"ifInErrors")
+	interfaceInUnknownProtos = ifCounterMapperFn(interfaceCounterPath+"in-unknown-protos",
+		"ifInUnknownProtos")
+	interfaceOutOctets64 = ifCounterMapperFn(interfaceCounterPath+"out-octets",
+		"ifHCOutOctets")
+	interfaceOutOctets32 = ifCounterMapperFn(interfaceCounterPath+"out-octets",
+		"ifOutOctets")
+	interfaceOutUnicastPkts64 = ifCounterMapperFn(interfaceCounterPath+"out-unicast-pkts",
+		"ifHCOutUcastPkts")
+	interfaceOutUnicastPkts32 = ifCounterMapperFn(interfaceCounterPath+"out-unicast-pkts",
+		"ifOutUcastPkts")
+	interfaceOutDiscards = ifCounterMapperFn(interfaceCounterPath+"out-discards",
+		"ifOutDiscards")
+	interfaceOutErrors = ifCounterMapperFn(interfaceCounterPath+"out-errors",
+		"ifOutErrors")
+
+	// /interfaces/interface/subinterfaces
+	subinterfacePath       = interfacePath + "subinterfaces/subinterface[index=0]/"
+	ipAddressPath          = subinterfacePath + "%s/addresses/address[ip=%s]/"
+	ipNeighborPath         = subinterfacePath + "%s/neighbors/neighbor[ip=%s]/"
+	subinterfaceIndex      = subinterfaceMapperFn(ipAddressesFromIPAddressTable, "index")
+	subinterfaceIndexV4    = subinterfaceMapperFn(ipAddressesFromIPAddrTable, "index")
+	subinterfaceStateIndex = subinterfaceMapperFn(ipAddressesFromIPAddressTable,
+		"state/index")
+	subinterfaceStateIndexV4 = subinterfaceMapperFn(ipAddressesFromIPAddrTable,
+		"state/index")
+	addressIP = func(a *ipAddress) *gnmi.TypedValue {
+		return strval(a.ip)
+	}
+	addressPrefixLength = func(a *ipAddress) *gnmi.TypedValue {
+		if a.prefixLen < 0 {
+			return nil
+		}
+		return uintval(a.prefixLen)
+	}
+	ipv4AddressIP = ipAddressMapperFn(ipAddressesFromIPAddressTable, "ipv4",
+		"ip", addressIP)
+	ipv4AddressIPV4 = ipAddressMapperFn(ipAddressesFromIPAddrTable, "ipv4",
+		"ip", addressIP)
+	ipv4AddressStateIP = ipAddressMapperFn(ipAddressesFromIPAddressTable, "ipv4",
+		"state/ip", addressIP)
+	ipv4AddressStateIPV4 = ipAddressMapperFn(ipAddressesFromIPAddrTable, "ipv4",
+		"state/ip", addressIP)
+	ipv4AddressPrefixLength = ipAddressMapperFn(ipAddressesFromIPAddressTable, "ipv4",
+		"state/prefix-length", addressPrefixLength)
+	ipv4AddressPrefixLengthV4 = ipAddressMapperFn(ipAddressesFromIPAddrTable, "ipv4",
+		"state/prefix-length", addressPrefixLength)
+	ipv6AddressIP = ipAddressMapperFn(ipAddressesFromIPAddressTable, "ipv6",
+		"ip", addressIP)
+	ipv6AddressStateIP = ipAddressMapperFn(ipAddressesFromIPAddressTable, "ipv6",
+		"state/ip", addressIP)
+	ipv6AddressPrefixLength = ipAddressMapperFn(ipAddressesFromIPAddressTable, "ipv6",
+		"state/prefix-length", addressPrefixLength)
+	neighborIP = func(n *ipNeighbor) *gnmi.TypedValue {
+		return strval(n.ip)
+	}
+	neighborLinkLayerAddress = func(n *ipNeighbor) *gnmi.TypedValue {
+		return strval(n.mac)
+	}
+	neighborOrigin = func(n *ipNeighbor) *gnmi.TypedValue {
+		return strval(n.origin)
+	}
+	ipv4NeighborIP      = ipNeighborMapperFn("ipv4", "ip", neighborIP)
+	ipv4NeighborStateIP = ipNeighborMapperFn("ipv4", "state/ip", neighborIP)
+	ipv4NeighborMac     = ipNeighborMapperFn("ipv4", "state/link-layer-address",
+		neighborLinkLayerAddress)
+	ipv4NeighborOrigin  = ipNeighborMapperFn("ipv4", "state/origin", neighborOrigin)
+	ipv6NeighborIP      = ipNeighborMapperFn("ipv6", "ip", neighborIP)
+	ipv6NeighborStateIP = ipNeighborMapperFn("ipv6", "state/ip", neighborIP)
+	ipv6NeighborMac     = ipNeighborMapperFn("ipv6", "state/link-layer-address",
+		neighborLinkLayerAddress)
+	ipv6NeighborOrigin = ipNeighborMapperFn("ipv6", "state/origin", neighborOrigin)
 
 	// /system/state
 	systemStatePath     = "/system/state/"
@@ -751,6 +817,26 @@ var (
 	systemStateBootTime32 = scalarMapperFn(systemStatePath+"boot-time",
 		"sysUpTimeInstance", processBootTime)
 
+	// /system/cpus
+	cpuPath     = "/system/cpus/cpu[index=%d]/"
+	cpuIndexVal = func(l *processorLoad) *gnmi.TypedValue {
+		return uintval(l.index)
+	}
+	cpuIndex      = cpuMapperFn("index", cpuIndexVal)
+	cpuStateIndex = cpuMapperFn("state/index", cpuIndexVal)
+	cpuTotal      = cpuMapperFn("state/total/instant", func(l *processorLoad) *gnmi.TypedValue {
+		return uintval(l.load)
+	})
+
+	// /system/memory
+	memoryStatePath = "/system/memory/state/"
+	memoryPhysical  = memoryMapperFn("physical", func(mu *memoryUsage) *gnmi.TypedValue {
+		return uintval(mu.physical)
+	})
+	memoryUsed = memoryMapperFn("used", func(mu *memoryUsage) *gnmi.TypedValue {
+		return uintval(mu.used)
+	})
+
 	// /lldp
 	lldpPath                        = "/lldp/"
 	lldpStatePath                   = lldpPath + "state/"
@@ -879,6 +965,72 @@ var (
 		"entPhysicalModelName", strval)
 	componentHardwareVersion = entPhysicalTableMapperFn(componentStatePath+"hardware-version",
 		"entPhysicalHardwareRev", strval)
+	componentTemperatureInstant = sensorMapperFn(sensorTemperaturePath("instant"),
+		sensorFloatval)
+	componentFanSpeed       = sensorMapperFn(sensorFanPath("speed"), sensorUintval)
+	powerSupplyInputVoltage = sensorMapperFn(sensorPowerSupplyPath("input-voltage", true,
+		sensorVoltsAC, sensorVoltsDC), sensorFloatval)
+	powerSupplyInputCurrent = sensorMapperFn(sensorPowerSupplyPath("input-current", true,
+		sensorAmperes), sensorFloatval)
+	powerSupplyOutputVoltage = sensorMapperFn(sensorPowerSupplyPath("output-voltage", false,
+		sensorVoltsAC, sensorVoltsDC), sensorFloatval)
+	powerSupplyOutputCurrent = sensorMapperFn(sensorPowerSupplyPath("output-current", false,
+		sensorAmperes), sensorFloatval)
+	powerSupplyOutputPower = sensorMapperFn(sensorPowerSupplyPath("output-power", false,
+		sensorWatts), sensorFloatval)
+
+	// /network-instances
+	defaultNetworkInstance   = "default"
+	networkInstancePath      = "/network-instances/network-instance[name=default]/"
+	aftPath                  = networkInstancePath + "afts/"
+	aftEntryPath             = aftPath + "%s-unicast/%s-entry[prefix=%s]/"
+	aftNextHopPath           = aftPath + "next-hops/next-hop[index=%d]/"
+	aftNextHopGroupPath      = aftPath + "next-hop-groups/next-hop-group[id=%d]/"
+	networkInstanceName      = networkInstanceMapperFn("name")
+	networkInstanceStateName = networkInstanceMapperFn("state/name")
+	aftEntryPrefix           = func(e *aftEntry) *gnmi.TypedValue {
+		return strval(e.prefix)
+	}
+	aftEntryProtocol = func(e *aftEntry) *gnmi.TypedValue {
+		return strval(e.protocol)
+	}
+	aftEntryNextHopGroup = func(e *aftEntry) *gnmi.TypedValue {
+		if e.group == 0 {
+			return nil
+		}
+		return uintval(e.group)
+	}
+	aftIPv4EntryPrefix      = aftEntryMapperFn("ipv4", "prefix", aftEntryPrefix)
+	aftIPv4EntryStatePrefix = aftEntryMapperFn("ipv4", "state/prefix", aftEntryPrefix)
+	aftIPv4EntryProtocol    = aftEntryMapperFn("ipv4", "state/origin-protocol",
+		aftEntryProtocol)
+	aftIPv4EntryNextHopGroup = aftEntryMapperFn("ipv4", "state/next-hop-group",
+		aftEntryNextHopGroup)
+	aftIPv6EntryPrefix      = aftEntryMapperFn("ipv6", "prefix", aftEntryPrefix)
+	aftIPv6EntryStatePrefix = aftEntryMapperFn("ipv6", "state/prefix", aftEntryPrefix)
+	aftIPv6EntryProtocol    = aftEntryMapperFn("ipv6", "state/origin-protocol",
+		aftEntryProtocol)
+	aftIPv6EntryNextHopGroup = aftEntryMapperFn("ipv6", "state/next-hop-group",
+		aftEntryNextHopGroup)
+	aftNextHopIndex = aftNextHopMapperFn("index", func(nh *aftNextHop) *gnmi.TypedValue {
+		return uintval(nh.index)
+	})
+	aftNextHopStateIndex = aftNextHopMapperFn("state/index",
+		func(nh *aftNextHop) *gnmi.TypedValue {
+			return uintval(nh.index)
+		})
+	aftNextHopIPAddress = aftNextHopMapperFn("state/ip-address",
+		func(nh *aftNextHop) *gnmi.TypedValue {
+			return strval(nh.ip)
+		})
+	aftNextHopInterface = aftNextHopMapperFn("interface-ref/state/interface",
+		func(nh *aftNextHop) *gnmi.TypedValue {
+			return strval(nh.intfName)
+		})
+	aftNextHopGroupID                = aftNextHopGroupMapperFn("id")
+	aftNextHopGroupStateID           = aftNextHopGroupMapperFn("state/id")
+	aftNextHopGroupNextHopIndex      = aftNextHopGroupNextHopMapperFn("index")
+	aftNextHopGroupNextHopStateIndex = aftNextHopGroupNextHopMapperFn("state/index")
 )
 
 var defaultMappings = map[string][]Mapper{
@@ -915,6 +1067,54 @@ var defaultMappings = map[string][]Mapper{
 	"/system/state/domain-name": []Mapper{systemStateDomainName},
 	"/system/state/boot-time":   []Mapper{systemStateBootTime64, systemStateBootTime32},
 
+	//// cpus
+	"/system/cpus/cpu[index=index]/index":               []Mapper{cpuIndex},
+	"/system/cpus/cpu[index=index]/state/index":         []Mapper{cpuStateIndex},
+	"/system/cpus/cpu[index=index]/state/total/instant": []Mapper{cpuTotal},
+
+	//// memory
+	"/system/memory/state/physical": []Mapper{memoryPhysical},
+	"/system/memory/state/used":     []Mapper{memoryUsed},
+
+	// subinterfaces
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"index": []Mapper{subinterfaceIndex, subinterfaceIndexV4},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"state/index": []Mapper{subinterfaceStateIndex, subinterfaceStateIndexV4},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv4/addresses/address[ip=ip]/ip": []Mapper{ipv4AddressIP, ipv4AddressIPV4},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv4/addresses/address[ip=ip]/state/ip": []Mapper{ipv4AddressStateIP,
+		ipv4AddressStateIPV4},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv4/addresses/address[ip=ip]/state/prefix-length": []Mapper{
+		ipv4AddressPrefixLength, ipv4AddressPrefixLengthV4},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv6/addresses/address[ip=ip]/ip": []Mapper{ipv6AddressIP},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv6/addresses/address[ip=ip]/state/ip": []Mapper{ipv6AddressStateIP},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv6/addresses/address[ip=ip]/state/prefix-length": []Mapper{
+		ipv6AddressPrefixLength},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv4/neighbors/neighbor[ip=ip]/ip": []Mapper{ipv4NeighborIP},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv4/neighbors/neighbor[ip=ip]/state/ip": []Mapper{ipv4NeighborStateIP},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv4/neighbors/neighbor[ip=ip]/state/link-layer-address": []Mapper{
+		ipv4NeighborMac},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv4/neighbors/neighbor[ip=ip]/state/origin": []Mapper{ipv4NeighborOrigin},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv6/neighbors/neighbor[ip=ip]/ip": []Mapper{ipv6NeighborIP},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv6/neighbors/neighbor[ip=ip]/state/ip": []Mapper{ipv6NeighborStateIP},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv6/neighbors/neighbor[ip=ip]/state/link-layer-address": []Mapper{
+		ipv6NeighborMac},
+	"/interfaces/interface[name=name]/subinterfaces/subinterface[index=index]/" +
+		"ipv6/neighbors/neighbor[ip=ip]/state/origin": []Mapper{ipv6NeighborOrigin},
+
 	//// platform
 	"/components/component[name=name]/name":                   []Mapper{componentName},
 	"/components/component[name=name]/state/name":             []Mapper{componentStateName},
@@ -927,6 +1127,58 @@ var defaultMappings = map[string][]Mapper{
 	"/components/component[name=name]/state/software-version": []Mapper{componentSoftwareVersion},
 	"/components/component[name=name]/state/hardware-version": []Mapper{componentModelName,
 		componentHardwareVersion},
+	"/components/component[name=name]/state/temperature/instant": []Mapper{
+		componentTemperatureInstant},
+	"/components/component[name=name]/fan/state/speed": []Mapper{componentFanSpeed},
+	"/components/component[name=name]/power-supply/state/input-voltage": []Mapper{
+		powerSupplyInputVoltage},
+	"/components/component[name=name]/power-supply/state/input-current": []Mapper{
+		powerSupplyInputCurrent},
+	"/components/component[name=name]/power-supply/state/output-voltage": []Mapper{
+		powerSupplyOutputVoltage},
+	"/components/component[name=name]/power-supply/state/output-current": []Mapper{
+		powerSupplyOutputCurrent},
+	"/components/component[name=name]/power-supply/state/output-power": []Mapper{
+		powerSupplyOutputPower},
+
+	//// network-instances
+	"/network-instances/network-instance[name=name]/name": []Mapper{networkInstanceName},
+	"/network-instances/network-instance[name=name]/state/name": []Mapper{
+		networkInstanceStateName},
+	"/network-instances/network-instance[name=name]/afts/ipv4-unicast/" +
+		"ipv4-entry[prefix=prefix]/prefix": []Mapper{aftIPv4EntryPrefix},
+	"/network-instances/network-instance[name=name]/afts/ipv4-unicast/" +
+		"ipv4-entry[prefix=prefix]/state/prefix": []Mapper{aftIPv4EntryStatePrefix},
+	"/network-instances/network-instance[name=name]/afts/ipv4-unicast/" +
+		"ipv4-entry[prefix=prefix]/state/origin-protocol": []Mapper{aftIPv4EntryProtocol},
+	"/network-instances/network-instance[name=name]/afts/ipv4-unicast/" +
+		"ipv4-entry[prefix=prefix]/state/next-hop-group": []Mapper{aftIPv4EntryNextHopGroup},
+	"/network-instances/network-instance[name=name]/afts/ipv6-unicast/" +
+		"ipv6-entry[prefix=prefix]/prefix": []Mapper{aftIPv6EntryPrefix},
+	"/network-instances/network-instance[name=name]/afts/ipv6-unicast/" +
+		"ipv6-entry[prefix=prefix]/state/prefix": []Mapper{aftIPv6EntryStatePrefix},
+	"/network-instances/network-instance[name=name]/afts/ipv6-unicast/" +
+		"ipv6-entry[prefix=prefix]/state/origin-protocol": []Mapper{aftIPv6EntryProtocol},
+	"/network-instances/network-instance[name=name]/afts/ipv6-unicast/" +
+		"ipv6-entry[prefix=prefix]/state/next-hop-group": []Mapper{aftIPv6EntryNextHopGroup},
+	"/network-instances/network-instance[name=name]/afts/next-hops/" +
+		"next-hop[index=index]/index": []Mapper{aftNextHopIndex},
+	"/network-instances/network-instance[name=name]/afts/next-hops/" +
+		"next-hop[index=index]/state/index": []Mapper{aftNextHopStateIndex},
+	"/network-instances/network-instance[name=name]/afts/next-hops/" +
+		"next-hop[index=index]/state/ip-address": []Mapper{aftNextHopIPAddress},
+	"/network-instances/network-instance[name=name]/afts/next-hops/" +
+		"next-hop[index=index]/interface-ref/state/interface": []Mapper{aftNextHopInterface},
+	"/network-instances/network-instance[name=name]/afts/next-hop-groups/" +
+		"next-hop-group[id=id]/id": []Mapper{aftNextHopGroupID},
+	"/network-instances/network-instance[name=name]/afts/next-hop-groups/" +
+		"next-hop-group[id=id]/state/id": []Mapper{aftNextHopGroupStateID},
+	"/network-instances/network-instance[name=name]/afts/next-hop-groups/" +
+		"next-hop-group[id=id]/next-hops/next-hop[index=index]/index": []Mapper{
+		aftNextHopGroupNextHopIndex},
+	"/network-instances/network-instance[name=name]/afts/next-hop-groups/" +
+		"next-hop-group[id=id]/next-hops/next-hop[index=index]/state/index": []Mapper{
+		aftNextHopGroupNextHopStateIndex},
 
 	//// lldp
 	"/lldp/state/chassis-id":         []Mapper{lldpChassisID, lldpV2ChassisID},
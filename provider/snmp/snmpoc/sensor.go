@@ -0,0 +1,207 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmpoc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/aristanetworks/cloudvision-go/provider"
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/pdu"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// EntitySensorDataType values
+const (
+	sensorVoltsAC = 3
+	sensorVoltsDC = 4
+	sensorAmperes = 5
+	sensorWatts   = 6
+	sensorCelsius = 8
+	sensorRPM     = 10
+)
+
+// entPhysicalClass values
+const (
+	entClassPowerSupply = 6
+	entClassFan         = 7
+)
+
+// entitySensor is an entPhySensorTable reading along with what we
+// know of the sensor's place in the entPhysicalTable.
+type entitySensor struct {
+	index       string // entPhysicalIndex of the sensor
+	parent      string // entPhysicalIndex of the containing entity
+	parentClass int
+	sensorType  int
+	value       float64 // scaled to units
+	input       bool    // whether the sensor describes itself as measuring input
+}
+
+// sensorValue scales an entPhySensorValue according to its
+// entPhySensorScale, an SI prefix where units(9) means no scaling,
+// and its entPhySensorPrecision, the number of decimal places in the
+// value if positive.
+func sensorValue(value, scale, precision int) float64 {
+	exp := 3 * (scale - 9)
+	if precision > 0 {
+		exp -= precision
+	}
+	if exp < 0 {
+		return float64(value) / math.Pow10(-exp)
+	}
+	return float64(value) * math.Pow10(exp)
+}
+
+func entitySensors(ss smi.Store, ps pdu.Store,
+	mapperData *sync.Map, logger Logger) ([]*entitySensor, error) {
+	if v, ok := mapperData.Load("entPhySensorTable"); ok {
+		return v.([]*entitySensor), nil
+	}
+
+	cols := make(map[string]map[string]int)
+	for _, oid := range []string{"entPhySensorType", "entPhySensorScale",
+		"entPhySensorPrecision", "entPhySensorOperStatus",
+		"entPhysicalContainedIn", "entPhysicalClass"} {
+		m, err := tabularInts(ss, ps, logger, oid)
+		if err != nil {
+			return nil, err
+		}
+		cols[oid] = m
+	}
+	descrs := make(map[string]string)
+	descrPDUs, err := getTabular(ps, "entPhysicalDescr")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range descrPDUs {
+		epi, err := firstIndex(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		descrs[epi] = sanitizedString(p.Value)
+	}
+
+	sensors := []*entitySensor{}
+	pdus, err := sortedPDUs(ps, "entPhySensorValue")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pdus {
+		epi, err := firstIndex(ss, p)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", p.Name, err)
+			continue
+		}
+		if cols["entPhySensorOperStatus"][epi] != 1 {
+			continue
+		}
+		v, err := provider.ToInt(p.Value)
+		if err != nil {
+			logger.Debugf("Skipping sensor %s: %v", epi, err)
+			continue
+		}
+		scale, ok := cols["entPhySensorScale"][epi]
+		if !ok {
+			scale = 9
+		}
+		parent := fmt.Sprintf("%d", cols["entPhysicalContainedIn"][epi])
+		sensors = append(sensors, &entitySensor{
+			index:       epi,
+			parent:      parent,
+			parentClass: cols["entPhysicalClass"][parent],
+			sensorType:  cols["entPhySensorType"][epi],
+			value:       sensorValue(v, scale, cols["entPhySensorPrecision"][epi]),
+			input:       strings.Contains(strings.ToLower(descrs[epi]), "input"),
+		})
+	}
+
+	mapperData.Store("entPhySensorTable", sensors)
+	return sensors, nil
+}
+
+// sensorComponentPath returns the path of a sensor's leaf under its
+// component, or the empty string if the sensor doesn't map to the
+// leaf.
+type sensorComponentPath func(*entitySensor) string
+
+// sensorTemperaturePath places a temperature reading under the
+// sensor's own component.
+func sensorTemperaturePath(leaf string) sensorComponentPath {
+	return func(s *entitySensor) string {
+		if s.sensorType != sensorCelsius {
+			return ""
+		}
+		return fmt.Sprintf(componentStatePath, s.index) + "temperature/" + leaf
+	}
+}
+
+// sensorFanPath places a fan speed reading under the fan containing
+// the sensor, if any, or else under the sensor itself.
+func sensorFanPath(leaf string) sensorComponentPath {
+	return func(s *entitySensor) string {
+		if s.sensorType != sensorRPM {
+			return ""
+		}
+		name := s.index
+		if s.parentClass == entClassFan {
+			name = s.parent
+		}
+		return fmt.Sprintf(componentPath, name) + "fan/state/" + leaf
+	}
+}
+
+// sensorPowerSupplyPath places a reading of one of the specified
+// types from a sensor inside a power supply under the power supply.
+func sensorPowerSupplyPath(leaf string, input bool,
+	sensorTypes ...int) sensorComponentPath {
+	return func(s *entitySensor) string {
+		if s.parentClass != entClassPowerSupply || s.input != input {
+			return ""
+		}
+		for _, t := range sensorTypes {
+			if s.sensorType == t {
+				return fmt.Sprintf(componentPath, s.parent) + "power-supply/state/" + leaf
+			}
+		}
+		return ""
+	}
+}
+
+func sensorMapperFn(path sensorComponentPath,
+	vp func(*entitySensor) *gnmi.TypedValue) Mapper {
+	return func(ss smi.Store, ps pdu.Store,
+		mapperData *sync.Map, logger Logger) ([]*gnmi.Update, error) {
+		sensors, err := entitySensors(ss, ps, mapperData, logger)
+		if err != nil {
+			return nil, err
+		}
+		updates := []*gnmi.Update{}
+		for _, s := range sensors {
+			p := path(s)
+			if p == "" {
+				continue
+			}
+			updates = append(updates, update(pgnmi.PathFromString(p), vp(s)))
+		}
+		return updates, nil
+	}
+}
+
+func sensorFloatval(s *entitySensor) *gnmi.TypedValue {
+	return pgnmi.Floatval(s.value)
+}
+
+func sensorUintval(s *entitySensor) *gnmi.TypedValue {
+	if s.value < 0 {
+		return pgnmi.Uintval(0)
+	}
+	return pgnmi.Uintval(uint64(math.Round(s.value)))
+}
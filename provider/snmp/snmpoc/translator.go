@@ -10,38 +10,67 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
 	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/pdu"
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
 	"github.com/openconfig/gnmi/proto/gnmi"
-	"github.com/soniah/gosnmp"
 )
 
-// NewTranslator returns a Translator.
+// NewTranslator returns a Translator that polls using the specified
+// gosnmp session. If gs is nil, the caller must set the Translator's
+// Getter and Walker.
 func NewTranslator(mibStore smi.Store, gs *gosnmp.GoSNMP) (*Translator, error) {
-	ps, err := pdu.NewStore(mibStore)
-	if err != nil {
-		return nil, err
+	models := make(map[string]*model)
+	for name, m := range supportedModels {
+		models[name] = m.Copy()
+	}
+	mappingGroups := make(map[string]*mappingGroup)
+	for name, mg := range supportedMappingGroups {
+		mappingGroups[name] = &mappingGroup{
+			name:   mg.name,
+			models: make(map[string]*model),
+		}
+		for modelName := range mg.models {
+			mappingGroups[name].models[modelName] = models[modelName]
+		}
 	}
 
-	return &Translator{
-		Getter:                 gs.Get,
+	t := &Translator{
 		gosnmp:                 gs,
 		gosnmpLock:             &sync.Mutex{},
 		Logger:                 &nonlogger{},
-		mapperData:             &sync.Map{},
+		mappingGroups:          mappingGroups,
 		Mappings:               DefaultMappings(),
 		mibStore:               mibStore,
+		models:                 models,
 		pathsMappingGroups:     make(map[string]map[string]*mappingGroup),
-		pduStore:               ps,
-		pollLock:               &sync.Mutex{},
+		groupPolls:             make(map[string]*groupPoll),
+		lastUpdates:            make(map[string][]*gnmi.Update),
+		counters:               newCounterStore(),
+		lock:                   &sync.RWMutex{},
+		cacheLock:              &sync.Mutex{},
 		successfulMappings:     make(map[string]Mapper),
 		successfulMappingsLock: &sync.RWMutex{},
-		Walker:                 gs.BulkWalk,
-	}, nil
+	}
+	if gs != nil {
+		// A gosnmp session can't handle parallel requests.
+		t.Getter = func(oids []string) (*gosnmp.SnmpPacket, error) {
+			t.gosnmpLock.Lock()
+			defer t.gosnmpLock.Unlock()
+			return gs.Get(oids)
+		}
+		t.Walker = func(rootOid string, walkFn gosnmp.WalkFunc) error {
+			t.gosnmpLock.Lock()
+			defer t.gosnmpLock.Unlock()
+			return gs.BulkWalk(rootOid, walkFn)
+		}
+	}
+	return t, nil
 }
 
 // Logger defines an interface for logging.
@@ -55,10 +84,13 @@ type Logger interface {
 }
 
 // model describes a set of paths rooted at rootPath for which
-// we want to produce updates.
+// we want to produce updates. If paths is set, the model claims only
+// the paths beneath rootPath that it matches, and it shares rootPath
+// with the model owning the rest of them.
 type model struct {
 	name         string
 	rootPath     string
+	paths        *regexp.Regexp
 	dependencies []string
 	snmpGetOIDs  []string
 	snmpWalkOIDs []string
@@ -68,6 +100,7 @@ func (m *model) Copy() *model {
 	m2 := &model{
 		name:         m.name,
 		rootPath:     m.rootPath,
+		paths:        m.paths,
 		dependencies: make([]string, len(m.dependencies)),
 		snmpGetOIDs:  make([]string, len(m.snmpGetOIDs)),
 		snmpWalkOIDs: make([]string, len(m.snmpWalkOIDs)),
@@ -80,10 +113,24 @@ func (m *model) Copy() *model {
 }
 
 // A mappingGroup contains a set of paths and their associated models.
+// dependencies holds models outside the group whose SNMP data the
+// group's models need but whose paths weren't requested. restores
+// names the models of other groups whose data the group's deletions
+// wipe out, and which it sends again from their last poll.
 type mappingGroup struct {
-	name        string
-	models      map[string]*model
-	updatePaths map[string][]string
+	name         string
+	models       map[string]*model
+	dependencies map[string]*model
+	updatePaths  map[string][]string
+	restores     []string
+}
+
+// A groupPoll holds the data of a mapping group's poll. A group's
+// polls don't overlap, but different groups may be polled at once.
+type groupPoll struct {
+	lock       sync.Mutex
+	pduStore   pdu.Store
+	mapperData *sync.Map
 }
 
 type nonlogger struct{}
@@ -98,29 +145,54 @@ func (n *nonlogger) Debugf(format string, args ...interface{}) {}
 // Translator defines an interface for producing translations from a
 // set of received SNMP PDUs to a set of gNMI updates.
 type Translator struct {
-	// auxiliary data stores
-	pduStore           pdu.Store
+	// auxiliary data stores, the mapping groups and polls of each
+	// set of paths polled, and the last updates of each model that
+	// another group may have to restore, which are guarded by
+	// cacheLock
 	mibStore           smi.Store
-	mapperData         *sync.Map
 	pathsMappingGroups map[string]map[string]*mappingGroup
+	groupPolls         map[string]*groupPoll
+	lastUpdates        map[string][]*gnmi.Update
+	cacheLock          *sync.Mutex
+
+	// supported models and mapping groups, which may be extended
+	// with AddMappings
+	models        map[string]*model
+	mappingGroups map[string]*mappingGroup
+
+	// counter samples, kept across polls
+	counters *counterStore
 
 	// mapping state
 	Mappings               map[string][]Mapper
 	successfulMappings     map[string]Mapper
 	successfulMappingsLock *sync.RWMutex
 
-	// gosnmp state
+	// gosnmp state, if the Translator has its own session
 	gosnmp          *gosnmp.GoSNMP
 	gosnmpLock      *sync.Mutex
 	gosnmpConnected bool
 
-	// to ensure non-overlapping polls
-	pollLock *sync.Mutex
+	// to keep the models and mappings from changing during polls
+	lock *sync.RWMutex
 
 	// logging
 	Logger Logger
 
-	// alternative get, walk, and time.Now for testing
+	// ExtendCounters causes 32-bit counters to be sent as 64-bit
+	// values that keep increasing when the device's counters wrap.
+	// CounterRates causes a per-second rate to be sent alongside
+	// each counter, at the counter's path in the "counter-rates"
+	// origin. Rates aren't OpenConfig, so they're sent through
+	// RateClient rather than the client passed to Poll, and dropped
+	// if RateClient is nil.
+	ExtendCounters bool
+	CounterRates   bool
+	RateClient     gnmi.GNMIClient
+
+	// Mock disables connecting to the device. Getter and Walker
+	// perform SNMP gets and walks; they may be called concurrently
+	// and must serialize requests themselves if need be.
 	Mock   bool
 	Getter func([]string) (*gosnmp.SnmpPacket, error)
 	Walker func(string, gosnmp.WalkFunc) error
@@ -132,10 +204,12 @@ type Translator struct {
 // is added to the set of updates to produce. It performs a poll for
 // any required SNMP data and translates that data into gNMI updates,
 // which it then transmits via the provided gNMI client's Set method.
+// Polls of different mapping groups may run concurrently; polls of
+// the same group wait for each other.
 func (t *Translator) Poll(ctx context.Context, client gnmi.GNMIClient,
 	paths []string) error {
-	t.pollLock.Lock()
-	defer t.pollLock.Unlock()
+	t.lock.RLock()
+	defer t.lock.RUnlock()
 
 	mappingGroups, err := t.mappingGroupsFromPaths(paths)
 	if err != nil {
@@ -145,25 +219,21 @@ func (t *Translator) Poll(ctx context.Context, client gnmi.GNMIClient,
 	if len(mappingGroups) == 0 {
 		return errors.New("no models to translate")
 	}
+	t.counters.setOptions(t.ExtendCounters, t.CounterRates)
 
-	// Clear data stores in preparation for filling them up again.
-	if err := t.pduStore.Clear(); err != nil {
-		return err
-	}
-	t.mapperData.Range(func(k interface{}, v interface{}) bool {
-		// Ideally we could just do t.mapperData = &sync.Map{} but
-		// the compiler says this is unsafe, so we have to use Range.
-		t.mapperData.Delete(k)
-		return true
-	})
-
-	// Produce updates for each mapping group.
+	// Produce updates for each mapping group. The channels have room
+	// for every group's result so that none is left blocked if we
+	// return early.
 	var wg sync.WaitGroup
 	setReqCh := make(chan *gnmi.SetRequest, len(mappingGroups))
-	errc := make(chan error)
+	errc := make(chan error, len(mappingGroups))
 	for _, mg := range mappingGroups {
+		gp, err := t.groupPoll(mg.name)
+		if err != nil {
+			return err
+		}
 		wg.Add(1)
-		go t.mappingGroupUpdates(ctx, mg, &wg, setReqCh, errc)
+		go t.mappingGroupUpdates(ctx, mg, gp, &wg, setReqCh, errc)
 	}
 
 	done := make(chan bool)
@@ -177,19 +247,62 @@ func (t *Translator) Poll(ctx context.Context, client gnmi.GNMIClient,
 		case <-ctx.Done():
 			return ctx.Err()
 		case sr := <-setReqCh:
-			if _, err := client.Set(ctx, sr); err != nil {
+			if err := t.set(ctx, client, sr); err != nil {
 				return err
 			}
 		case <-done:
-			return nil
+			// Send any SetRequests still buffered when the last
+			// mapping group finished.
+			for {
+				select {
+				case sr := <-setReqCh:
+					if err := t.set(ctx, client, sr); err != nil {
+						return err
+					}
+				default:
+					return nil
+				}
+			}
 		case err := <-errc:
 			return err
 		}
 	}
 }
 
-// updates produces updates for the provided set of paths.
-func (t *Translator) updates(paths []string) ([]*gnmi.Update, error) {
+// set sends a SetRequest through the client, except for any counter
+// rates in it, which go through RateClient.
+func (t *Translator) set(ctx context.Context, client gnmi.GNMIClient,
+	sr *gnmi.SetRequest) error {
+	sr, rates := splitCounterRates(sr)
+	if _, err := client.Set(ctx, sr); err != nil {
+		return err
+	}
+	if rates == nil || t.RateClient == nil {
+		return nil
+	}
+	_, err := t.RateClient.Set(ctx, rates)
+	return err
+}
+
+// groupPoll returns the poll data of the named mapping group.
+func (t *Translator) groupPoll(name string) (*groupPoll, error) {
+	t.cacheLock.Lock()
+	defer t.cacheLock.Unlock()
+	if gp, ok := t.groupPolls[name]; ok {
+		return gp, nil
+	}
+	ps, err := pdu.NewStore(t.mibStore)
+	if err != nil {
+		return nil, err
+	}
+	gp := &groupPoll{pduStore: ps}
+	t.groupPolls[name] = gp
+	return gp, nil
+}
+
+// updates produces updates for the provided set of paths from the
+// data of a mapping group's poll.
+func (t *Translator) updates(paths []string, gp *groupPoll) ([]*gnmi.Update, error) {
 	updates := []*gnmi.Update{}
 	for _, path := range paths {
 		// If we have a mapping that already worked, use it.
@@ -197,7 +310,7 @@ func (t *Translator) updates(paths []string) ([]*gnmi.Update, error) {
 		mapping, ok := t.successfulMappings[path]
 		t.successfulMappingsLock.RUnlock()
 		if ok {
-			u, err := mapping(t.mibStore, t.pduStore, t.mapperData, t.Logger)
+			u, err := mapping(t.mibStore, gp.pduStore, gp.mapperData, t.Logger)
 			if err != nil {
 				return nil, err
 			}
@@ -211,7 +324,7 @@ func (t *Translator) updates(paths []string) ([]*gnmi.Update, error) {
 			return nil, fmt.Errorf("No mapping supplied for path %v", path)
 		}
 		for _, mapping := range mappings {
-			u, err := mapping(t.mibStore, t.pduStore, t.mapperData, t.Logger)
+			u, err := mapping(t.mibStore, gp.pduStore, gp.mapperData, t.Logger)
 			if err != nil {
 				return nil, err
 			} else if len(u) == 0 {
@@ -231,14 +344,41 @@ var supportedModels = map[string]*model{
 	"interfaces": &model{
 		name:         "interfaces",
 		rootPath:     "/interfaces",
+		snmpGetOIDs:  []string{"sysUpTimeInstance"},
 		snmpWalkOIDs: []string{"ifTable", "ifXTable"},
 	},
+	"ip-addresses": &model{
+		name:     "ip-addresses",
+		rootPath: "/interfaces",
+		paths: regexp.MustCompile(`^/interfaces/interface\[name=name\]/subinterfaces/` +
+			`subinterface\[index=index\]/(index|state/index|ipv[46]/addresses/)`),
+		dependencies: []string{"interfaces"},
+		snmpWalkOIDs: []string{"ipAddressTable", "ipAddrTable"},
+	},
+	"neighbors": &model{
+		name:     "neighbors",
+		rootPath: "/interfaces",
+		paths: regexp.MustCompile(`^/interfaces/interface\[name=name\]/subinterfaces/` +
+			`subinterface\[index=index\]/ipv[46]/neighbors/`),
+		dependencies: []string{"interfaces"},
+		snmpWalkOIDs: []string{"ipNetToPhysicalTable"},
+	},
 	"system": &model{
 		name:     "system",
 		rootPath: "/system",
 		snmpGetOIDs: []string{"sysName.0", "lldpLocSysName.0", "hrSystemUptime.0",
 			"sysUpTimeInstance"},
 	},
+	"cpus": &model{
+		name:         "cpus",
+		rootPath:     "/system/cpus",
+		snmpWalkOIDs: []string{"hrProcessorLoad"},
+	},
+	"memory": &model{
+		name:         "memory",
+		rootPath:     "/system/memory",
+		snmpWalkOIDs: []string{"hrStorageTable"},
+	},
 	"lldp": &model{
 		name:         "lldp",
 		rootPath:     "/lldp",
@@ -251,6 +391,30 @@ var supportedModels = map[string]*model{
 		rootPath:     "/components",
 		snmpWalkOIDs: []string{"entPhysicalEntry"},
 	},
+	"sensors": &model{
+		name:     "sensors",
+		rootPath: "/components",
+		paths: regexp.MustCompile(`^/components/component\[name=name\]/` +
+			`(state/temperature|fan|power-supply)/`),
+		snmpWalkOIDs: []string{"entPhySensorTable", "entPhysicalDescr",
+			"entPhysicalContainedIn", "entPhysicalClass"},
+	},
+	"network-instances": &model{
+		name:         "network-instances",
+		rootPath:     "/network-instances",
+		dependencies: []string{"interfaces"},
+		snmpWalkOIDs: []string{"inetCidrRouteTable"},
+	},
+}
+
+// Models returns the names of the supported models, in sorted order.
+func Models() []string {
+	names := make([]string, 0, len(supportedModels))
+	for name := range supportedModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 var supportedMappingGroups = map[string]*mappingGroup{
@@ -261,10 +425,30 @@ var supportedMappingGroups = map[string]*mappingGroup{
 			"lldp":       supportedModels["lldp"],
 		},
 	},
+	"ip-addresses": &mappingGroup{
+		name: "ip-addresses",
+		models: map[string]*model{
+			"ip-addresses": supportedModels["ip-addresses"],
+		},
+	},
+	"neighbors": &mappingGroup{
+		name: "neighbors",
+		models: map[string]*model{
+			"neighbors": supportedModels["neighbors"],
+		},
+	},
+	"network-instances": &mappingGroup{
+		name: "network-instances",
+		models: map[string]*model{
+			"network-instances": supportedModels["network-instances"],
+		},
+	},
 	"system": &mappingGroup{
 		name: "system",
 		models: map[string]*model{
 			"system": supportedModels["system"],
+			"cpus":   supportedModels["cpus"],
+			"memory": supportedModels["memory"],
 		},
 	},
 	"platform": &mappingGroup{
@@ -273,95 +457,141 @@ var supportedMappingGroups = map[string]*mappingGroup{
 			"platform": supportedModels["platform"],
 		},
 	},
+	"sensors": &mappingGroup{
+		name: "sensors",
+		models: map[string]*model{
+			"sensors": supportedModels["sensors"],
+		},
+	},
 }
 
-func (t *Translator) storePDU(pdu gosnmp.SnmpPDU) error {
-	return t.pduStore.Add(&pdu)
-}
-
-func (t *Translator) getSNMPData(mg *mappingGroup) error {
-	t.gosnmpLock.Lock()
-	defer t.gosnmpLock.Unlock()
-
+func (t *Translator) getSNMPData(mg *mappingGroup, ps pdu.Store) error {
 	// Connect to target.
-	if !t.gosnmpConnected && !t.Mock {
+	t.gosnmpLock.Lock()
+	if !t.gosnmpConnected && !t.Mock && t.gosnmp != nil {
 		if err := t.gosnmp.Connect(); err != nil {
+			t.gosnmpLock.Unlock()
 			return err
 		}
 		t.gosnmpConnected = true
 		t.Logger.Infoln("gosnmp.Connect complete")
 	}
+	t.gosnmpLock.Unlock()
 
-	// Get SNMP data for each model in this mappingGroup.
+	// Get SNMP data for each model in this mappingGroup, and for the
+	// models they depend on. The requests are independent, so issue
+	// them all at once and let the Getter and Walker decide how many
+	// to have in flight.
+	models := []*model{}
 	for _, model := range mg.models {
-		// Walk
+		models = append(models, model)
+	}
+	for _, model := range mg.dependencies {
+		models = append(models, model)
+	}
+	var wg sync.WaitGroup
+	for _, model := range models {
 		for _, oid := range model.snmpWalkOIDs {
-			t.Logger.Debugf("SNMP Walk (OID = %s)", oid)
-			if err := t.Walker(oid, t.storePDU); err != nil {
-				t.Logger.Infof("Error walking OID %s: %s", oid, err)
-			} else {
-				t.Logger.Debugf("SNMP Walk complete (OID = %s)", oid)
-			}
+			wg.Add(1)
+			go func(oid string) {
+				defer wg.Done()
+				t.walk(oid, ps)
+			}(oid)
 		}
-
-		// Get
-		if len(model.snmpGetOIDs) == 0 {
-			continue
-		}
-		t.Logger.Debugf("SNMP Get (OIDs = %s)",
-			strings.Join(model.snmpGetOIDs, " "))
-		pkt, err := t.Getter(model.snmpGetOIDs)
-		if err != nil {
-			t.Logger.Infof("Error getting OIDs %s: %s",
-				strings.Join(model.snmpGetOIDs, " "), err)
-			return nil
-		} else if pkt == nil {
-			t.Logger.Info("SNMP Get returned nil packet")
-			return nil
-		} else {
-			t.Logger.Debugf("SNMP Get complete. pkt = %v, err = %v", pkt, err)
+		if len(model.snmpGetOIDs) > 0 {
+			wg.Add(1)
+			go func(oids []string) {
+				defer wg.Done()
+				t.get(oids, ps)
+			}(model.snmpGetOIDs)
 		}
+	}
+	wg.Wait()
 
-		if pkt.Error != gosnmp.NoError {
-			errstr, ok := SNMPErrCodes[pkt.Error]
-			if !ok {
-				errstr = "Unknown error"
-			}
-			t.Logger.Infof("SNMP Get: Error in packet (%v): %s",
-				pkt, errstr)
-		}
+	return nil
+}
 
-		for _, pdu := range pkt.Variables {
-			if err = t.storePDU(pdu); err != nil {
-				t.Logger.Infof("Error storing PDU: %s", err)
-			}
+func (t *Translator) walk(oid string, ps pdu.Store) {
+	t.Logger.Debugf("SNMP Walk (OID = %s)", oid)
+	if err := t.Walker(oid, func(pdu gosnmp.SnmpPDU) error {
+		return ps.Add(&pdu)
+	}); err != nil {
+		t.Logger.Infof("Error walking OID %s: %s", oid, err)
+	} else {
+		t.Logger.Debugf("SNMP Walk complete (OID = %s)", oid)
+	}
+}
+
+func (t *Translator) get(oids []string, ps pdu.Store) {
+	t.Logger.Debugf("SNMP Get (OIDs = %s)", strings.Join(oids, " "))
+	pkt, err := t.Getter(oids)
+	if err != nil {
+		t.Logger.Infof("Error getting OIDs %s: %s", strings.Join(oids, " "), err)
+		return
+	} else if pkt == nil {
+		t.Logger.Info("SNMP Get returned nil packet")
+		return
+	}
+	t.Logger.Debugf("SNMP Get complete. pkt = %v, err = %v", pkt, err)
+
+	if pkt.Error != gosnmp.NoError {
+		errstr, ok := SNMPErrCodes[pkt.Error]
+		if !ok {
+			errstr = "Unknown error"
 		}
+		t.Logger.Infof("SNMP Get: Error in packet (%v): %s", pkt, errstr)
 	}
 
-	return nil
+	for i := range pkt.Variables {
+		if err = ps.Add(&pkt.Variables[i]); err != nil {
+			t.Logger.Infof("Error storing PDU: %s", err)
+		}
+	}
 }
 
 func (t *Translator) mappingGroupUpdates(ctx context.Context,
-	mg *mappingGroup, wg *sync.WaitGroup, setReqCh chan *gnmi.SetRequest,
-	errc chan error) {
+	mg *mappingGroup, gp *groupPoll, wg *sync.WaitGroup,
+	setReqCh chan *gnmi.SetRequest, errc chan error) {
 	defer wg.Done()
+	gp.lock.Lock()
+	defer gp.lock.Unlock()
+
+	// Clear data stores in preparation for filling them up again.
+	if err := gp.pduStore.Clear(); err != nil {
+		errc <- err
+		return
+	}
+	gp.mapperData = &sync.Map{}
+	gp.mapperData.Store("counters", t.counters)
 
 	// Get SNMP data.
-	if err := t.getSNMPData(mg); err != nil {
+	if err := t.getSNMPData(mg, gp.pduStore); err != nil {
 		errc <- err
+		return
 	}
 
 	// Produce updates and hand a SetRequest to the gNMI client.
 	setRequest := new(gnmi.SetRequest)
 	for modelName, model := range mg.models {
-		setRequest.Delete = append(setRequest.Delete,
-			pgnmi.PathFromString(model.rootPath))
+		if model.paths == nil && !mg.hasAncestorModel(model) {
+			setRequest.Delete = append(setRequest.Delete,
+				pgnmi.PathFromString(model.rootPath))
+			if t.CounterRates {
+				setRequest.Delete = append(setRequest.Delete,
+					counterRatePath(model.rootPath))
+			}
+		}
 		if up, ok := mg.updatePaths[modelName]; ok {
-			updates, err := t.updates(up)
+			updates, err := t.updates(up, gp)
 			if err != nil {
 				errc <- err
 				return
 			}
+			if model.paths != nil {
+				t.cacheLock.Lock()
+				t.lastUpdates[modelName] = updates
+				t.cacheLock.Unlock()
+			}
 			setRequest.Replace = append(setRequest.Replace, updates...)
 			t.Logger.Debugf("Replace for mapping group %s, model %s has %d updates",
 				mg.name, modelName, len(setRequest.Replace))
@@ -370,13 +600,36 @@ func (t *Translator) mappingGroupUpdates(ctx context.Context,
 			return
 		}
 	}
+	// Restore the paths of other groups' models that our deletions
+	// just wiped out, as those groups last polled them. Polling them
+	// again here would cost as much as the polls of their own groups.
+	t.cacheLock.Lock()
+	for _, modelName := range mg.restores {
+		setRequest.Replace = append(setRequest.Replace,
+			t.lastUpdates[modelName]...)
+	}
+	t.cacheLock.Unlock()
 
 	setReqCh <- setRequest
 }
 
+// hasAncestorModel returns whether a model in the group is rooted
+// above the specified model, in which case deleting the ancestor's
+// root path deletes the model's too.
+func (mg *mappingGroup) hasAncestorModel(m *model) bool {
+	for _, a := range mg.models {
+		if a.paths == nil && strings.HasPrefix(m.rootPath, a.rootPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // A mappingGroup is a set of related translations that share dependencies.
 func (t *Translator) mappingGroupsFromPaths(paths []string) (map[string]*mappingGroup,
 	error) {
+	t.cacheLock.Lock()
+	defer t.cacheLock.Unlock()
 	phb := md5.Sum([]byte(strings.Join(paths, "")))
 	pathHash := string(phb[:])
 
@@ -421,15 +674,10 @@ func (t *Translator) mappingGroupsFromPaths(paths []string) (map[string]*mapping
 	// Pare down mappingGroups to include only the models we need for the
 	// provided paths.
 	reducedMg := map[string]*mappingGroup{}
-	for _, mg := range supportedMappingGroups {
+	for _, mg := range t.mappingGroups {
 		for _, mod := range mg.models {
 			for _, p := range paths {
-				match, err := regexp.MatchString(fmt.Sprintf("^%s/.*",
-					mod.rootPath), p)
-				if err != nil {
-					return nil, err
-				}
-				if match {
+				if t.modelNameForPath(p) == mod.name {
 					if _, ok := reducedMg[mg.name]; !ok {
 						reducedMg[mg.name] = &mappingGroup{
 							name:        mg.name,
@@ -439,25 +687,7 @@ func (t *Translator) mappingGroupsFromPaths(paths []string) (map[string]*mapping
 					}
 					cmg := reducedMg[mg.name]
 					if _, ok := cmg.models[mod.name]; !ok {
-						cmg.models[mod.name] = mod.Copy()
-
-						// Swap text OIDs out for their numeric equivalents.
-						for i, oid := range cmg.models[mod.name].snmpGetOIDs {
-							obj := t.mibStore.GetObject(oid)
-							if obj != nil {
-								cmg.models[mod.name].snmpGetOIDs[i] = obj.Oid
-								// Add back ".0" for scalars
-								if oid[len(oid)-2:] == ".0" {
-									cmg.models[mod.name].snmpGetOIDs[i] += ".0"
-								}
-							}
-						}
-						for i, oid := range cmg.models[mod.name].snmpWalkOIDs {
-							obj := t.mibStore.GetObject(oid)
-							if obj != nil {
-								cmg.models[mod.name].snmpWalkOIDs[i] = obj.Oid
-							}
-						}
+						cmg.models[mod.name] = t.numericModel(mod)
 					}
 					cmg.updatePaths[mod.name] = append(cmg.updatePaths[mod.name], p)
 				}
@@ -465,8 +695,128 @@ func (t *Translator) mappingGroupsFromPaths(paths []string) (map[string]*mapping
 		}
 	}
 
+	// Fetch data for any dependencies that aren't already included.
+	for _, mg := range reducedMg {
+		for _, mod := range mg.models {
+			for _, dep := range mod.dependencies {
+				if _, ok := mg.models[dep]; ok {
+					continue
+				}
+				dm, ok := t.models[dep]
+				if !ok {
+					return nil, fmt.Errorf("Unknown dependency '%s' of model '%s'",
+						dep, mod.name)
+				}
+				if mg.dependencies == nil {
+					mg.dependencies = map[string]*model{}
+				}
+				mg.dependencies[dep] = t.numericModel(dm)
+			}
+		}
+	}
+
+	// A group deleting the root path of a model that claims only
+	// some of the paths beneath it wipes out that model's data too,
+	// so it has to send that data again.
+	for _, mg := range reducedMg {
+		for _, mod := range mg.models {
+			if mod.paths != nil || mg.hasAncestorModel(mod) {
+				continue
+			}
+			for _, nm := range t.models {
+				if _, ok := mg.models[nm.name]; ok || nm.paths == nil ||
+					!pathContains(mod.rootPath, nm.rootPath) {
+					continue
+				}
+				mg.restores = append(mg.restores, nm.name)
+			}
+		}
+		sort.Strings(mg.restores)
+	}
+
 	// Store mapping groups for these paths.
 	t.pathsMappingGroups[pathHash] = reducedMg
 
 	return reducedMg, nil
 }
+
+// modelNameForPath returns the name of the model responsible for a
+// path: the one with the longest root path containing it, so that
+// models may be rooted beneath other models. A model matching the
+// path with its paths pattern wins over one sharing its root path.
+func (t *Translator) modelNameForPath(path string) string {
+	name, rootPath, matched := "", "", false
+	for _, m := range t.models {
+		if !strings.HasPrefix(path, m.rootPath+"/") ||
+			len(m.rootPath) < len(rootPath) {
+			continue
+		}
+		match := m.paths != nil && m.paths.MatchString(path)
+		if m.paths != nil && !match {
+			continue
+		}
+		if len(m.rootPath) > len(rootPath) || match && !matched {
+			name, rootPath, matched = m.name, m.rootPath, match
+		}
+	}
+	return name
+}
+
+// MappingGroup returns the name of the mapping group containing the
+// named model. Models in the same group are polled together.
+func (t *Translator) MappingGroup(model string) (string, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	for _, mg := range t.mappingGroups {
+		if _, ok := mg.models[model]; ok {
+			return mg.name, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown model '%s'", model)
+}
+
+// MappingGroupPaths returns the paths mapped by the models of each
+// mapping group, keyed by group name, for polling groups separately.
+func (t *Translator) MappingGroupPaths() map[string][]string {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	groups := make(map[string]string)
+	for _, mg := range t.mappingGroups {
+		for name := range mg.models {
+			groups[name] = mg.name
+		}
+	}
+	paths := make(map[string][]string)
+	for p := range t.Mappings {
+		if mg, ok := groups[t.modelNameForPath(p)]; ok {
+			paths[mg] = append(paths[mg], p)
+		}
+	}
+	for _, ps := range paths {
+		sort.Strings(ps)
+	}
+	return paths
+}
+
+// numericModel returns a copy of a model with its text OIDs swapped
+// out for their numeric equivalents.
+func (t *Translator) numericModel(mod *model) *model {
+	m := mod.Copy()
+	for i, oid := range m.snmpGetOIDs {
+		obj := t.mibStore.GetObject(oid)
+		if obj != nil {
+			m.snmpGetOIDs[i] = obj.Oid
+			// Add back ".0" for scalars
+			if strings.HasSuffix(oid, ".0") {
+				m.snmpGetOIDs[i] += ".0"
+			}
+		}
+	}
+	for i, oid := range m.snmpWalkOIDs {
+		obj := t.mibStore.GetObject(oid)
+		if obj != nil {
+			m.snmpWalkOIDs[i] = obj.Oid
+		}
+	}
+	return m
+}
@@ -10,14 +10,16 @@ import (
 	"reflect"
 	"regexp"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
 	"github.com/aristanetworks/cloudvision-go/provider/openconfig"
 	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
 	"github.com/openconfig/gnmi/proto/gnmi"
-	"github.com/soniah/gosnmp"
 )
 
 var basicEntPhysicalTableResponse = `
@@ -55,6 +57,94 @@ var basicEntPhysicalTableResponse = `
 .1.3.6.1.2.1.47.1.1.1.1.13.100601110 = STRING:
 `
 
+// A chassis with a temperature sensor, a fan with a speed sensor,
+// and a power supply with input and output sensors.
+var sensorEntPhysicalTableResponse = `
+.1.3.6.1.2.1.47.1.1.1.1.2.1 = STRING: DCS-7280SR-48C6 Chassis
+.1.3.6.1.2.1.47.1.1.1.1.2.100006001 = STRING: Cpu temp sensor
+.1.3.6.1.2.1.47.1.1.1.1.2.100006002 = STRING: Rear temp sensor
+.1.3.6.1.2.1.47.1.1.1.1.2.100601110 = STRING: Fan Tray 1 Fan 1
+.1.3.6.1.2.1.47.1.1.1.1.2.100601111 = STRING: Fan Tray 1 Fan 1 Sensor 1
+.1.3.6.1.2.1.47.1.1.1.1.2.100711101 = STRING: PowerSupply1
+.1.3.6.1.2.1.47.1.1.1.1.2.100711102 = STRING: Input voltage sensor for PowerSupply1
+.1.3.6.1.2.1.47.1.1.1.1.2.100711103 = STRING: Output current sensor for PowerSupply1
+.1.3.6.1.2.1.47.1.1.1.1.2.100711104 = STRING: Output power sensor for PowerSupply1
+.1.3.6.1.2.1.47.1.1.1.1.4.1 = INTEGER: 0
+.1.3.6.1.2.1.47.1.1.1.1.4.100006001 = INTEGER: 1
+.1.3.6.1.2.1.47.1.1.1.1.4.100006002 = INTEGER: 1
+.1.3.6.1.2.1.47.1.1.1.1.4.100601110 = INTEGER: 1
+.1.3.6.1.2.1.47.1.1.1.1.4.100601111 = INTEGER: 100601110
+.1.3.6.1.2.1.47.1.1.1.1.4.100711101 = INTEGER: 1
+.1.3.6.1.2.1.47.1.1.1.1.4.100711102 = INTEGER: 100711101
+.1.3.6.1.2.1.47.1.1.1.1.4.100711103 = INTEGER: 100711101
+.1.3.6.1.2.1.47.1.1.1.1.4.100711104 = INTEGER: 100711101
+.1.3.6.1.2.1.47.1.1.1.1.5.1 = INTEGER: chassis(3)
+.1.3.6.1.2.1.47.1.1.1.1.5.100006001 = INTEGER: sensor(8)
+.1.3.6.1.2.1.47.1.1.1.1.5.100006002 = INTEGER: sensor(8)
+.1.3.6.1.2.1.47.1.1.1.1.5.100601110 = INTEGER: fan(7)
+.1.3.6.1.2.1.47.1.1.1.1.5.100601111 = INTEGER: sensor(8)
+.1.3.6.1.2.1.47.1.1.1.1.5.100711101 = INTEGER: powerSupply(6)
+.1.3.6.1.2.1.47.1.1.1.1.5.100711102 = INTEGER: sensor(8)
+.1.3.6.1.2.1.47.1.1.1.1.5.100711103 = INTEGER: sensor(8)
+.1.3.6.1.2.1.47.1.1.1.1.5.100711104 = INTEGER: sensor(8)
+`
+
+var entPhySensorTableResponse = `
+.1.3.6.1.2.1.99.1.1.1.1.100006001 = INTEGER: celsius(8)
+.1.3.6.1.2.1.99.1.1.1.1.100006002 = INTEGER: celsius(8)
+.1.3.6.1.2.1.99.1.1.1.1.100601111 = INTEGER: rpm(10)
+.1.3.6.1.2.1.99.1.1.1.1.100711102 = INTEGER: voltsAC(3)
+.1.3.6.1.2.1.99.1.1.1.1.100711103 = INTEGER: amperes(5)
+.1.3.6.1.2.1.99.1.1.1.1.100711104 = INTEGER: watts(6)
+.1.3.6.1.2.1.99.1.1.1.2.100006001 = INTEGER: units(9)
+.1.3.6.1.2.1.99.1.1.1.2.100006002 = INTEGER: units(9)
+.1.3.6.1.2.1.99.1.1.1.2.100601111 = INTEGER: units(9)
+.1.3.6.1.2.1.99.1.1.1.2.100711102 = INTEGER: units(9)
+.1.3.6.1.2.1.99.1.1.1.2.100711103 = INTEGER: milli(8)
+.1.3.6.1.2.1.99.1.1.1.2.100711104 = INTEGER: units(9)
+.1.3.6.1.2.1.99.1.1.1.3.100006001 = INTEGER: 1
+.1.3.6.1.2.1.99.1.1.1.3.100006002 = INTEGER: 1
+.1.3.6.1.2.1.99.1.1.1.3.100601111 = INTEGER: 0
+.1.3.6.1.2.1.99.1.1.1.3.100711102 = INTEGER: 0
+.1.3.6.1.2.1.99.1.1.1.3.100711103 = INTEGER: 0
+.1.3.6.1.2.1.99.1.1.1.3.100711104 = INTEGER: 2
+.1.3.6.1.2.1.99.1.1.1.4.100006001 = INTEGER: 385
+.1.3.6.1.2.1.99.1.1.1.4.100006002 = INTEGER: 0
+.1.3.6.1.2.1.99.1.1.1.4.100601111 = INTEGER: 8160
+.1.3.6.1.2.1.99.1.1.1.4.100711102 = INTEGER: 208
+.1.3.6.1.2.1.99.1.1.1.4.100711103 = INTEGER: 12500
+.1.3.6.1.2.1.99.1.1.1.4.100711104 = INTEGER: 26050
+.1.3.6.1.2.1.99.1.1.1.5.100006001 = INTEGER: ok(1)
+.1.3.6.1.2.1.99.1.1.1.5.100006002 = INTEGER: unavailable(2)
+.1.3.6.1.2.1.99.1.1.1.5.100601111 = INTEGER: ok(1)
+.1.3.6.1.2.1.99.1.1.1.5.100711102 = INTEGER: ok(1)
+.1.3.6.1.2.1.99.1.1.1.5.100711103 = INTEGER: ok(1)
+.1.3.6.1.2.1.99.1.1.1.5.100711104 = INTEGER: ok(1)
+`
+
+var hrProcessorLoadResponse = `
+.1.3.6.1.2.1.25.3.3.1.2.196609 = INTEGER: 12
+.1.3.6.1.2.1.25.3.3.1.2.196608 = INTEGER: 7
+`
+
+var hrStorageTableResponse = `
+.1.3.6.1.2.1.25.2.3.1.2.1 = OID: .1.3.6.1.2.1.25.2.1.2
+.1.3.6.1.2.1.25.2.3.1.2.3 = OID: .1.3.6.1.2.1.25.2.1.3
+.1.3.6.1.2.1.25.2.3.1.2.6 = OID: .1.3.6.1.2.1.25.2.1.1
+.1.3.6.1.2.1.25.2.3.1.3.1 = STRING: Physical memory
+.1.3.6.1.2.1.25.2.3.1.3.3 = STRING: Virtual memory
+.1.3.6.1.2.1.25.2.3.1.3.6 = STRING: Memory buffers
+.1.3.6.1.2.1.25.2.3.1.4.1 = INTEGER: 1024
+.1.3.6.1.2.1.25.2.3.1.4.3 = INTEGER: 1024
+.1.3.6.1.2.1.25.2.3.1.4.6 = INTEGER: 1024
+.1.3.6.1.2.1.25.2.3.1.5.1 = INTEGER: 8029060
+.1.3.6.1.2.1.25.2.3.1.5.3 = INTEGER: 8029060
+.1.3.6.1.2.1.25.2.3.1.5.6 = INTEGER: 8029060
+.1.3.6.1.2.1.25.2.3.1.6.1 = INTEGER: 3489536
+.1.3.6.1.2.1.25.2.3.1.6.3 = INTEGER: 3489536
+.1.3.6.1.2.1.25.2.3.1.6.6 = INTEGER: 162904
+`
+
 // snmpwalk responses for six interfaces, four interface types.
 var basicIfTableResponse = `
 .1.3.6.1.2.1.2.2.1.1.3001 = INTEGER: 3001
@@ -297,6 +387,97 @@ var ifXTable64BitResponse = `
 .1.3.6.1.2.1.31.1.1.1.10.3002 = Counter64: 103002
 `
 
+// Rows whose OIDs have two components where ifIndex has one.
+var badIndexIfTableResponse = `
+.1.3.6.1.2.1.2.2.1.2.1.7 = STRING: Bogus1/7
+.1.3.6.1.2.1.2.2.1.3.1.7 = INTEGER: 6
+.1.3.6.1.2.1.2.2.1.10.1.7 = Counter32: 17
+`
+
+var badIndexIfXTableResponse = `
+.1.3.6.1.2.1.31.1.1.1.1.1.7 = STRING: Bogus1/7
+.1.3.6.1.2.1.31.1.1.1.6.1.7 = Counter64: 17
+`
+
+var ipAddressTableResponse = `
+.1.3.6.1.2.1.4.34.1.3.1.4.10.1.2.3 = INTEGER: 3001
+.1.3.6.1.2.1.4.34.1.3.1.4.10.1.2.255 = INTEGER: 3001
+.1.3.6.1.2.1.4.34.1.3.1.4.172.30.174.29 = INTEGER: 999011
+.1.3.6.1.2.1.4.34.1.3.2.16.254.128.0.0.0.0.0.0.0.0.0.0.0.0.0.1 = INTEGER: 3001
+.1.3.6.1.2.1.4.34.1.4.1.4.10.1.2.3 = INTEGER: 1
+.1.3.6.1.2.1.4.34.1.4.1.4.10.1.2.255 = INTEGER: 3
+.1.3.6.1.2.1.4.34.1.4.1.4.172.30.174.29 = INTEGER: 1
+.1.3.6.1.2.1.4.34.1.4.2.16.254.128.0.0.0.0.0.0.0.0.0.0.0.0.0.1 = INTEGER: 1
+.1.3.6.1.2.1.4.34.1.5.1.4.10.1.2.3 = OID: .1.3.6.1.2.1.4.32.1.5.3001.1.4.10.1.2.0.24
+.1.3.6.1.2.1.4.34.1.5.1.4.10.1.2.255 = OID: .0.0
+.1.3.6.1.2.1.4.34.1.5.1.4.172.30.174.29 = OID: .1.3.6.1.2.1.4.32.1.5.999011.1.4.172.30.174.0.25
+.1.3.6.1.2.1.4.34.1.5.2.16.254.128.0.0.0.0.0.0.0.0.0.0.0.0.0.1 = OID: ` +
+	`.1.3.6.1.2.1.4.32.1.5.3001.2.16.254.128.0.0.0.0.0.0.0.0.0.0.0.0.0.0.64
+`
+
+var ipAddrTableResponse = `
+.1.3.6.1.2.1.4.20.1.1.172.30.174.29 = IpAddress: 172.30.174.29
+.1.3.6.1.2.1.4.20.1.2.172.30.174.29 = INTEGER: 999011
+.1.3.6.1.2.1.4.20.1.3.172.30.174.29 = IpAddress: 255.255.255.128
+`
+
+// badIndexHostResourcesResponse has a processor and a RAM entry
+// with too many index components.
+var badIndexHostResourcesResponse = `
+.1.3.6.1.2.1.25.3.3.1.2.196610.1 = INTEGER: 50
+.1.3.6.1.2.1.25.2.3.1.2.9.9 = OID: .1.3.6.1.2.1.25.2.1.2
+.1.3.6.1.2.1.25.2.3.1.4.9.9 = INTEGER: 4096
+.1.3.6.1.2.1.25.2.3.1.5.9.9 = INTEGER: 100
+.1.3.6.1.2.1.25.2.3.1.6.9.9 = INTEGER: 50
+`
+
+// badIndexIPNetToPhysicalTableResponse has a neighbor with a
+// truncated address.
+var badIndexIPNetToPhysicalTableResponse = `
+.1.3.6.1.2.1.4.35.1.4.3001.1.4.10.1 = Hex-STRING: 00 1C 73 01 02 06
+.1.3.6.1.2.1.4.35.1.6.3001.1.4.10.1 = INTEGER: 3
+`
+
+var ipNetToPhysicalTableResponse = `
+.1.3.6.1.2.1.4.35.1.4.3001.1.4.10.1.2.4 = Hex-STRING: 00 1C 73 01 02 03
+.1.3.6.1.2.1.4.35.1.4.3001.1.4.10.1.2.5 = Hex-STRING: 00 1C 73 01 02 04
+.1.3.6.1.2.1.4.35.1.4.3001.2.16.254.128.0.0.0.0.0.0.0.0.0.0.0.0.0.2 = Hex-STRING: 00 1C 73 01 02 05
+.1.3.6.1.2.1.4.35.1.6.3001.1.4.10.1.2.4 = INTEGER: 3
+.1.3.6.1.2.1.4.35.1.6.3001.1.4.10.1.2.5 = INTEGER: 2
+.1.3.6.1.2.1.4.35.1.6.3001.2.16.254.128.0.0.0.0.0.0.0.0.0.0.0.0.0.2 = INTEGER: 4
+`
+
+// A default route, a connected route, an ECMP route, and a blackhole
+// route.
+var inetCidrRouteTableResponse = `
+.1.3.6.1.2.1.4.24.7.1.7.1.4.0.0.0.0.0.2.0.0.1.4.10.1.2.1 = INTEGER: 3001
+.1.3.6.1.2.1.4.24.7.1.7.1.4.10.1.2.0.24.2.0.0.0.0 = INTEGER: 3001
+.1.3.6.1.2.1.4.24.7.1.7.1.4.10.10.0.0.16.2.0.0.1.4.10.1.2.1 = INTEGER: 3001
+.1.3.6.1.2.1.4.24.7.1.7.1.4.10.10.0.0.16.2.0.0.1.4.10.1.3.1 = INTEGER: 3002
+.1.3.6.1.2.1.4.24.7.1.7.1.4.192.0.2.0.24.2.0.0.0.0 = INTEGER: 0
+.1.3.6.1.2.1.4.24.7.1.8.1.4.0.0.0.0.0.2.0.0.1.4.10.1.2.1 = INTEGER: 4
+.1.3.6.1.2.1.4.24.7.1.8.1.4.10.1.2.0.24.2.0.0.0.0 = INTEGER: 3
+.1.3.6.1.2.1.4.24.7.1.8.1.4.10.10.0.0.16.2.0.0.1.4.10.1.2.1 = INTEGER: 4
+.1.3.6.1.2.1.4.24.7.1.8.1.4.10.10.0.0.16.2.0.0.1.4.10.1.3.1 = INTEGER: 4
+.1.3.6.1.2.1.4.24.7.1.8.1.4.192.0.2.0.24.2.0.0.0.0 = INTEGER: 5
+.1.3.6.1.2.1.4.24.7.1.9.1.4.0.0.0.0.0.2.0.0.1.4.10.1.2.1 = INTEGER: 3
+.1.3.6.1.2.1.4.24.7.1.9.1.4.10.1.2.0.24.2.0.0.0.0 = INTEGER: 2
+.1.3.6.1.2.1.4.24.7.1.9.1.4.10.10.0.0.16.2.0.0.1.4.10.1.2.1 = INTEGER: 14
+.1.3.6.1.2.1.4.24.7.1.9.1.4.10.10.0.0.16.2.0.0.1.4.10.1.3.1 = INTEGER: 14
+.1.3.6.1.2.1.4.24.7.1.9.1.4.192.0.2.0.24.2.0.0.0.0 = INTEGER: 3
+`
+
+func subintfTestPath(intfName string, elems ...string) *gnmi.Path {
+	return pgnmi.Path(append([]string{"interfaces",
+		pgnmi.ListWithKey("interface", "name", intfName), "subinterfaces",
+		pgnmi.ListWithKey("subinterface", "index", "0")}, elems...)...)
+}
+
+func aftTestPath(elems ...string) *gnmi.Path {
+	return pgnmi.Path(append([]string{"network-instances",
+		pgnmi.ListWithKey("network-instance", "name", "default"), "afts"}, elems...)...)
+}
+
 // mockget and mockwalk are the SNMP get and walk routines used for
 // injecting mocked SNMP data into the polling routines.
 func mockget(oids []string, responses map[string][]*gosnmp.SnmpPDU,
@@ -343,7 +524,9 @@ type translatorTestCase struct {
 	name                string
 	responses           map[string][]*gosnmp.SnmpPDU
 	mappings            map[string][]Mapper
+	mappingConfig       string
 	updatePaths         []string
+	priorPaths          []string
 	expectedSetRequests []*gnmi.SetRequest
 	setRequestMatchAll  bool
 	expectedErr         error
@@ -408,6 +591,15 @@ func runTranslatorTest(t *testing.T, mibStore smi.Store, tc translatorTestCase)
 	if len(tc.mappings) > 0 {
 		trans.Mappings = tc.mappings
 	}
+	if tc.mappingConfig != "" {
+		cfg, err := ParseMappingConfig([]byte(tc.mappingConfig))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := trans.AddMappings(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
 	trans.Getter = func(oids []string) (*gosnmp.SnmpPacket, error) {
 		return mockget(oids, tc.responses, mibStore)
 	}
@@ -424,7 +616,15 @@ func runTranslatorTest(t *testing.T, mibStore smi.Store, tc translatorTestCase)
 		setReqs = append(setReqs, req)
 		return nil, nil
 	})
-	// Call translator.Poll and check the translator's output.
+	// Poll any paths the test needs polled first, then call
+	// translator.Poll and check the translator's output.
+	if tc.priorPaths != nil {
+		if err := trans.Poll(context.Background(), client,
+			tc.priorPaths); err != nil {
+			t.Fatalf("Failure in translator.Poll: %v", err)
+		}
+		setReqs = nil
+	}
 	err = trans.Poll(context.Background(), client, tc.updatePaths)
 	if err != nil {
 		t.Fatalf("Failure in translator.Poll: %v", err)
@@ -465,6 +665,44 @@ func TestTranslator(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "updateSystemResources",
+			updatePaths: []string{"^/system/(cpus|memory)/"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"hrProcessorLoad": PDUsFromString(hrProcessorLoadResponse +
+					badIndexHostResourcesResponse),
+				"hrStorageTable": PDUsFromString(hrStorageTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Delete: []*gnmi.Path{pgnmi.Path("system", "cpus"),
+						pgnmi.Path("system", "memory")},
+					Replace: []*gnmi.Update{
+						update(pgnmi.Path("system", "cpus",
+							pgnmi.ListWithKey("cpu", "index", "196608"), "index"), uintval(196608)),
+						update(pgnmi.Path("system", "cpus",
+							pgnmi.ListWithKey("cpu", "index", "196608"), "state", "index"),
+							uintval(196608)),
+						update(pgnmi.Path("system", "cpus",
+							pgnmi.ListWithKey("cpu", "index", "196608"), "state", "total",
+							"instant"), uintval(7)),
+						update(pgnmi.Path("system", "cpus",
+							pgnmi.ListWithKey("cpu", "index", "196609"), "index"), uintval(196609)),
+						update(pgnmi.Path("system", "cpus",
+							pgnmi.ListWithKey("cpu", "index", "196609"), "state", "index"),
+							uintval(196609)),
+						update(pgnmi.Path("system", "cpus",
+							pgnmi.ListWithKey("cpu", "index", "196609"), "state", "total",
+							"instant"), uintval(12)),
+						update(pgnmi.Path("system", "memory", "state", "physical"),
+							uintval(8221757440)),
+						update(pgnmi.Path("system", "memory", "state", "used"),
+							uintval(3573284864)),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
 		{
 			name:        "updatePlatformBasic",
 			updatePaths: []string{"^/components/"},
@@ -537,6 +775,101 @@ func TestTranslator(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "updatePlatformSensors",
+			updatePaths: []string{"^/components/.*/(temperature|fan|power-supply)/"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"entPhysicalDescr": columnPDUs(sensorEntPhysicalTableResponse,
+					".1.3.6.1.2.1.47.1.1.1.1.2."),
+				"entPhysicalContainedIn": columnPDUs(sensorEntPhysicalTableResponse,
+					".1.3.6.1.2.1.47.1.1.1.1.4."),
+				"entPhysicalClass": columnPDUs(sensorEntPhysicalTableResponse,
+					".1.3.6.1.2.1.47.1.1.1.1.5."),
+				"entPhySensorTable": PDUsFromString(entPhySensorTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Replace: []*gnmi.Update{
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100006001"), "state",
+							"temperature", "instant"), pgnmi.Floatval(38.5)),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100601110"), "fan",
+							"state", "speed"),
+							uintval(8160)),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100711101"),
+							"power-supply", "state", "input-voltage"), pgnmi.Floatval(208)),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100711101"),
+							"power-supply", "state", "output-current"), pgnmi.Floatval(12.5)),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100711101"),
+							"power-supply", "state", "output-power"), pgnmi.Floatval(260.5)),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
+		{
+			// Polling platform deletes the sensors' data, so it has to
+			// be sent again as the sensors were last polled.
+			name:        "updatePlatformRestoresSensors",
+			priorPaths:  []string{"^/components/.*/(temperature|fan|power-supply)/"},
+			updatePaths: []string{"/components/component[name=name]/state/description"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"entPhysicalEntry": PDUsFromString(sensorEntPhysicalTableResponse),
+				"entPhysicalDescr": columnPDUs(sensorEntPhysicalTableResponse,
+					".1.3.6.1.2.1.47.1.1.1.1.2."),
+				"entPhysicalContainedIn": columnPDUs(sensorEntPhysicalTableResponse,
+					".1.3.6.1.2.1.47.1.1.1.1.4."),
+				"entPhysicalClass": columnPDUs(sensorEntPhysicalTableResponse,
+					".1.3.6.1.2.1.47.1.1.1.1.5."),
+				"entPhySensorTable": PDUsFromString(entPhySensorTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Delete: []*gnmi.Path{pgnmi.Path("components")},
+					Replace: []*gnmi.Update{
+						update(pgnmi.PlatformComponentStatePath("1", "description"),
+							strval("DCS-7280SR-48C6 Chassis")),
+						update(pgnmi.PlatformComponentStatePath("100006001", "description"),
+							strval("Cpu temp sensor")),
+						update(pgnmi.PlatformComponentStatePath("100006002", "description"),
+							strval("Rear temp sensor")),
+						update(pgnmi.PlatformComponentStatePath("100601110", "description"),
+							strval("Fan Tray 1 Fan 1")),
+						update(pgnmi.PlatformComponentStatePath("100601111", "description"),
+							strval("Fan Tray 1 Fan 1 Sensor 1")),
+						update(pgnmi.PlatformComponentStatePath("100711101", "description"),
+							strval("PowerSupply1")),
+						update(pgnmi.PlatformComponentStatePath("100711102", "description"),
+							strval("Input voltage sensor for PowerSupply1")),
+						update(pgnmi.PlatformComponentStatePath("100711103", "description"),
+							strval("Output current sensor for PowerSupply1")),
+						update(pgnmi.PlatformComponentStatePath("100711104", "description"),
+							strval("Output power sensor for PowerSupply1")),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100006001"), "state",
+							"temperature", "instant"), pgnmi.Floatval(38.5)),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100601110"), "fan",
+							"state", "speed"),
+							uintval(8160)),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100711101"),
+							"power-supply", "state", "input-voltage"), pgnmi.Floatval(208)),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100711101"),
+							"power-supply", "state", "output-current"), pgnmi.Floatval(12.5)),
+						update(pgnmi.Path("components",
+							pgnmi.ListWithKey("component", "name", "100711101"),
+							"power-supply", "state", "output-power"), pgnmi.Floatval(260.5)),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
 		{
 			name:        "updateInterfacesBasic",
 			updatePaths: []string{"^/interfaces/"},
@@ -1183,6 +1516,46 @@ func TestTranslator(t *testing.T) {
 				},
 			},
 		},
+		{
+			// PDUs with bad indexes are skipped.
+			name:        "updateInterfacesBadIndex",
+			updatePaths: []string{"^/interfaces/"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"ifTable": PDUsFromString(ifTable64BitResponse +
+					badIndexIfTableResponse),
+				"ifXTable": PDUsFromString(ifXTable64BitResponse +
+					badIndexIfXTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Delete: []*gnmi.Path{pgnmi.Path("interfaces")},
+					Replace: []*gnmi.Update{
+						update(pgnmi.IntfStatePath("Ethernet3/1", "name"), strval("Ethernet3/1")),
+						update(pgnmi.IntfPath("Ethernet3/1", "name"), strval("Ethernet3/1")),
+						update(pgnmi.IntfConfigPath("Ethernet3/1", "name"), strval("Ethernet3/1")),
+						update(pgnmi.IntfStatePath("Ethernet3/2", "name"), strval("Ethernet3/2")),
+						update(pgnmi.IntfPath("Ethernet3/2", "name"), strval("Ethernet3/2")),
+						update(pgnmi.IntfConfigPath("Ethernet3/2", "name"), strval("Ethernet3/2")),
+						update(pgnmi.IntfStatePath("Ethernet3/1", "type"),
+							strval("iana-if-type:ethernetCsmacd")),
+						update(pgnmi.IntfStatePath("Ethernet3/2", "type"),
+							strval("iana-if-type:ethernetCsmacd")),
+						update(pgnmi.IntfStateCountersPath("Ethernet3/1", "in-octets"),
+							uintval(1030011)),
+						update(pgnmi.IntfStateCountersPath("Ethernet3/2", "in-octets"),
+							uintval(1030022)),
+						update(pgnmi.IntfStateCountersPath("Ethernet3/1", "in-multicast-pkts"),
+							uintval(83001)),
+						update(pgnmi.IntfStateCountersPath("Ethernet3/2", "in-multicast-pkts"),
+							uintval(83002)),
+						update(pgnmi.IntfStateCountersPath("Ethernet3/1", "out-octets"),
+							uintval(103001)),
+						update(pgnmi.IntfStateCountersPath("Ethernet3/2", "out-octets"),
+							uintval(103002)),
+					},
+				},
+			},
+		},
 		{
 			// chassis ID has no subtype
 			name:        "lldpStringChassisIDNoSubtype",
@@ -1205,6 +1578,268 @@ func TestTranslator(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "updateIPAddresses",
+			updatePaths: []string{"^/interfaces/.*/(index|addresses/)"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"ifTable":        PDUsFromString(basicIfTableResponse),
+				"ipAddressTable": PDUsFromString(ipAddressTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Replace: []*gnmi.Update{
+						update(subintfTestPath("Ethernet3/1", "index"), uintval(0)),
+						update(subintfTestPath("Ethernet3/1", "state", "index"), uintval(0)),
+						update(subintfTestPath("Management1/1", "index"), uintval(0)),
+						update(subintfTestPath("Management1/1", "state", "index"), uintval(0)),
+						update(subintfTestPath("Ethernet3/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "10.1.2.3"), "ip"),
+							strval("10.1.2.3")),
+						update(subintfTestPath("Ethernet3/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "10.1.2.3"), "state", "ip"),
+							strval("10.1.2.3")),
+						update(subintfTestPath("Ethernet3/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "10.1.2.3"), "state",
+							"prefix-length"), uintval(24)),
+						update(subintfTestPath("Management1/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "172.30.174.29"), "ip"),
+							strval("172.30.174.29")),
+						update(subintfTestPath("Management1/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "172.30.174.29"), "state", "ip"),
+							strval("172.30.174.29")),
+						update(subintfTestPath("Management1/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "172.30.174.29"), "state",
+							"prefix-length"), uintval(25)),
+						update(subintfTestPath("Ethernet3/1", "ipv6", "addresses",
+							pgnmi.ListWithKey("address", "ip", "fe80::1"), "ip"),
+							strval("fe80::1")),
+						update(subintfTestPath("Ethernet3/1", "ipv6", "addresses",
+							pgnmi.ListWithKey("address", "ip", "fe80::1"), "state", "ip"),
+							strval("fe80::1")),
+						update(subintfTestPath("Ethernet3/1", "ipv6", "addresses",
+							pgnmi.ListWithKey("address", "ip", "fe80::1"), "state",
+							"prefix-length"), uintval(64)),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
+		{
+			name:        "updateIPAddressesFromIPAddrTable",
+			updatePaths: []string{"^/interfaces/.*/(index|addresses/)"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"ifTable":     PDUsFromString(basicIfTableResponse),
+				"ipAddrTable": PDUsFromString(ipAddrTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Replace: []*gnmi.Update{
+						update(subintfTestPath("Management1/1", "index"), uintval(0)),
+						update(subintfTestPath("Management1/1", "state", "index"), uintval(0)),
+						update(subintfTestPath("Management1/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "172.30.174.29"), "ip"),
+							strval("172.30.174.29")),
+						update(subintfTestPath("Management1/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "172.30.174.29"), "state", "ip"),
+							strval("172.30.174.29")),
+						update(subintfTestPath("Management1/1", "ipv4", "addresses",
+							pgnmi.ListWithKey("address", "ip", "172.30.174.29"), "state",
+							"prefix-length"), uintval(25)),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
+		{
+			name:        "updateNeighbors",
+			updatePaths: []string{"^/interfaces/.*/neighbors/"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"ifTable": PDUsFromString(basicIfTableResponse),
+				"ipNetToPhysicalTable": PDUsFromString(ipNetToPhysicalTableResponse +
+					badIndexIPNetToPhysicalTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Replace: []*gnmi.Update{
+						update(subintfTestPath("Ethernet3/1", "ipv4", "neighbors",
+							pgnmi.ListWithKey("neighbor", "ip", "10.1.2.4"), "ip"),
+							strval("10.1.2.4")),
+						update(subintfTestPath("Ethernet3/1", "ipv4", "neighbors",
+							pgnmi.ListWithKey("neighbor", "ip", "10.1.2.4"), "state", "ip"),
+							strval("10.1.2.4")),
+						update(subintfTestPath("Ethernet3/1", "ipv4", "neighbors",
+							pgnmi.ListWithKey("neighbor", "ip", "10.1.2.4"), "state",
+							"link-layer-address"), strval("00:1c:73:01:02:03")),
+						update(subintfTestPath("Ethernet3/1", "ipv4", "neighbors",
+							pgnmi.ListWithKey("neighbor", "ip", "10.1.2.4"), "state", "origin"),
+							strval("DYNAMIC")),
+						update(subintfTestPath("Ethernet3/1", "ipv6", "neighbors",
+							pgnmi.ListWithKey("neighbor", "ip", "fe80::2"), "ip"),
+							strval("fe80::2")),
+						update(subintfTestPath("Ethernet3/1", "ipv6", "neighbors",
+							pgnmi.ListWithKey("neighbor", "ip", "fe80::2"), "state", "ip"),
+							strval("fe80::2")),
+						update(subintfTestPath("Ethernet3/1", "ipv6", "neighbors",
+							pgnmi.ListWithKey("neighbor", "ip", "fe80::2"), "state",
+							"link-layer-address"), strval("00:1c:73:01:02:05")),
+						update(subintfTestPath("Ethernet3/1", "ipv6", "neighbors",
+							pgnmi.ListWithKey("neighbor", "ip", "fe80::2"), "state", "origin"),
+							strval("STATIC")),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
+		{
+			name:        "updateRoutes",
+			updatePaths: []string{"^/network-instances/"},
+			responses: map[string][]*gosnmp.SnmpPDU{
+				"ifTable":            PDUsFromString(basicIfTableResponse),
+				"inetCidrRouteTable": PDUsFromString(inetCidrRouteTableResponse),
+			},
+			expectedSetRequests: []*gnmi.SetRequest{
+				&gnmi.SetRequest{
+					Delete: []*gnmi.Path{pgnmi.Path("network-instances")},
+					Replace: []*gnmi.Update{
+						update(pgnmi.Path("network-instances",
+							pgnmi.ListWithKey("network-instance", "name", "default"), "name"),
+							strval("default")),
+						update(pgnmi.Path("network-instances",
+							pgnmi.ListWithKey("network-instance", "name", "default"),
+							"state", "name"), strval("default")),
+
+						// entries
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "0.0.0.0/0"), "prefix"),
+							strval("0.0.0.0/0")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "0.0.0.0/0"),
+							"state", "prefix"), strval("0.0.0.0/0")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "0.0.0.0/0"),
+							"state", "origin-protocol"),
+							strval("openconfig-policy-types:STATIC")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "0.0.0.0/0"),
+							"state", "next-hop-group"), uintval(1)),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "10.1.2.0/24"), "prefix"),
+							strval("10.1.2.0/24")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "10.1.2.0/24"),
+							"state", "prefix"), strval("10.1.2.0/24")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "10.1.2.0/24"),
+							"state", "origin-protocol"),
+							strval("openconfig-policy-types:DIRECTLY_CONNECTED")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "10.1.2.0/24"),
+							"state", "next-hop-group"), uintval(2)),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "10.10.0.0/16"), "prefix"),
+							strval("10.10.0.0/16")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "10.10.0.0/16"),
+							"state", "prefix"), strval("10.10.0.0/16")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "10.10.0.0/16"),
+							"state", "origin-protocol"),
+							strval("openconfig-policy-types:BGP")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "10.10.0.0/16"),
+							"state", "next-hop-group"), uintval(3)),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "192.0.2.0/24"), "prefix"),
+							strval("192.0.2.0/24")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "192.0.2.0/24"),
+							"state", "prefix"), strval("192.0.2.0/24")),
+						update(aftTestPath("ipv4-unicast",
+							pgnmi.ListWithKey("ipv4-entry", "prefix", "192.0.2.0/24"),
+							"state", "origin-protocol"),
+							strval("openconfig-policy-types:STATIC")),
+
+						// next hops
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "1"), "index"), uintval(1)),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "1"), "state", "index"),
+							uintval(1)),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "1"), "state", "ip-address"),
+							strval("10.1.2.1")),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "1"), "interface-ref",
+							"state", "interface"), strval("Ethernet3/1")),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "2"), "index"), uintval(2)),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "2"), "state", "index"),
+							uintval(2)),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "2"), "interface-ref",
+							"state", "interface"), strval("Ethernet3/1")),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "3"), "index"), uintval(3)),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "3"), "state", "index"),
+							uintval(3)),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "3"), "state", "ip-address"),
+							strval("10.1.3.1")),
+						update(aftTestPath("next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "3"), "interface-ref",
+							"state", "interface"), strval("Ethernet3/2")),
+
+						// next-hop groups
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "1"), "id"), uintval(1)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "1"), "state", "id"),
+							uintval(1)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "1"), "next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "1"), "index"), uintval(1)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "1"), "next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "1"), "state", "index"),
+							uintval(1)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "2"), "id"), uintval(2)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "2"), "state", "id"),
+							uintval(2)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "2"), "next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "2"), "index"), uintval(2)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "2"), "next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "2"), "state", "index"),
+							uintval(2)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "3"), "id"), uintval(3)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "3"), "state", "id"),
+							uintval(3)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "3"), "next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "1"), "index"), uintval(1)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "3"), "next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "1"), "state", "index"),
+							uintval(1)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "3"), "next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "3"), "index"), uintval(3)),
+						update(aftTestPath("next-hop-groups",
+							pgnmi.ListWithKey("next-hop-group", "id", "3"), "next-hops",
+							pgnmi.ListWithKey("next-hop", "index", "3"), "state", "index"),
+							uintval(3)),
+					},
+				},
+			},
+			setRequestMatchAll: true,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			runTranslatorTest(t, mibStore, tc)
@@ -1308,9 +1943,30 @@ func mappingGroupsEqual(mg1, mg2 *mappingGroup) bool {
 			return false
 		}
 	}
+	if len(mg1.dependencies) != len(mg2.dependencies) ||
+		!stringSliceEqual(mg1.restores, mg2.restores) {
+		return false
+	}
+	for k, m := range mg1.dependencies {
+		if d, ok := mg2.dependencies[k]; !ok || !modelsEqual(m, d) {
+			return false
+		}
+	}
 	return true
 }
 
+// columnPDUs returns the PDUs of a walk response beneath the
+// specified column OID.
+func columnPDUs(response, column string) []*gosnmp.SnmpPDU {
+	pdus := []*gosnmp.SnmpPDU{}
+	for _, p := range PDUsFromString(response) {
+		if strings.HasPrefix(p.Name, column) {
+			pdus = append(pdus, p)
+		}
+	}
+	return pdus
+}
+
 func matchingPaths(pattern string, paths []string) []string {
 	mp := []string{}
 	for _, p := range paths {
@@ -1326,10 +1982,19 @@ func TestMappingGroups(t *testing.T) {
 	for k := range DefaultMappings() {
 		defaultPaths = append(defaultPaths, k)
 	}
-	allIntfPaths := matchingPaths("^/interfaces/.*", defaultPaths)
-	allPlatformPaths := matchingPaths("^/components/.*", defaultPaths)
-	allSystemPaths := matchingPaths("^/system/.*", defaultPaths)
+	allIntfPaths := matchingPaths("^/interfaces/interface\\[name=name\\]/"+
+		"(name|config/|state/)", defaultPaths)
+	allIPAddressPaths := matchingPaths("^/interfaces/.*/(index|addresses/)", defaultPaths)
+	allNeighborPaths := matchingPaths("^/interfaces/.*/neighbors/", defaultPaths)
+	allSensorPaths := matchingPaths(
+		"^/components/.*/(state/temperature|fan|power-supply)/", defaultPaths)
+	allPlatformPaths := matchingPaths(
+		"^/components/component\\[name=name\\]/(config|name|state/[^/]+$)", defaultPaths)
+	allSystemPaths := matchingPaths("^/system/state/.*", defaultPaths)
+	allCPUPaths := matchingPaths("^/system/cpus/.*", defaultPaths)
+	allMemoryPaths := matchingPaths("^/system/memory/.*", defaultPaths)
 	allLldpPaths := matchingPaths("^/lldp/.*", defaultPaths)
+	allNetworkInstancePaths := matchingPaths("^/network-instances/.*", defaultPaths)
 	for _, tc := range []mappingGroupTestCase{
 		{
 			name:  "interfaces",
@@ -1343,6 +2008,31 @@ func TestMappingGroups(t *testing.T) {
 					updatePaths: map[string][]string{
 						"interfaces": allIntfPaths,
 					},
+					restores: []string{"ip-addresses", "neighbors"},
+				},
+				"ip-addresses": &mappingGroup{
+					name: "ip-addresses",
+					models: map[string]*model{
+						"ip-addresses": supportedModels["ip-addresses"],
+					},
+					dependencies: map[string]*model{
+						"interfaces": supportedModels["interfaces"],
+					},
+					updatePaths: map[string][]string{
+						"ip-addresses": allIPAddressPaths,
+					},
+				},
+				"neighbors": &mappingGroup{
+					name: "neighbors",
+					models: map[string]*model{
+						"neighbors": supportedModels["neighbors"],
+					},
+					dependencies: map[string]*model{
+						"interfaces": supportedModels["interfaces"],
+					},
+					updatePaths: map[string][]string{
+						"neighbors": allNeighborPaths,
+					},
 				},
 			},
 		},
@@ -1362,6 +2052,39 @@ func TestMappingGroups(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "memory",
+			paths: []string{"^/system/memory/"},
+			expectedMappingGroups: map[string]*mappingGroup{
+				"system": &mappingGroup{
+					name: "system",
+					models: map[string]*model{
+						"memory": supportedModels["memory"],
+					},
+					updatePaths: map[string][]string{
+						"memory": allMemoryPaths,
+					},
+				},
+			},
+		},
+		{
+			name:  "lldp",
+			paths: []string{"^/lldp/"},
+			expectedMappingGroups: map[string]*mappingGroup{
+				"interfaces-lldp": &mappingGroup{
+					name: "interfaces-lldp",
+					models: map[string]*model{
+						"lldp": supportedModels["lldp"],
+					},
+					dependencies: map[string]*model{
+						"interfaces": supportedModels["interfaces"],
+					},
+					updatePaths: map[string][]string{
+						"lldp": allLldpPaths,
+					},
+				},
+			},
+		},
 		{
 			name:  "none",
 			paths: []string{},
@@ -1376,14 +2099,55 @@ func TestMappingGroups(t *testing.T) {
 						"interfaces": allIntfPaths,
 						"lldp":       allLldpPaths,
 					},
+					restores: []string{"ip-addresses", "neighbors"},
+				},
+				"ip-addresses": &mappingGroup{
+					name: "ip-addresses",
+					models: map[string]*model{
+						"ip-addresses": supportedModels["ip-addresses"],
+					},
+					dependencies: map[string]*model{
+						"interfaces": supportedModels["interfaces"],
+					},
+					updatePaths: map[string][]string{
+						"ip-addresses": allIPAddressPaths,
+					},
+				},
+				"neighbors": &mappingGroup{
+					name: "neighbors",
+					models: map[string]*model{
+						"neighbors": supportedModels["neighbors"],
+					},
+					dependencies: map[string]*model{
+						"interfaces": supportedModels["interfaces"],
+					},
+					updatePaths: map[string][]string{
+						"neighbors": allNeighborPaths,
+					},
+				},
+				"network-instances": &mappingGroup{
+					name: "network-instances",
+					models: map[string]*model{
+						"network-instances": supportedModels["network-instances"],
+					},
+					dependencies: map[string]*model{
+						"interfaces": supportedModels["interfaces"],
+					},
+					updatePaths: map[string][]string{
+						"network-instances": allNetworkInstancePaths,
+					},
 				},
 				"system": &mappingGroup{
 					name: "system",
 					models: map[string]*model{
 						"system": supportedModels["system"],
+						"cpus":   supportedModels["cpus"],
+						"memory": supportedModels["memory"],
 					},
 					updatePaths: map[string][]string{
 						"system": allSystemPaths,
+						"cpus":   allCPUPaths,
+						"memory": allMemoryPaths,
 					},
 				},
 				"platform": &mappingGroup{
@@ -1394,6 +2158,31 @@ func TestMappingGroups(t *testing.T) {
 					updatePaths: map[string][]string{
 						"platform": allPlatformPaths,
 					},
+					restores: []string{"sensors"},
+				},
+				"sensors": &mappingGroup{
+					name: "sensors",
+					models: map[string]*model{
+						"sensors": supportedModels["sensors"],
+					},
+					updatePaths: map[string][]string{
+						"sensors": allSensorPaths,
+					},
+				},
+			},
+		},
+		{
+			name:  "sensors",
+			paths: []string{"^/components/.*/fan/"},
+			expectedMappingGroups: map[string]*mappingGroup{
+				"sensors": &mappingGroup{
+					name: "sensors",
+					models: map[string]*model{
+						"sensors": supportedModels["sensors"],
+					},
+					updatePaths: map[string][]string{
+						"sensors": matchingPaths("^/components/.*/fan/", defaultPaths),
+					},
 				},
 			},
 		},
@@ -1427,3 +2216,110 @@ func TestMappingGroups(t *testing.T) {
 		})
 	}
 }
+
+func TestMappingGroupPaths(t *testing.T) {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error in smi.NewStore: %s", err)
+	}
+	trans, err := NewTranslator(mibStore, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for model, expected := range map[string]string{
+		"interfaces":        "interfaces-lldp",
+		"lldp":              "interfaces-lldp",
+		"ip-addresses":      "ip-addresses",
+		"neighbors":         "neighbors",
+		"network-instances": "network-instances",
+		"cpus":              "system",
+		"platform":          "platform",
+		"sensors":           "sensors",
+	} {
+		if mg, err := trans.MappingGroup(model); err != nil || mg != expected {
+			t.Errorf("Expected model %s in group %s, got %s (%v)", model, expected,
+				mg, err)
+		}
+	}
+	if _, err := trans.MappingGroup("bogus"); err == nil {
+		t.Error("Expected error for unknown model")
+	}
+
+	defaultPaths := []string{}
+	for k := range DefaultMappings() {
+		defaultPaths = append(defaultPaths, k)
+	}
+	expected := map[string][]string{
+		"interfaces-lldp": matchingPaths("^/(interfaces/interface\\[name=name\\]/"+
+			"(name|config/|state/)|lldp/)", defaultPaths),
+		"ip-addresses":      matchingPaths("^/interfaces/.*/(index|addresses/)", defaultPaths),
+		"neighbors":         matchingPaths("^/interfaces/.*/neighbors/", defaultPaths),
+		"network-instances": matchingPaths("^/network-instances/", defaultPaths),
+		"system":            matchingPaths("^/system/", defaultPaths),
+		"platform": matchingPaths(
+			"^/components/component\\[name=name\\]/(config|name|state/[^/]+$)", defaultPaths),
+		"sensors": matchingPaths(
+			"^/components/.*/(state/temperature|fan|power-supply)/", defaultPaths),
+	}
+	for _, paths := range expected {
+		sort.Strings(paths)
+	}
+	if got := trans.MappingGroupPaths(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected mapping group paths %v, got %v", expected, got)
+	}
+}
+
+func TestConcurrentWalks(t *testing.T) {
+	mibStore, err := smi.NewStore("../smi/mibs")
+	if err != nil {
+		t.Fatalf("Error in smi.NewStore: %s", err)
+	}
+	trans, err := NewTranslator(mibStore, &gosnmp.GoSNMP{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans.Mock = true
+	trans.Getter = func(oids []string) (*gosnmp.SnmpPacket, error) {
+		return mockget(oids, nil, mibStore)
+	}
+
+	// Record how many walks are in flight at once.
+	var lock sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	responses := map[string][]*gosnmp.SnmpPDU{
+		"ifTable":  PDUsFromString(basicIfTableResponse),
+		"ifXTable": PDUsFromString(basicIfXTableResponse),
+	}
+	trans.Walker = func(oid string, walker gosnmp.WalkFunc) error {
+		lock.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		lock.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		err := mockwalk(oid, walker, responses, mibStore)
+		lock.Lock()
+		inFlight--
+		lock.Unlock()
+		return err
+	}
+
+	setReqs := []*gnmi.SetRequest{}
+	client := pgnmi.NewSimpleGNMIClient(func(ctx context.Context,
+		req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+		setReqs = append(setReqs, req)
+		return nil, nil
+	})
+	if err := trans.Poll(context.Background(), client,
+		[]string{"/interfaces/interface[name=name]/state/name"}); err != nil {
+		t.Fatalf("Failure in translator.Poll: %v", err)
+	}
+	if len(setReqs) != 1 || len(setReqs[0].Replace) == 0 {
+		t.Fatalf("Expected interface names, got %v", setReqs)
+	}
+	if maxInFlight < 2 {
+		t.Fatalf("Expected parallel walks, but at most %d ran at once", maxInFlight)
+	}
+}
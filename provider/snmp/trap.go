@@ -0,0 +1,247 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmp
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/aristanetworks/cloudvision-go/log"
+	"github.com/aristanetworks/cloudvision-go/provider/snmp/smi"
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	snmpTrapOID = ".1.3.6.1.6.3.1.1.4.1.0"
+
+	snmpColdStart           = ".1.3.6.1.6.3.1.1.5.1"
+	snmpWarmStart           = ".1.3.6.1.6.3.1.1.5.2"
+	snmpLinkDown            = ".1.3.6.1.6.3.1.1.5.3"
+	snmpLinkUp              = ".1.3.6.1.6.3.1.1.5.4"
+	snmpEntConfigChange     = ".1.3.6.1.2.1.47.2.0.1"
+	snmpLldpRemTablesChange = ".1.0.8802.1.1.2.0.0.1"
+)
+
+// trapPaths maps the notifications we understand to the OpenConfig
+// paths that need to be repolled when they arrive.
+var trapPaths = map[string][]string{
+	snmpColdStart:           {".*"},
+	snmpWarmStart:           {".*"},
+	snmpLinkDown:            {"^/interfaces/"},
+	snmpLinkUp:              {"^/interfaces/"},
+	snmpEntConfigChange:     {"^/components/"},
+	snmpLldpRemTablesChange: {"^/lldp/"},
+}
+
+// trapOID returns the snmpTrapOID.0 value of a notification, or an
+// empty string if it has none. For an SNMPv1 trap, that's the
+// notification OID it maps to.
+func trapOID(pkt *gosnmp.SnmpPacket) string {
+	if pkt.PDUType == gosnmp.Trap {
+		return "." + smi.TrapOID(pkt.Enterprise, pkt.GenericTrap, pkt.SpecificTrap)
+	}
+	for _, v := range pkt.Variables {
+		if v.Name != snmpTrapOID && "."+v.Name != snmpTrapOID {
+			continue
+		}
+		if oid, ok := v.Value.(string); ok {
+			if !strings.HasPrefix(oid, ".") {
+				oid = "." + oid
+			}
+			return oid
+		}
+	}
+	return ""
+}
+
+// trapTarget is a device registered to receive its notifications
+// from a TrapReceiver.
+type trapTarget struct {
+	// params holds the device's credentials, for decoding its
+	// notifications.
+	params *gosnmp.GoSNMP
+	trapc  chan<- *gosnmp.SnmpPacket
+}
+
+// A TrapReceiver listens for SNMPv2c and SNMPv3 traps and informs and
+// hands each to the device it came from, as identified by its source
+// address. Informs are acknowledged once decoded.
+type TrapReceiver struct {
+	conn    net.PacketConn
+	lock    sync.Mutex
+	targets map[string]*trapTarget
+}
+
+// NewTrapReceiver returns a TrapReceiver listening on the specified
+// UDP address. Call Serve to start receiving.
+func NewTrapReceiver(address string) (*TrapReceiver, error) {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &TrapReceiver{
+		conn:    conn,
+		targets: make(map[string]*trapTarget),
+	}, nil
+}
+
+// Addr returns the address on which the TrapReceiver is listening.
+func (r *TrapReceiver) Addr() net.Addr {
+	return r.conn.LocalAddr()
+}
+
+// Close stops the TrapReceiver.
+func (r *TrapReceiver) Close() error {
+	return r.conn.Close()
+}
+
+// register arranges for notifications from the specified device
+// address to be decoded with params and sent to trapc. Notifications
+// are told apart only by source address, so it fails if another
+// device is registered at the address.
+func (r *TrapReceiver) register(address string, params *gosnmp.GoSNMP,
+	trapc chan<- *gosnmp.SnmpPacket) error {
+	ips, err := net.LookupIP(address)
+	if err != nil {
+		return fmt.Errorf("Error resolving trap source %s: %v", address, err)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, ip := range ips {
+		if t, ok := r.targets[ip.String()]; ok && t.trapc != trapc {
+			return fmt.Errorf("Trap source %s is already registered to another device",
+				ip)
+		}
+	}
+	for _, ip := range ips {
+		r.targets[ip.String()] = &trapTarget{params: params, trapc: trapc}
+	}
+	return nil
+}
+
+// deregister stops delivery of notifications to trapc.
+func (r *TrapReceiver) deregister(trapc chan<- *gosnmp.SnmpPacket) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for ip, t := range r.targets {
+		if t.trapc == trapc {
+			delete(r.targets, ip)
+		}
+	}
+}
+
+func (r *TrapReceiver) target(addr net.Addr) *trapTarget {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.targets[udpAddr.IP.String()]
+}
+
+// handle decodes a notification from the specified address and
+// delivers it to the registered device.
+func (r *TrapReceiver) handle(msg []byte, addr net.Addr) {
+	t := r.target(addr)
+	if t == nil {
+		log.Log(r).Debugf("Dropping notification from unknown source %v", addr)
+		return
+	}
+	pkt := t.params.UnmarshalTrap(msg, false)
+	if pkt == nil {
+		log.Log(r).Infof("Failed to decode notification from %v", addr)
+		return
+	}
+	if pkt.Version != gosnmp.Version3 && pkt.Community != t.params.Community {
+		log.Log(r).Infof("Dropping notification from %v with wrong community", addr)
+		return
+	}
+
+	// Build the inform's acknowledgment before handing the packet to
+	// the device, which owns it from then on.
+	var ack []byte
+	if pkt.PDUType == gosnmp.InformRequest {
+		var err error
+		if ack, err = informResponse(pkt); err != nil {
+			log.Log(r).Infof("Error marshaling inform response to %v: %v", addr, err)
+		}
+	}
+
+	// Don't hold up the receiver if the device is busy; it's polled
+	// regularly anyway.
+	select {
+	case t.trapc <- pkt:
+	default:
+		log.Log(r).Infof("Dropping notification from %v: device busy", addr)
+	}
+
+	if ack == nil {
+		return
+	}
+	if _, err := r.conn.WriteTo(ack, addr); err != nil {
+		log.Log(r).Infof("Error sending inform response to %v: %v", addr, err)
+	}
+}
+
+// informResponse returns the encoded response acknowledging an
+// inform, leaving the inform itself unchanged.
+func informResponse(pkt *gosnmp.SnmpPacket) ([]byte, error) {
+	resp := *pkt
+	if pkt.SecurityParameters != nil {
+		resp.SecurityParameters = pkt.SecurityParameters.Copy()
+	}
+	resp.PDUType = gosnmp.GetResponse
+	resp.Error = gosnmp.NoError
+	resp.ErrorIndex = 0
+	return resp.MarshalMsg()
+}
+
+// Serve receives notifications until the TrapReceiver is closed.
+func (r *TrapReceiver) Serve() error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			if strings.Contains(err.Error(), "use of closed network connection") {
+				return nil
+			}
+			return err
+		}
+		msg := make([]byte, n)
+		copy(msg, buf[:n])
+		r.handle(msg, addr)
+	}
+}
+
+var (
+	trapReceiversLock sync.Mutex
+	trapReceivers     = make(map[string]*TrapReceiver)
+)
+
+// SharedTrapReceiver returns a TrapReceiver listening on the
+// specified address, starting one if none is running yet, so that
+// all devices configured with the same trap address share a socket.
+func SharedTrapReceiver(address string) (*TrapReceiver, error) {
+	trapReceiversLock.Lock()
+	defer trapReceiversLock.Unlock()
+	if r, ok := trapReceivers[address]; ok {
+		return r, nil
+	}
+	r, err := NewTrapReceiver(address)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := r.Serve(); err != nil {
+			log.Log(r).Errorf("Error receiving SNMP notifications on %s: %v",
+				address, err)
+		}
+	}()
+	trapReceivers[address] = r
+	return r, nil
+}
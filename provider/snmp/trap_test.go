@@ -0,0 +1,309 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package snmp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+func newTestTrapReceiver(t *testing.T) *TrapReceiver {
+	r, err := NewTrapReceiver("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go r.Serve()
+	return r
+}
+
+// sender returns gosnmp parameters for sending notifications to the
+// receiver.
+func sender(t *testing.T, r *TrapReceiver, params gosnmp.GoSNMP) *gosnmp.GoSNMP {
+	params.Target = "127.0.0.1"
+	params.Port = uint16(r.Addr().(*net.UDPAddr).Port)
+	params.Timeout = 2 * time.Second
+	params.Retries = 1
+	if err := params.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return &params
+}
+
+func linkDownTrap(inform bool) gosnmp.SnmpTrap {
+	return gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: snmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: snmpLinkDown},
+			{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: gosnmp.Integer, Value: 3},
+		},
+		IsInform: inform,
+	}
+}
+
+func v3Params(engineID string) *gosnmp.UsmSecurityParameters {
+	return &gosnmp.UsmSecurityParameters{
+		UserName:                 "user",
+		AuthenticationProtocol:   gosnmp.SHA,
+		AuthenticationPassphrase: "authpassword",
+		PrivacyProtocol:          gosnmp.AES,
+		PrivacyPassphrase:        "privpassword",
+		AuthoritativeEngineID:    engineID,
+	}
+}
+
+func TestTrapOID(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		pkt  *gosnmp.SnmpPacket
+		oid  string
+	}{
+		{
+			name: "v2c trap",
+			pkt: &gosnmp.SnmpPacket{
+				PDUType:   gosnmp.SNMPv2Trap,
+				Variables: linkDownTrap(false).Variables,
+			},
+			oid: snmpLinkDown,
+		},
+		{
+			name: "v1 generic trap",
+			pkt: &gosnmp.SnmpPacket{
+				PDUType: gosnmp.Trap,
+				SnmpTrap: gosnmp.SnmpTrap{
+					Enterprise:  ".1.3.6.1.4.1.30065",
+					GenericTrap: 2,
+				},
+			},
+			oid: snmpLinkDown,
+		},
+		{
+			name: "v1 enterprise-specific trap",
+			pkt: &gosnmp.SnmpPacket{
+				PDUType: gosnmp.Trap,
+				SnmpTrap: gosnmp.SnmpTrap{
+					Enterprise:   ".1.3.6.1.2.1.47.2",
+					GenericTrap:  6,
+					SpecificTrap: 1,
+				},
+			},
+			oid: snmpEntConfigChange,
+		},
+		{
+			name: "no snmpTrapOID",
+			pkt:  &gosnmp.SnmpPacket{PDUType: gosnmp.SNMPv2Trap},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if oid := trapOID(tc.pkt); oid != tc.oid {
+				t.Fatalf("Expected %q, got %q", tc.oid, oid)
+			}
+		})
+	}
+}
+
+func TestTrapReceiver(t *testing.T) {
+	v2c := gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"}
+	wrongCommunity := gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "private"}
+	v3 := gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           gosnmp.AuthPriv,
+		SecurityParameters: v3Params(""),
+	}
+	v3Sender := v3
+	v3Sender.SecurityParameters = v3Params("\x80\x00\x1f\x88\x04test")
+	v3WrongKey := v3Sender
+	wrongKey := v3Params("\x80\x00\x1f\x88\x04test")
+	wrongKey.AuthenticationPassphrase = "wrongpassword"
+	v3WrongKey.SecurityParameters = wrongKey
+
+	for _, tc := range []struct {
+		name     string
+		receiver gosnmp.GoSNMP
+		sender   gosnmp.GoSNMP
+		inform   bool
+		expected bool
+	}{
+		{
+			name:     "v2c trap",
+			receiver: v2c,
+			sender:   v2c,
+			expected: true,
+		},
+		{
+			name:     "v2c inform",
+			receiver: v2c,
+			sender:   v2c,
+			inform:   true,
+			expected: true,
+		},
+		{
+			name:     "v2c wrong community",
+			receiver: v2c,
+			sender:   wrongCommunity,
+		},
+		{
+			name:     "v3 trap",
+			receiver: v3,
+			sender:   v3Sender,
+			expected: true,
+		},
+		{
+			name:     "v3 wrong key",
+			receiver: v3,
+			sender:   v3WrongKey,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestTrapReceiver(t)
+			defer r.Close()
+			trapc := make(chan *gosnmp.SnmpPacket, 1)
+			receiver := tc.receiver
+			if err := r.register("127.0.0.1", &receiver, trapc); err != nil {
+				t.Fatal(err)
+			}
+
+			s := sender(t, r, tc.sender)
+			defer s.Conn.Close()
+			if _, err := s.SendTrap(linkDownTrap(tc.inform)); err != nil {
+				t.Fatalf("Error sending notification: %v", err)
+			}
+
+			select {
+			case pkt := <-trapc:
+				if !tc.expected {
+					t.Fatalf("Unexpected notification: %v", pkt)
+				}
+				if oid := trapOID(pkt); oid != snmpLinkDown {
+					t.Fatalf("Expected trap OID %s, got %s", snmpLinkDown, oid)
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tc.expected {
+					t.Fatal("Timed out waiting for notification")
+				}
+			}
+		})
+	}
+}
+
+// A second device at an address already registered should be
+// refused, leaving the first device registered.
+func TestTrapReceiverDuplicate(t *testing.T) {
+	r := newTestTrapReceiver(t)
+	defer r.Close()
+	v2c := gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"}
+	trapc := make(chan *gosnmp.SnmpPacket, 1)
+	if err := r.register("127.0.0.1", &v2c, trapc); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.register("127.0.0.1", &v2c, trapc); err != nil {
+		t.Fatalf("Unexpected error re-registering device: %v", err)
+	}
+	other := make(chan *gosnmp.SnmpPacket, 1)
+	if err := r.register("127.0.0.1", &v2c, other); err == nil {
+		t.Fatal("Expected error registering second device at same address")
+	}
+	r.deregister(other)
+
+	s := sender(t, r, v2c)
+	defer s.Conn.Close()
+	if _, err := s.SendTrap(linkDownTrap(false)); err != nil {
+		t.Fatalf("Error sending notification: %v", err)
+	}
+	select {
+	case <-trapc:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Timed out waiting for notification")
+	}
+}
+
+// recordingGNMIClient records the root paths deleted by each
+// SetRequest it's sent.
+type recordingGNMIClient struct {
+	gnmi.GNMIClient
+	lock    sync.Mutex
+	deletes map[string]int
+}
+
+func (c *recordingGNMIClient) Set(ctx context.Context, in *gnmi.SetRequest,
+	opts ...grpc.CallOption) (*gnmi.SetResponse, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, d := range in.Delete {
+		if len(d.Elem) > 0 {
+			c.deletes[d.Elem[0].Name]++
+		}
+	}
+	return &gnmi.SetResponse{}, nil
+}
+
+func (c *recordingGNMIClient) count(root string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.deletes[root]
+}
+
+// A linkDown trap should cause an immediate poll of interfaces and
+// nothing else.
+func TestTrapPoll(t *testing.T) {
+	walkMaps, err := walkMapsFromDump(
+		"dumps/Arista_DCS-7150S-24_4.21.3F-2GB-INT_20190301.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestTrapReceiver(t)
+	defer r.Close()
+
+	p := NewSNMPProvider("127.0.0.1", 161, "public", time.Hour,
+		gosnmp.Version2c, nil, []string{"smi/mibs"}, true).(*Snmp)
+	p.getter = func(oids []string) (*gosnmp.SnmpPacket, error) {
+		return testget(oids, p.mibStore, walkMaps[0])
+	}
+	p.walker = func(oid string, walker gosnmp.WalkFunc) error {
+		return testwalk(oid, walker, p.mibStore, walkMaps[0])
+	}
+	p.SetTrapReceiver(r)
+	client := &recordingGNMIClient{deletes: make(map[string]int)}
+	p.InitGNMI(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		errc <- p.Run(ctx)
+	}()
+
+	waitFor := func(root string, n int) {
+		deadline := time.Now().Add(10 * time.Second)
+		for client.count(root) < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %d polls of %s", n, root)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitFor("system", 1)
+	waitFor("interfaces", 1)
+
+	s := sender(t, r, gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"})
+	defer s.Conn.Close()
+	if _, err := s.SendTrap(linkDownTrap(false)); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("interfaces", 2)
+	if n := client.count("system"); n != 1 {
+		t.Fatalf("Expected system to be polled once, got %d", n)
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
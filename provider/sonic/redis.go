@@ -0,0 +1,103 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package sonic
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis"
+)
+
+// SONiC Redis database numbers.
+const (
+	ApplDB     = 0
+	CountersDB = 2
+	ConfigDB   = 4
+	StateDB    = 6
+)
+
+// Key separators. CONFIG_DB and STATE_DB use "|" between table
+// name and key; APPL_DB and COUNTERS_DB use ":".
+const (
+	configSeparator = "|"
+	applSeparator   = ":"
+)
+
+// A Client reads hashes from SONiC's Redis databases.
+type Client interface {
+	// Keys returns the keys in the specified database matching the
+	// specified glob pattern. It must not block the server, which is
+	// the switch's own.
+	Keys(db int, pattern string) ([]string, error)
+	// HGetAll returns all fields of the hash at the specified key,
+	// or an empty map if there's no such key.
+	HGetAll(db int, key string) (map[string]string, error)
+}
+
+// redisClient implements Client with one go-redis client per
+// database, since a Redis connection is bound to a single database.
+type redisClient struct {
+	network string
+	address string
+	clients map[int]*redis.Client
+	lock    sync.Mutex
+}
+
+func (r *redisClient) client(db int) *redis.Client {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	c, ok := r.clients[db]
+	if !ok {
+		c = redis.NewClient(&redis.Options{
+			Network: r.network,
+			Addr:    r.address,
+			DB:      db,
+		})
+		r.clients[db] = c
+	}
+	return c
+}
+
+// scanCount is roughly how many keys a SCAN looks at per call.
+const scanCount = 1000
+
+// Keys uses SCAN rather than KEYS, which blocks the server while it
+// looks at every key in the database.
+func (r *redisClient) Keys(db int, pattern string) ([]string, error) {
+	// SCAN may return a key more than once.
+	seen := make(map[string]bool)
+	keys := []string{}
+	it := r.client(db).Scan(0, pattern, scanCount).Iterator()
+	for it.Next() {
+		if k := it.Val(); !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *redisClient) HGetAll(db int, key string) (map[string]string, error) {
+	return r.client(db).HGetAll(key).Result()
+}
+
+// NewRedisClient returns a Client connected to the Redis server at
+// the specified address, which is either host:port or the path of a
+// unix socket.
+func NewRedisClient(address string) Client {
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+	return &redisClient{
+		network: network,
+		address: address,
+		clients: make(map[int]*redis.Client),
+	}
+}
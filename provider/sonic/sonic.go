@@ -7,50 +7,376 @@ package sonic
 import (
 	"context"
 	"fmt"
-	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aristanetworks/cloudvision-go/provider"
 	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/aristanetworks/cloudvision-go/provider/openconfig"
 	"github.com/openconfig/gnmi/proto/gnmi"
 )
 
+// ChassisName is the name of the chassis component.
+const ChassisName = "SONiC"
+
+// System EEPROM TLV codes, as found in STATE_DB's EEPROM_INFO table.
+const (
+	eepromProductName  = "0x21"
+	eepromPartNumber   = "0x22"
+	eepromSerialNumber = "0x23"
+	eepromManufacturer = "0x2b"
+)
+
 type sonic struct {
 	client       gnmi.GNMIClient
+	redis        Client
 	errc         chan error
 	pollInterval time.Duration
 }
 
-// Return a set of gNMI updates for in/out bytes, packets, and errors
-// for a given interface, as reported by netstat.
-func updatesFromSystem() ([]*gnmi.Update, error) {
+// counterFields maps SAI port counters in COUNTERS_DB to OpenConfig
+// interface counters.
+var counterFields = []struct {
+	sai, leaf string
+}{
+	{"SAI_PORT_STAT_IF_IN_OCTETS", "in-octets"},
+	{"SAI_PORT_STAT_IF_IN_UCAST_PKTS", "in-unicast-pkts"},
+	{"SAI_PORT_STAT_IF_IN_MULTICAST_PKTS", "in-multicast-pkts"},
+	{"SAI_PORT_STAT_IF_IN_BROADCAST_PKTS", "in-broadcast-pkts"},
+	{"SAI_PORT_STAT_IF_IN_DISCARDS", "in-discards"},
+	{"SAI_PORT_STAT_IF_IN_ERRORS", "in-errors"},
+	{"SAI_PORT_STAT_IF_IN_UNKNOWN_PROTOS", "in-unknown-protos"},
+	{"SAI_PORT_STAT_IF_OUT_OCTETS", "out-octets"},
+	{"SAI_PORT_STAT_IF_OUT_UCAST_PKTS", "out-unicast-pkts"},
+	{"SAI_PORT_STAT_IF_OUT_MULTICAST_PKTS", "out-multicast-pkts"},
+	{"SAI_PORT_STAT_IF_OUT_BROADCAST_PKTS", "out-broadcast-pkts"},
+	{"SAI_PORT_STAT_IF_OUT_DISCARDS", "out-discards"},
+	{"SAI_PORT_STAT_IF_OUT_ERRORS", "out-errors"},
+}
+
+// tableKeys returns the sorted keys of the entries in the specified
+// table.
+func tableKeys(c Client, db int, table, separator string) ([]string, error) {
+	prefix := table + separator
+	keys, err := c.Keys(db, prefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	for i, k := range keys {
+		keys[i] = strings.TrimPrefix(k, prefix)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func deviceMetadata(c Client) (map[string]string, error) {
+	return c.HGetAll(ConfigDB, "DEVICE_METADATA"+configSeparator+"localhost")
+}
 
+// eeprom returns the values of the system EEPROM TLVs by code.
+func eeprom(c Client) (map[string]string, error) {
+	codes, err := tableKeys(c, StateDB, "EEPROM_INFO", configSeparator)
+	if err != nil {
+		return nil, err
+	}
+	tlvs := make(map[string]string)
+	for _, code := range codes {
+		tlv, err := c.HGetAll(StateDB, "EEPROM_INFO"+configSeparator+code)
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := tlv["Value"]; ok {
+			tlvs[strings.ToLower(code)] = v
+		}
+	}
+	return tlvs, nil
+}
+
+// DeviceID returns the switch's serial number from its system EEPROM,
+// or, if that's not available, its MAC address.
+func DeviceID(c Client) (string, error) {
+	tlvs, err := eeprom(c)
+	if err != nil {
+		return "", err
+	}
+	if serial := tlvs[eepromSerialNumber]; serial != "" {
+		return serial, nil
+	}
+	md, err := deviceMetadata(c)
+	if err != nil {
+		return "", err
+	}
+	if mac := md["mac"]; mac != "" {
+		return mac, nil
+	}
+	return "", fmt.Errorf("No serial number or MAC address found")
+}
+
+func adminStatus(s string) string {
+	if s == "up" {
+		return openconfig.IntfAdminStatus(1)
+	}
+	return openconfig.IntfAdminStatus(2)
+}
+
+func operStatus(s string) string {
+	switch s {
+	case "up":
+		return openconfig.IntfOperStatus(1)
+	case "down":
+		return openconfig.IntfOperStatus(2)
+	}
+	return openconfig.IntfOperStatus(4)
+}
+
+// interfaceUpdates returns updates for each port in CONFIG_DB, with
+// oper-status from APPL_DB and counters from COUNTERS_DB.
+func interfaceUpdates(c Client) ([]*gnmi.Update, error) {
+	ports, err := tableKeys(c, ConfigDB, "PORT", configSeparator)
+	if err != nil {
+		return nil, err
+	}
+	counterOids, err := c.HGetAll(CountersDB, "COUNTERS_PORT_NAME_MAP")
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []*gnmi.Update
+	for _, name := range ports {
+		port, err := c.HGetAll(ConfigDB, "PORT"+configSeparator+name)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates,
+			pgnmi.Update(pgnmi.IntfPath(name, "name"), pgnmi.Strval(name)),
+			pgnmi.Update(pgnmi.IntfConfigPath(name, "name"), pgnmi.Strval(name)),
+			pgnmi.Update(pgnmi.IntfStatePath(name, "name"), pgnmi.Strval(name)),
+			pgnmi.Update(pgnmi.IntfStatePath(name, "type"),
+				pgnmi.Strval("iana-if-type:"+openconfig.InterfaceType(6))),
+			pgnmi.Update(pgnmi.IntfStatePath(name, "admin-status"),
+				pgnmi.Strval(adminStatus(port["admin_status"]))))
+		if desc := port["description"]; desc != "" {
+			updates = append(updates, pgnmi.Update(pgnmi.IntfStatePath(name,
+				"description"), pgnmi.Strval(desc)))
+		}
+		if mtu, err := strconv.ParseUint(port["mtu"], 10, 64); err == nil {
+			if mtu > math.MaxUint16 {
+				mtu = math.MaxUint16
+			}
+			updates = append(updates,
+				pgnmi.Update(pgnmi.IntfStatePath(name, "mtu"), pgnmi.Uintval(mtu)))
+		}
+		if speed, err := strconv.ParseUint(port["speed"], 10, 64); err == nil {
+			updates = append(updates, pgnmi.Update(pgnmi.Path("interfaces",
+				pgnmi.ListWithKey("interface", "name", name), "ethernet", "state",
+				"port-speed"), pgnmi.Strval("openconfig-if-ethernet:"+
+				openconfig.PortSpeed(speed))))
+		}
+
+		state, err := c.HGetAll(ApplDB, "PORT_TABLE"+applSeparator+name)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, pgnmi.Update(pgnmi.IntfStatePath(name, "oper-status"),
+			pgnmi.Strval(operStatus(state["oper_status"]))))
+
+		oid, ok := counterOids[name]
+		if !ok {
+			continue
+		}
+		counters, err := c.HGetAll(CountersDB, "COUNTERS"+applSeparator+oid)
+		if err != nil {
+			return nil, err
+		}
+		for _, cf := range counterFields {
+			v, err := strconv.ParseUint(counters[cf.sai], 10, 64)
+			if err != nil {
+				continue
+			}
+			updates = append(updates, pgnmi.Update(
+				pgnmi.IntfStateCountersPath(name, cf.leaf), pgnmi.Uintval(v)))
+		}
+	}
+	return updates, nil
+}
+
+// lldpUpdates returns updates for the local LLDP system and for
+// each neighbor learned by SONiC's lldp container.
+func lldpUpdates(c Client) ([]*gnmi.Update, error) {
+	var updates []*gnmi.Update
+	loc, err := c.HGetAll(ApplDB, "LLDP_LOC_CHASSIS")
+	if err != nil {
+		return nil, err
+	}
+	chassisID := loc["lldp_loc_chassis_id"]
+	if chassisID == "" {
+		md, err := deviceMetadata(c)
+		if err != nil {
+			return nil, err
+		}
+		chassisID = md["mac"]
+	}
+	if chassisID != "" {
+		updates = append(updates, pgnmi.Update(pgnmi.LldpStatePath("chassis-id"),
+			pgnmi.Strval(chassisID)))
+	}
+	if t, err := strconv.Atoi(loc["lldp_loc_chassis_id_subtype"]); err == nil {
+		updates = append(updates, pgnmi.Update(pgnmi.LldpStatePath("chassis-id-type"),
+			pgnmi.Strval(openconfig.LLDPChassisIDType(t))))
+	}
+	if s := loc["lldp_loc_sys_name"]; s != "" {
+		updates = append(updates, pgnmi.Update(pgnmi.LldpStatePath("system-name"),
+			pgnmi.Strval(s)))
+	}
+	if s := loc["lldp_loc_sys_desc"]; s != "" {
+		updates = append(updates, pgnmi.Update(pgnmi.LldpStatePath("system-description"),
+			pgnmi.Strval(s)))
+	}
+
+	intfs, err := tableKeys(c, ApplDB, "LLDP_ENTRY_TABLE", applSeparator)
+	if err != nil {
+		return nil, err
+	}
+	for _, intf := range intfs {
+		entry, err := c.HGetAll(ApplDB, "LLDP_ENTRY_TABLE"+applSeparator+intf)
+		if err != nil {
+			return nil, err
+		}
+		id := entry["lldp_rem_index"]
+		if id == "" {
+			id = "1"
+		}
+		updates = append(updates,
+			pgnmi.Update(pgnmi.LldpIntfPath(intf, "name"), pgnmi.Strval(intf)),
+			pgnmi.Update(pgnmi.LldpIntfConfigPath(intf, "name"), pgnmi.Strval(intf)),
+			pgnmi.Update(pgnmi.LldpIntfStatePath(intf, "name"), pgnmi.Strval(intf)),
+			pgnmi.Update(pgnmi.LldpNeighborStatePath(intf, id, "id"), pgnmi.Strval(id)))
+		for _, f := range []struct {
+			field, leaf string
+		}{
+			{"lldp_rem_chassis_id", "chassis-id"},
+			{"lldp_rem_port_id", "port-id"},
+			{"lldp_rem_port_desc", "port-description"},
+			{"lldp_rem_sys_name", "system-name"},
+			{"lldp_rem_sys_desc", "system-description"},
+		} {
+			if v := entry[f.field]; v != "" {
+				updates = append(updates, pgnmi.Update(
+					pgnmi.LldpNeighborStatePath(intf, id, f.leaf), pgnmi.Strval(v)))
+			}
+		}
+		if t, err := strconv.Atoi(entry["lldp_rem_chassis_id_subtype"]); err == nil {
+			updates = append(updates, pgnmi.Update(
+				pgnmi.LldpNeighborStatePath(intf, id, "chassis-id-type"),
+				pgnmi.Strval(openconfig.LLDPChassisIDType(t))))
+		}
+		if t, err := strconv.Atoi(entry["lldp_rem_port_id_subtype"]); err == nil {
+			updates = append(updates, pgnmi.Update(
+				pgnmi.LldpNeighborStatePath(intf, id, "port-id-type"),
+				pgnmi.Strval(openconfig.LLDPPortIDType(t))))
+		}
+	}
+	return updates, nil
+}
+
+func componentUpdates(name, componentType string) []*gnmi.Update {
+	return []*gnmi.Update{
+		pgnmi.Update(pgnmi.PlatformComponentPath(name, "name"), pgnmi.Strval(name)),
+		pgnmi.Update(pgnmi.PlatformComponentConfigPath(name, "name"),
+			pgnmi.Strval(name)),
+		pgnmi.Update(pgnmi.PlatformComponentStatePath(name, "name"),
+			pgnmi.Strval(name)),
+		pgnmi.Update(pgnmi.PlatformComponentStatePath(name, "type"),
+			pgnmi.Strval("openconfig-platform-types:"+componentType)),
+	}
+}
+
+// platformUpdates returns updates for the chassis, described by the
+// system EEPROM and device metadata, and for the PSUs and fans in
+// STATE_DB.
+func platformUpdates(c Client) ([]*gnmi.Update, error) {
+	md, err := deviceMetadata(c)
+	if err != nil {
+		return nil, err
+	}
+	tlvs, err := eeprom(c)
+	if err != nil {
+		return nil, err
+	}
+	partNo := tlvs[eepromPartNumber]
+	if partNo == "" {
+		partNo = md["hwsku"]
+	}
+
+	updates := componentUpdates(ChassisName, "CHASSIS")
+	for _, leaf := range []struct {
+		name, value string
+	}{
+		{"serial-no", tlvs[eepromSerialNumber]},
+		{"part-no", partNo},
+		{"description", tlvs[eepromProductName]},
+		{"mfg-name", tlvs[eepromManufacturer]},
+		{"hardware-version", md["hwsku"]},
+	} {
+		if leaf.value != "" {
+			updates = append(updates, pgnmi.Update(
+				pgnmi.PlatformComponentStatePath(ChassisName, leaf.name),
+				pgnmi.Strval(leaf.value)))
+		}
+	}
+
+	for _, t := range []struct {
+		table, componentType string
+	}{
+		{"PSU_INFO", "POWER_SUPPLY"},
+		{"FAN_INFO", "FAN"},
+	} {
+		names, err := tableKeys(c, StateDB, t.table, configSeparator)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			info, err := c.HGetAll(StateDB, t.table+configSeparator+name)
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, componentUpdates(name, t.componentType)...)
+			if presence, err := strconv.ParseBool(info["presence"]); err == nil {
+				updates = append(updates, pgnmi.Update(
+					pgnmi.PlatformComponentStatePath(name, "empty"),
+					pgnmi.Boolval(!presence)))
+			}
+			if status, err := strconv.ParseBool(info["status"]); err == nil {
+				operStatus := "INACTIVE"
+				if status {
+					operStatus = "ACTIVE"
+				}
+				updates = append(updates, pgnmi.Update(
+					pgnmi.PlatformComponentStatePath(name, "oper-status"),
+					pgnmi.Strval("openconfig-platform-types:"+operStatus)))
+			}
+		}
+	}
+	return updates, nil
+}
+
+// systemUpdates returns updates for the system hostname.
+func systemUpdates(c Client) ([]*gnmi.Update, error) {
+	md, err := deviceMetadata(c)
+	if err != nil {
+		return nil, err
+	}
+	hostname := md["hostname"]
+	if hostname == "" {
+		return nil, nil
+	}
 	return []*gnmi.Update{
-		// Platform components to define teh part number and serial number
-		pgnmi.Update(pgnmi.PlatformComponentPath("SONiC", "name"),
-			pgnmi.Strval("SONiC")),
-		pgnmi.Update(pgnmi.PlatformComponentConfigPath("SONiC", "name"),
-			pgnmi.Strval("SONiC")),
-		pgnmi.Update(pgnmi.PlatformComponentStatePath("SONiC", "name"),
-			pgnmi.Strval("SONiC")),
-		pgnmi.Update(pgnmi.PlatformComponentStatePath("SONiC", "type"),
-			pgnmi.Strval("openconfig-platform-types:CHASSIS")),
-		pgnmi.Update(pgnmi.PlatformComponentStatePath("SONiC", "software-version"),
-			pgnmi.Strval("SONiC.staphylo.108945.1")),
-		pgnmi.Update(pgnmi.PlatformComponentStatePath("SONiC", "part-no"),
-			pgnmi.Strval("Arista-7050-QX-32S")),
-		pgnmi.Update(pgnmi.PlatformComponentStatePath("SONiC", "hardware-version"),
-			pgnmi.Strval("Arista-7050-QX-32S")),
-		pgnmi.Update(pgnmi.PlatformComponentStatePath("SONiC", "mfg-name"),
-			pgnmi.Strval("Arista Networks")),
-		pgnmi.Update(SystemConfigPath("hostname"), pgnmi.Strval("sonic-leaf1")),
-		pgnmi.Update(SystemStatePath("hostname"), pgnmi.Strval("sonic-leaf1")),
-		// TODO: boot time -> uptime and mgmt IP address
-		// pgnmi.Update(SystemStatePath("boot-time"), &gnmi.TypedValue{}),
-		pgnmi.Update(pgnmi.LldpStatePath("chassis-id"), pgnmi.Strval("00:1c:73:e1:be:ef")),
+		pgnmi.Update(SystemConfigPath("hostname"), pgnmi.Strval(hostname)),
+		pgnmi.Update(SystemStatePath("hostname"), pgnmi.Strval(hostname)),
 	}, nil
-	// return Path("lldp", "interfaces", ListWithKey("interface", "name",
-	// intfName), "state", "counters", leafName)
 }
 
 // SystemConfigPath provides an easy gnmi path to the system config settings
@@ -63,20 +389,24 @@ func SystemStatePath(leafName string) *gnmi.Path {
 	return pgnmi.Path("system", "state", leafName)
 }
 
-func (d *sonic) updatePlatform() ([]*gnmi.SetRequest, error) {
-
-	log.Printf("updatePlatform")
-	setRequest := new(gnmi.SetRequest)
-	updates, err := updatesFromSystem()
-	if err != nil {
-		return nil, err
+func (d *sonic) updateDevice() ([]*gnmi.SetRequest, error) {
+	setRequest := &gnmi.SetRequest{
+		Delete: []*gnmi.Path{
+			pgnmi.Path("interfaces"),
+			pgnmi.Path("lldp"),
+			pgnmi.Path("components"),
+			pgnmi.Path("system"),
+		},
+	}
+	for _, fn := range []func(Client) ([]*gnmi.Update, error){
+		interfaceUpdates, lldpUpdates, platformUpdates, systemUpdates,
+	} {
+		updates, err := fn(d.redis)
+		if err != nil {
+			return nil, err
+		}
+		setRequest.Replace = append(setRequest.Replace, updates...)
 	}
-
-	setRequest.Delete = []*gnmi.Path{pgnmi.Path("components")}
-	setRequest.Delete = []*gnmi.Path{pgnmi.Path("system")}
-	setRequest.Delete = []*gnmi.Path{pgnmi.Path("lldp")}
-	setRequest.Replace = updates
-	log.Printf("setRquests done")
 	return []*gnmi.SetRequest{setRequest}, nil
 }
 
@@ -92,13 +422,13 @@ func (d *sonic) handleErrors(ctx context.Context) error {
 }
 
 func (d *sonic) Run(ctx context.Context) error {
-	// Run updatePlatform at the specified polling interval,
+	// Run updateDevice at the specified polling interval,
 	// forever. PollForever sends the updates produced by
-	// updatePlatorm to the gNMI client and sends any
+	// updateDevice to the gNMI client and sends any
 	// resulting errors to the error channel to be handled by
 	// handleErrors.
 	go pgnmi.PollForever(ctx, d.client, d.pollInterval,
-		d.updatePlatform, d.errc)
+		d.updateDevice, d.errc)
 
 	// handleErrors only returns if it sees an error.
 	return d.handleErrors(ctx)
@@ -112,10 +442,12 @@ func (d *sonic) OpenConfig() bool {
 	return true
 }
 
-// NewSonicProvider returns a sonic provider that registers a
-// sonic device and streams interface statistics.
-func NewSonicProvider(pollInterval time.Duration) provider.GNMIProvider {
+// NewSonicProvider returns a sonic provider that streams interface,
+// LLDP, platform, and system state read from the SONiC Redis
+// databases through the specified client.
+func NewSonicProvider(client Client, pollInterval time.Duration) provider.GNMIProvider {
 	return &sonic{
+		redis:        client,
 		errc:         make(chan error),
 		pollInterval: pollInterval,
 	}
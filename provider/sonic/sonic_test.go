@@ -0,0 +1,202 @@
+// Copyright (c) 2020 Arista Networks, Inc.
+// Use of this source code is governed by the Apache License 2.0
+// that can be found in the COPYING file.
+
+package sonic
+
+import (
+	"path"
+	"reflect"
+	"testing"
+
+	pgnmi "github.com/aristanetworks/cloudvision-go/provider/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// fakeClient maps database to key to hash.
+type fakeClient map[int]map[string]map[string]string
+
+func (f fakeClient) Keys(db int, pattern string) ([]string, error) {
+	var keys []string
+	for k := range f[db] {
+		if ok, _ := path.Match(pattern, k); ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (f fakeClient) HGetAll(db int, key string) (map[string]string, error) {
+	if h, ok := f[db][key]; ok {
+		return h, nil
+	}
+	return map[string]string{}, nil
+}
+
+func newFakeClient() fakeClient {
+	return fakeClient{
+		ApplDB: {
+			"PORT_TABLE:Ethernet0": {"oper_status": "up"},
+			"PORT_TABLE:Ethernet4": {"oper_status": "down"},
+			"LLDP_LOC_CHASSIS": {
+				"lldp_loc_chassis_id":         "00:1c:73:00:00:01",
+				"lldp_loc_chassis_id_subtype": "4",
+				"lldp_loc_sys_name":           "sonic1",
+			},
+			"LLDP_ENTRY_TABLE:Ethernet0": {
+				"lldp_rem_index":              "2",
+				"lldp_rem_chassis_id":         "00:1c:73:00:00:02",
+				"lldp_rem_chassis_id_subtype": "4",
+				"lldp_rem_port_id":            "Ethernet1",
+				"lldp_rem_port_id_subtype":    "5",
+				"lldp_rem_sys_name":           "peer",
+			},
+		},
+		CountersDB: {
+			"COUNTERS_PORT_NAME_MAP": {"Ethernet0": "oid:0x1000000000002"},
+			"COUNTERS:oid:0x1000000000002": {
+				"SAI_PORT_STAT_IF_IN_OCTETS":  "1234",
+				"SAI_PORT_STAT_IF_OUT_ERRORS": "5",
+			},
+		},
+		ConfigDB: {
+			"DEVICE_METADATA|localhost": {
+				"hostname": "sonic1",
+				"hwsku":    "Arista-7050-QX-32S",
+				"mac":      "00:1c:73:00:00:01",
+			},
+			"PORT|Ethernet0": {
+				"admin_status": "up",
+				"mtu":          "9100",
+				"speed":        "40000",
+				"description":  "uplink",
+			},
+			"PORT|Ethernet4": {"speed": "40000"},
+		},
+		StateDB: {
+			"EEPROM_INFO|0x21": {"Name": "Product Name", "Value": "DCS-7050QX-32S"},
+			"EEPROM_INFO|0x23": {"Name": "Serial Number", "Value": "JPE16194299"},
+			"PSU_INFO|PSU 1":   {"presence": "true", "status": "true"},
+			"FAN_INFO|FAN 1":   {"presence": "false", "status": "false"},
+		},
+	}
+}
+
+func portSpeedPath(name string) *gnmi.Path {
+	return pgnmi.Path("interfaces", pgnmi.ListWithKey("interface", "name", name),
+		"ethernet", "state", "port-speed")
+}
+
+func TestInterfaceUpdates(t *testing.T) {
+	updates, err := interfaceUpdates(newFakeClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*gnmi.Update{
+		pgnmi.Update(pgnmi.IntfPath("Ethernet0", "name"), pgnmi.Strval("Ethernet0")),
+		pgnmi.Update(pgnmi.IntfConfigPath("Ethernet0", "name"), pgnmi.Strval("Ethernet0")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet0", "name"), pgnmi.Strval("Ethernet0")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet0", "type"),
+			pgnmi.Strval("iana-if-type:ethernetCsmacd")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet0", "admin-status"), pgnmi.Strval("UP")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet0", "description"), pgnmi.Strval("uplink")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet0", "mtu"), pgnmi.Uintval(9100)),
+		pgnmi.Update(portSpeedPath("Ethernet0"),
+			pgnmi.Strval("openconfig-if-ethernet:SPEED_40GB")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet0", "oper-status"), pgnmi.Strval("UP")),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("Ethernet0", "in-octets"), pgnmi.Uintval(1234)),
+		pgnmi.Update(pgnmi.IntfStateCountersPath("Ethernet0", "out-errors"), pgnmi.Uintval(5)),
+
+		pgnmi.Update(pgnmi.IntfPath("Ethernet4", "name"), pgnmi.Strval("Ethernet4")),
+		pgnmi.Update(pgnmi.IntfConfigPath("Ethernet4", "name"), pgnmi.Strval("Ethernet4")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet4", "name"), pgnmi.Strval("Ethernet4")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet4", "type"),
+			pgnmi.Strval("iana-if-type:ethernetCsmacd")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet4", "admin-status"), pgnmi.Strval("DOWN")),
+		pgnmi.Update(portSpeedPath("Ethernet4"),
+			pgnmi.Strval("openconfig-if-ethernet:SPEED_40GB")),
+		pgnmi.Update(pgnmi.IntfStatePath("Ethernet4", "oper-status"), pgnmi.Strval("DOWN")),
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestLldpUpdates(t *testing.T) {
+	updates, err := lldpUpdates(newFakeClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*gnmi.Update{
+		pgnmi.Update(pgnmi.LldpStatePath("chassis-id"), pgnmi.Strval("00:1c:73:00:00:01")),
+		pgnmi.Update(pgnmi.LldpStatePath("chassis-id-type"), pgnmi.Strval("MAC_ADDRESS")),
+		pgnmi.Update(pgnmi.LldpStatePath("system-name"), pgnmi.Strval("sonic1")),
+		pgnmi.Update(pgnmi.LldpIntfPath("Ethernet0", "name"), pgnmi.Strval("Ethernet0")),
+		pgnmi.Update(pgnmi.LldpIntfConfigPath("Ethernet0", "name"), pgnmi.Strval("Ethernet0")),
+		pgnmi.Update(pgnmi.LldpIntfStatePath("Ethernet0", "name"), pgnmi.Strval("Ethernet0")),
+		pgnmi.Update(pgnmi.LldpNeighborStatePath("Ethernet0", "2", "id"), pgnmi.Strval("2")),
+		pgnmi.Update(pgnmi.LldpNeighborStatePath("Ethernet0", "2", "chassis-id"),
+			pgnmi.Strval("00:1c:73:00:00:02")),
+		pgnmi.Update(pgnmi.LldpNeighborStatePath("Ethernet0", "2", "port-id"),
+			pgnmi.Strval("Ethernet1")),
+		pgnmi.Update(pgnmi.LldpNeighborStatePath("Ethernet0", "2", "system-name"),
+			pgnmi.Strval("peer")),
+		pgnmi.Update(pgnmi.LldpNeighborStatePath("Ethernet0", "2", "chassis-id-type"),
+			pgnmi.Strval("MAC_ADDRESS")),
+		pgnmi.Update(pgnmi.LldpNeighborStatePath("Ethernet0", "2", "port-id-type"),
+			pgnmi.Strval("INTERFACE_NAME")),
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestPlatformUpdates(t *testing.T) {
+	updates, err := platformUpdates(newFakeClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := componentUpdates(ChassisName, "CHASSIS")
+	expected = append(expected,
+		pgnmi.Update(pgnmi.PlatformComponentStatePath(ChassisName, "serial-no"),
+			pgnmi.Strval("JPE16194299")),
+		pgnmi.Update(pgnmi.PlatformComponentStatePath(ChassisName, "part-no"),
+			pgnmi.Strval("Arista-7050-QX-32S")),
+		pgnmi.Update(pgnmi.PlatformComponentStatePath(ChassisName, "description"),
+			pgnmi.Strval("DCS-7050QX-32S")),
+		pgnmi.Update(pgnmi.PlatformComponentStatePath(ChassisName, "hardware-version"),
+			pgnmi.Strval("Arista-7050-QX-32S")))
+	expected = append(expected, componentUpdates("PSU 1", "POWER_SUPPLY")...)
+	expected = append(expected,
+		pgnmi.Update(pgnmi.PlatformComponentStatePath("PSU 1", "empty"), pgnmi.Boolval(false)),
+		pgnmi.Update(pgnmi.PlatformComponentStatePath("PSU 1", "oper-status"),
+			pgnmi.Strval("openconfig-platform-types:ACTIVE")))
+	expected = append(expected, componentUpdates("FAN 1", "FAN")...)
+	expected = append(expected,
+		pgnmi.Update(pgnmi.PlatformComponentStatePath("FAN 1", "empty"), pgnmi.Boolval(true)),
+		pgnmi.Update(pgnmi.PlatformComponentStatePath("FAN 1", "oper-status"),
+			pgnmi.Strval("openconfig-platform-types:INACTIVE")))
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("Expected updates %v, got %v", expected, updates)
+	}
+}
+
+func TestDeviceID(t *testing.T) {
+	c := newFakeClient()
+	did, err := DeviceID(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if did != "JPE16194299" {
+		t.Fatalf("Expected device ID JPE16194299, got %s", did)
+	}
+
+	delete(c[StateDB], "EEPROM_INFO|0x23")
+	did, err = DeviceID(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if did != "00:1c:73:00:00:01" {
+		t.Fatalf("Expected device ID 00:1c:73:00:00:01, got %s", did)
+	}
+}